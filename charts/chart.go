@@ -0,0 +1,295 @@
+// Package charts 在服务端把折线图、柱状图、面积图和散点图渲染为内联SVG
+//
+// 图表实现widgets.Widget接口，可以像其他组件一样添加到页面，页面不需要加载任何图表脚本库。
+package charts
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// chartType 图表类型，同时作为组件类型
+type chartType string
+
+const (
+	lineChart    chartType = "line_chart"
+	barChart     chartType = "bar_chart"
+	areaChart    chartType = "area_chart"
+	scatterChart chartType = "scatter_chart"
+)
+
+// 默认尺寸，SVG按宽度缩放，尺寸只决定宽高比和文字的相对大小
+const (
+	defaultWidth  = 640
+	defaultHeight = 320
+)
+
+// AxisKind X轴类型
+type AxisKind int
+
+const (
+	// NumberAxis 数值轴
+	NumberAxis AxisKind = iota
+	// TimeAxis 时间轴，点的X是Unix时间（秒）
+	TimeAxis
+	// CategoryAxis 分类轴，点按分类等距排列
+	CategoryAxis
+)
+
+// Point 数据点
+type Point struct {
+	X float64
+	Y float64 // NaN表示缺失，折线和面积在此断开
+}
+
+// At 创建时间轴上的数据点，X为t的Unix时间（秒，含小数部分）
+func At(t time.Time, y float64) Point {
+	return Point{X: float64(t.UnixNano()) / 1e9, Y: y}
+}
+
+// Series 数据系列
+type Series struct {
+	Name   string
+	Color  string // CSS颜色，如"#1c83e1"，为空或无效时使用默认调色板
+	Points []Point
+
+	kind   AxisKind
+	labels []string // 分类轴系列中每个点的分类
+}
+
+// NewSeries 创建数值系列，点的X为序号（从0开始）
+func NewSeries(name string, values ...float64) Series {
+	points := make([]Point, len(values))
+	for i, value := range values {
+		points[i] = Point{X: float64(i), Y: value}
+	}
+	return Series{Name: name, Points: points}
+}
+
+// NewXYSeries 创建数值系列，x和y长度不同时按较短的截断
+func NewXYSeries(name string, x, y []float64) Series {
+	n := min(len(x), len(y))
+	points := make([]Point, n)
+	for i := 0; i < n; i++ {
+		points[i] = Point{X: x[i], Y: y[i]}
+	}
+	return Series{Name: name, Points: points}
+}
+
+// NewTimeSeries 创建时间系列，使用该系列的图表X轴为时间轴
+func NewTimeSeries(name string, times []time.Time, values []float64) Series {
+	n := min(len(times), len(values))
+	points := make([]Point, n)
+	for i := 0; i < n; i++ {
+		points[i] = At(times[i], values[i])
+	}
+	return Series{Name: name, Points: points, kind: TimeAxis}
+}
+
+// NewCategorySeries 创建分类系列，使用该系列的图表X轴为分类轴
+//
+// 多个系列的分类按首次出现的顺序合并，相同名称的分类对齐到同一位置。
+func NewCategorySeries(name string, categories []string, values []float64) Series {
+	n := min(len(categories), len(values))
+	points := make([]Point, n)
+	labels := make([]string, n)
+	for i := 0; i < n; i++ {
+		points[i] = Point{X: float64(i), Y: values[i]}
+		labels[i] = categories[i]
+	}
+	return Series{Name: name, Points: points, kind: CategoryAxis, labels: labels}
+}
+
+// Axis 坐标轴设置
+type Axis struct {
+	Title string
+	// Format 数值的显示格式，用于刻度标签和提示，为nil时自动格式化；对时间轴和分类轴的X无效
+	Format func(value float64) string
+}
+
+// Chart 图表组件
+type Chart struct {
+	*widgets.BaseWidget
+	chartType  chartType
+	title      string
+	width      int
+	height     int
+	data       *chartData
+	xKind      AxisKind
+	xKindSet   bool // X轴类型由SetXAxisKind指定，否则根据系列推断
+	xAxis      Axis
+	yAxis      Axis
+	yMin, yMax float64
+	yRangeSet  bool
+}
+
+// chartData 图表的数据系列，实时图表的所有会话副本共享同一份数据
+type chartData struct {
+	mutex   sync.RWMutex
+	series  []Series
+	live    *liveState // 调用SetWindow或Append后不为nil
+	version uint64     // 每次修改数据加1，页面据此判断能否执行实时图表的增量更新
+}
+
+// newChart 创建指定类型的图表
+func newChart(chartType chartType, series []Series) *Chart {
+	return &Chart{
+		BaseWidget: widgets.NewBaseWidget(string(chartType)),
+		chartType:  chartType,
+		width:      defaultWidth,
+		height:     defaultHeight,
+		data:       &chartData{series: append([]Series(nil), series...)},
+	}
+}
+
+// NewLineChart 创建折线图
+func NewLineChart(series ...Series) *Chart {
+	return newChart(lineChart, series)
+}
+
+// NewBarChart 创建柱状图，多个系列在同一位置并排显示
+func NewBarChart(series ...Series) *Chart {
+	return newChart(barChart, series)
+}
+
+// NewAreaChart 创建面积图
+func NewAreaChart(series ...Series) *Chart {
+	return newChart(areaChart, series)
+}
+
+// NewScatterChart 创建散点图
+func NewScatterChart(series ...Series) *Chart {
+	return newChart(scatterChart, series)
+}
+
+// SetTitle 设置图表标题
+func (c *Chart) SetTitle(title string) {
+	c.title = title
+}
+
+// SetSize 设置图表的宽高（像素），图表宽度始终填满容器，此尺寸决定宽高比
+func (c *Chart) SetSize(width, height int) {
+	if width > 0 {
+		c.width = width
+	}
+	if height > 0 {
+		c.height = height
+	}
+}
+
+// SetSeries 设置数据系列，替换已有系列
+func (c *Chart) SetSeries(series ...Series) {
+	c.data.mutex.Lock()
+	defer c.data.mutex.Unlock()
+
+	c.data.series = append([]Series(nil), series...)
+	c.data.changed()
+}
+
+// AddSeries 添加数据系列
+func (c *Chart) AddSeries(series Series) {
+	c.data.mutex.Lock()
+	defer c.data.mutex.Unlock()
+
+	c.data.series = append(c.data.series, series)
+	c.data.changed()
+}
+
+// GetSeries 获取数据系列
+func (c *Chart) GetSeries() []Series {
+	c.data.mutex.RLock()
+	defer c.data.mutex.RUnlock()
+
+	series := make([]Series, len(c.data.series))
+	for i, s := range c.data.series {
+		s.Points = append([]Point(nil), s.Points...)
+		series[i] = s
+	}
+	return series
+}
+
+// SetXAxis 设置X轴
+func (c *Chart) SetXAxis(axis Axis) {
+	c.xAxis = axis
+}
+
+// SetYAxis 设置Y轴
+func (c *Chart) SetYAxis(axis Axis) {
+	c.yAxis = axis
+}
+
+// SetXAxisKind 指定X轴类型，默认根据系列推断：时间系列使用时间轴，分类系列使用分类轴，其他使用数值轴
+func (c *Chart) SetXAxisKind(kind AxisKind) {
+	c.xKind = kind
+	c.xKindSet = true
+}
+
+// SetYRange 固定Y轴范围，默认根据数据自动调整
+func (c *Chart) SetYRange(min, max float64) {
+	if math.IsNaN(min) || math.IsNaN(max) || min >= max {
+		return
+	}
+	c.yMin, c.yMax = min, max
+	c.yRangeSet = true
+}
+
+// axisKind 获取X轴类型，调用时需持有c.data.mutex
+func (c *Chart) axisKind() AxisKind {
+	if c.xKindSet {
+		return c.xKind
+	}
+	for _, series := range c.data.series {
+		if series.kind != NumberAxis {
+			return series.kind
+		}
+	}
+	return NumberAxis
+}
+
+// banded 判断X轴是否按分类等分，分类轴和柱状图的图形位置随分类数变化，不能增量更新，调用时需持有c.data.mutex
+func (c *Chart) banded() bool {
+	return c.axisKind() == CategoryAxis || c.chartType == barChart
+}
+
+// changed 系列被替换或添加后更新数据版本，页面在下一次增量更新时重新获取图表，调用时需持有d.mutex
+func (d *chartData) changed() {
+	d.version++
+	if d.live != nil {
+		d.live.setOrigin(d.series)
+	}
+}
+
+// Render 渲染图表，实时图表带有数据版本
+func (c *Chart) Render() string {
+	c.data.mutex.RLock()
+	defer c.data.mutex.RUnlock()
+
+	p := newPlot(c)
+	var version widgets.SafeHTML
+	if c.data.live != nil {
+		version = widgets.HTMLf(" data-version=\"%d\"", c.data.version)
+	}
+	return widgets.HTMLf("<div class=\"st-chart\" data-widget-id=\"%s\"%s>%s%s%s</div>",
+		c.GetID(), version, p.renderTitle(), p.renderLegend(), p.renderSVG()).String()
+}
+
+// Clone 复制图表，实时图表的副本与原图表共享数据
+func (c *Chart) Clone() widgets.Widget {
+	clone := *c
+	clone.BaseWidget = c.BaseWidget.CloneBase()
+
+	c.data.mutex.RLock()
+	defer c.data.mutex.RUnlock()
+	if c.data.live == nil {
+		clone.data = &chartData{series: make([]Series, len(c.data.series))}
+		for i, series := range c.data.series {
+			series.Points = append([]Point(nil), series.Points...)
+			series.labels = append([]string(nil), series.labels...)
+			clone.data.series[i] = series
+		}
+	}
+	return &clone
+}
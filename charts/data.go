@@ -0,0 +1,220 @@
+package charts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// table 按列名读取的表格数据
+type table struct {
+	columns []string
+	rows    []map[string]interface{}
+}
+
+// SetData 从表格数据设置系列，替换已有系列，每个y列对应一个以列名命名的系列
+//
+// data可以是结构体切片（元素可以是结构体指针）或[]map[string]interface{}。结构体的列名是字段名，
+// 有json标签时使用标签中的名称。x列是数值时使用数值轴，是time.Time时使用时间轴，是字符串时使用分类轴；
+// y列必须是数值，nil表示缺失。y为空时使用x以外的所有数值列，结构体按字段顺序，map按列名排序。
+func (c *Chart) SetData(data interface{}, x string, y ...string) error {
+	t, err := readTable(data)
+	if err != nil {
+		return err
+	}
+	if !t.hasColumn(x) {
+		return fmt.Errorf("charts: unknown column %q", x)
+	}
+	if len(y) == 0 {
+		for _, column := range t.columns {
+			if column != x && t.isNumeric(column) {
+				y = append(y, column)
+			}
+		}
+		if len(y) == 0 {
+			return errors.New("charts: no numeric columns")
+		}
+	}
+	for _, column := range y {
+		if !t.hasColumn(column) {
+			return fmt.Errorf("charts: unknown column %q", column)
+		}
+	}
+
+	// X轴类型由第一个非nil值决定
+	kind := NumberAxis
+	for _, row := range t.rows {
+		if row[x] == nil {
+			continue
+		}
+		switch row[x].(type) {
+		case time.Time:
+			kind = TimeAxis
+		case string:
+			kind = CategoryAxis
+		}
+		break
+	}
+
+	series := make([]Series, len(y))
+	for i, column := range y {
+		series[i] = Series{Name: column, Points: make([]Point, 0, len(t.rows)), kind: kind}
+	}
+	for rowIndex, row := range t.rows {
+		if row[x] == nil {
+			continue
+		}
+		var xValue float64
+		var label string
+		switch kind {
+		case TimeAxis:
+			value, ok := row[x].(time.Time)
+			if !ok {
+				return fmt.Errorf("charts: column %q row %d: expected time.Time, got %T", x, rowIndex, row[x])
+			}
+			xValue = float64(value.UnixNano()) / 1e9
+		case CategoryAxis:
+			value, ok := row[x].(string)
+			if !ok {
+				return fmt.Errorf("charts: column %q row %d: expected string, got %T", x, rowIndex, row[x])
+			}
+			label = value
+		default:
+			value, ok := toFloat(row[x])
+			if !ok {
+				return fmt.Errorf("charts: column %q row %d: unsupported value type %T", x, rowIndex, row[x])
+			}
+			if math.IsNaN(value) {
+				continue
+			}
+			xValue = value
+		}
+		for i, column := range y {
+			yValue, ok := toFloat(row[column])
+			if !ok {
+				return fmt.Errorf("charts: column %q row %d: unsupported value type %T", column, rowIndex, row[column])
+			}
+			if kind == CategoryAxis {
+				xValue = float64(len(series[i].Points))
+				series[i].labels = append(series[i].labels, label)
+			}
+			series[i].Points = append(series[i].Points, Point{X: xValue, Y: yValue})
+		}
+	}
+	c.data.mutex.Lock()
+	defer c.data.mutex.Unlock()
+
+	c.data.series = series
+	return nil
+}
+
+// readTable 读取结构体切片或map切片
+func readTable(data interface{}) (*table, error) {
+	if maps, ok := data.([]map[string]interface{}); ok {
+		t := &table{rows: maps}
+		seen := make(map[string]bool)
+		for _, row := range maps {
+			for column := range row {
+				if !seen[column] {
+					seen[column] = true
+					t.columns = append(t.columns, column)
+				}
+			}
+		}
+		sort.Strings(t.columns)
+		return t, nil
+	}
+
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("charts: unsupported data type %T", data)
+	}
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("charts: unsupported data type %T", data)
+	}
+
+	t := &table{}
+	fields := make([]int, 0)
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields = append(fields, i)
+		t.columns = append(t.columns, name)
+	}
+	for i := 0; i < value.Len(); i++ {
+		elem := value.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		row := make(map[string]interface{}, len(fields))
+		for j, field := range fields {
+			row[t.columns[j]] = elem.Field(field).Interface()
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// hasColumn 判断列是否存在
+func (t *table) hasColumn(column string) bool {
+	for _, c := range t.columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumeric 判断列的第一个非nil值是否为数值
+func (t *table) isNumeric(column string) bool {
+	for _, row := range t.rows {
+		value, exists := row[column]
+		if !exists || value == nil {
+			continue
+		}
+		_, ok := toFloat(value)
+		return ok
+	}
+	return false
+}
+
+// toFloat 把数值转换为float64，nil和nil指针转换为NaN
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return math.NaN(), true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return math.NaN(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
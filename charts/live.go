@@ -0,0 +1,221 @@
+package charts
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// DefaultLivePoints 实时图表每个系列默认最多保留的点数
+const DefaultLivePoints = 1000
+
+// liveState 实时图表的推送状态
+type liveState struct {
+	stream    *widgets.Stream
+	maxPoints int           // 每个系列最多保留的点数，0表示不限制
+	window    time.Duration // 只保留最新点之前这段时间内的点，0表示不限制
+	origin    float64       // 图形数据坐标的X原点，有数据后不再改变，使时间轴的坐标保持精度
+	originSet bool
+}
+
+// SetWindow 把图表设置为实时图表，并设置追加数据时的滑动窗口
+//
+// 每个系列最多保留maxPoints个点；window大于0时只保留X在最新点之前window时间内的点，仅对时间轴有效。
+// 参数为0表示不限制，未调用SetWindow的实时图表每个系列最多保留DefaultLivePoints个点。
+// 实时图表应在添加到页面之前设置，所有会话副本共享同一份数据。
+func (c *Chart) SetWindow(maxPoints int, window time.Duration) {
+	c.data.mutex.Lock()
+	defer c.data.mutex.Unlock()
+
+	live := c.data.liveState()
+	live.maxPoints = max(maxPoints, 0)
+	live.window = max(window, 0)
+	c.data.trim()
+	c.data.version++
+}
+
+// Append 向第一个系列追加数据点，没有系列时创建，可以在任何goroutine中调用
+//
+// 折线图、面积图和散点图按X顺序追加时，服务只向正在显示图表的会话发送新的点、每个系列删除的点数和新的坐标轴，
+// 页面在已有的SVG中合并，消息大小与追加的点数成正比，与窗口内的点数无关。
+// 新建系列、乱序追加以及柱状图和分类轴图表重新渲染整个图表。
+// 时间轴图表的初始系列应使用NewTimeSeries创建，或者调用SetXAxisKind(TimeAxis)。
+func (c *Chart) Append(points ...Point) {
+	c.data.mutex.Lock()
+	created := len(c.data.series) == 0
+	if created {
+		c.data.series = append(c.data.series, Series{})
+	}
+	update := c.appendLocked(0, points, created)
+	stream := c.data.live.stream
+	c.data.mutex.Unlock()
+
+	// 没有增量更新时服务重新渲染图表，需要在释放锁之后通知
+	stream.Publish(update)
+}
+
+// AppendTo 向指定名称的系列追加数据点，系列不存在时创建，可以在任何goroutine中调用
+func (c *Chart) AppendTo(name string, points ...Point) {
+	c.data.mutex.Lock()
+	index := -1
+	for i, series := range c.data.series {
+		if series.Name == name {
+			index = i
+			break
+		}
+	}
+	created := index < 0
+	if created {
+		index = len(c.data.series)
+		c.data.series = append(c.data.series, Series{Name: name})
+	}
+	update := c.appendLocked(index, points, created)
+	stream := c.data.live.stream
+	c.data.mutex.Unlock()
+
+	stream.Publish(update)
+}
+
+// appendLocked 追加数据点并按滑动窗口删除旧的点，返回增量更新，不能增量更新时返回nil，调用时需持有c.data.mutex
+func (c *Chart) appendLocked(index int, points []Point, created bool) *widgets.StreamUpdate {
+	live := c.data.liveState()
+	before := make([]int, len(c.data.series))
+	for i, series := range c.data.series {
+		before[i] = len(series.Points)
+	}
+	series := &c.data.series[index]
+	ordered := c.chartType == scatterChart || inOrder(series.Points, points)
+	series.Points = append(series.Points, points...)
+	live.setOrigin(c.data.series)
+	drops := c.data.trim()
+	from := c.data.version
+	c.data.version++
+
+	if created || !ordered || c.banded() {
+		return nil
+	}
+	return c.liveUpdate(from, before, drops)
+}
+
+// inOrder 判断追加的点是否按X不减的顺序排在已有的点之后
+func inOrder(current, points []Point) bool {
+	last := math.Inf(-1)
+	if n := len(current); n > 0 {
+		last = current[n-1].X
+	}
+	for _, point := range points {
+		if !(point.X >= last) {
+			return false
+		}
+		last = point.X
+	}
+	return true
+}
+
+// liveUpdate 生成从版本from到当前版本的增量更新，调用时需持有c.data.mutex
+//
+// before是追加前每个系列的点数，drops是本次删除的点数。页面中每个点对应系列分组中的一个子元素，
+// 删除的点超过页面已有的点时，新追加但已经移出窗口的点不发送。
+func (c *Chart) liveUpdate(from uint64, before []int, drops []int) *widgets.StreamUpdate {
+	p := newPlot(c)
+	ops := []widgets.StreamOp{
+		{Target: ".st-chart-axes", HTML: p.renderAxes().String()},
+		{Target: ".st-chart-live", Attrs: map[string]string{"transform": p.liveTransform()}},
+	}
+	for i, series := range p.series {
+		drop := min(drops[i], before[i])
+		start := before[i] - drop
+		if drop == 0 && start == len(series.points) {
+			continue
+		}
+		w := &svgWriter{}
+		for j := start; j < len(series.points); j++ {
+			p.renderLivePoint(w, series, j)
+		}
+		ops = append(ops, widgets.StreamOp{
+			Target: "[data-series=\"" + strconv.Itoa(i) + "\"]",
+			Drop:   drop,
+			Append: w.String(),
+		})
+	}
+	return &widgets.StreamUpdate{From: from, To: c.data.version, Ops: ops}
+}
+
+// IsViewEvent 页面错过增量更新时发送的resync事件只重新渲染图表
+func (c *Chart) IsViewEvent(event string) bool {
+	return event == "resync"
+}
+
+// Stream 获取实时图表的数据流，实现widgets.IStreamer，非实时图表返回nil
+func (c *Chart) Stream() *widgets.Stream {
+	c.data.mutex.RLock()
+	defer c.data.mutex.RUnlock()
+
+	if c.data.live == nil {
+		return nil
+	}
+	return c.data.live.stream
+}
+
+// liveState 获取实时状态，不存在时创建，调用时需持有d.mutex
+func (d *chartData) liveState() *liveState {
+	if d.live == nil {
+		d.live = &liveState{stream: widgets.NewStream(), maxPoints: DefaultLivePoints}
+		d.live.setOrigin(d.series)
+	}
+	return d.live
+}
+
+// setOrigin 第一次有数据时确定X原点，调用时需持有图表数据的写锁
+func (l *liveState) setOrigin(series []Series) {
+	if l.originSet {
+		return
+	}
+	for _, s := range series {
+		for _, point := range s.Points {
+			if !math.IsNaN(point.X) && !math.IsInf(point.X, 0) {
+				l.origin = point.X
+				l.originSet = true
+				return
+			}
+		}
+	}
+}
+
+// trim 按滑动窗口删除旧的点，返回每个系列删除的点数，调用时需持有d.mutex
+func (d *chartData) trim() []int {
+	drops := make([]int, len(d.series))
+	if d.live == nil {
+		return drops
+	}
+	latest := math.Inf(-1)
+	for _, series := range d.series {
+		if n := len(series.Points); n > 0 {
+			latest = math.Max(latest, series.Points[n-1].X)
+		}
+	}
+	for i := range d.series {
+		series := &d.series[i]
+		drop := 0
+		if d.live.maxPoints > 0 && len(series.Points) > d.live.maxPoints {
+			drop = len(series.Points) - d.live.maxPoints
+		}
+		if d.live.window > 0 {
+			cutoff := latest - d.live.window.Seconds()
+			for drop < len(series.Points) && series.Points[drop].X < cutoff {
+				drop++
+			}
+		}
+		if drop == 0 {
+			continue
+		}
+		if len(series.labels) == len(series.Points) {
+			series.labels = series.labels[drop:]
+		}
+		series.Points = series.Points[drop:]
+		drops[i] = drop
+	}
+	return drops
+}
@@ -0,0 +1,590 @@
+package charts
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+const (
+	fontSize       = 12
+	tickSpacing    = 80  // X轴刻度的最小间距（像素）
+	maxHoverPoints = 500 // 折线和面积每个系列最多生成的提示点数，超出时等间隔抽样
+	maxLabelRunes  = 16  // 分类标签的最大字符数
+)
+
+// palette 默认调色板
+var palette = []string{"#1c83e1", "#ff8700", "#21c354", "#ff2b2b", "#803df5", "#00c0f2", "#ffd16a", "#808495"}
+
+// colorPattern 允许的颜色格式：十六进制、颜色名称和rgb/hsl函数
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|(rgb|rgba|hsl|hsla)\([0-9.,% ]+\))$`)
+
+// plotSeries 准备好绘制的系列
+type plotSeries struct {
+	name   string
+	color  string
+	points []Point // 分类轴和柱状图中X为分类序号
+	labels []string
+}
+
+// plot 一次渲染的布局和比例尺
+type plot struct {
+	chart      *Chart
+	kind       AxisKind
+	banded     bool // X轴按分类等分，用于分类轴和柱状图
+	series     []plotSeries
+	categories []string
+	xMin, xMax float64
+	yMin, yMax float64
+	xTicks     []tick
+	yTicks     []tick
+	xFormat    func(float64) string // 提示中X的格式
+	yFormat    func(float64) string // 提示中Y的格式
+	empty      bool
+	live       bool    // 实时图表的图形使用数据坐标，见renderLive
+	origin     float64 // 数据坐标的X原点
+
+	// 绘图区域的边界（像素）
+	left, right, top, bottom float64
+}
+
+// newPlot 计算图表的比例尺和布局
+func newPlot(c *Chart) *plot {
+	p := &plot{chart: c, kind: c.axisKind()}
+	p.banded = c.banded()
+	if c.data.live != nil && !p.banded {
+		p.live = true
+		p.origin = c.data.live.origin
+	}
+	p.prepareSeries()
+	p.prepareY()
+	p.prepareX()
+	return p
+}
+
+// prepareSeries 确定系列颜色，把分类和柱状图的X转换为分类序号
+func (p *plot) prepareSeries() {
+	c := p.chart
+	p.series = make([]plotSeries, len(c.data.series))
+	for i, series := range c.data.series {
+		color := seriesColor(i, series)
+		points := append([]Point(nil), series.Points...)
+		if c.chartType == lineChart || c.chartType == areaChart {
+			if p.kind != CategoryAxis {
+				sort.SliceStable(points, func(a, b int) bool { return points[a].X < points[b].X })
+			}
+		}
+		p.series[i] = plotSeries{name: series.Name, color: color, points: points, labels: series.labels}
+	}
+
+	p.xFormat = formatValue
+	if c.xAxis.Format != nil {
+		p.xFormat = c.xAxis.Format
+	}
+	if p.kind == TimeAxis {
+		p.xFormat = formatTime
+	}
+	p.yFormat = formatValue
+	if c.yAxis.Format != nil {
+		p.yFormat = c.yAxis.Format
+	}
+	if !p.banded {
+		return
+	}
+
+	// 分类按首次出现的顺序合并；柱状图的数值和时间X按大小排序后作为分类
+	index := make(map[string]int)
+	addCategory := func(label string) int {
+		if i, ok := index[label]; ok {
+			return i
+		}
+		index[label] = len(p.categories)
+		p.categories = append(p.categories, label)
+		return index[label]
+	}
+	if p.kind == TimeAxis && dateOnly(p.series) {
+		p.xFormat = func(value float64) string { return toTime(value).Format("2006-01-02") }
+	}
+	if p.kind != CategoryAxis {
+		values := make([]float64, 0)
+		for _, series := range p.series {
+			for _, point := range series.points {
+				values = append(values, point.X)
+			}
+		}
+		sort.Float64s(values)
+		for _, value := range values {
+			addCategory(p.xFormat(value))
+		}
+	}
+	for i := range p.series {
+		series := &p.series[i]
+		labels := make([]string, len(series.points))
+		for j, point := range series.points {
+			label := p.xFormat(point.X)
+			if p.kind == CategoryAxis && len(series.labels) == len(series.points) {
+				label = series.labels[j]
+			}
+			labels[j] = label
+			series.points[j].X = float64(addCategory(label))
+		}
+		series.labels = labels
+	}
+}
+
+// seriesColor 获取系列的颜色，未设置或无效时使用调色板中的颜色
+func seriesColor(index int, series Series) string {
+	if colorPattern.MatchString(series.Color) {
+		return series.Color
+	}
+	return palette[index%len(palette)]
+}
+
+// dateOnly 判断所有时间点是否都是本地时间的零点
+func dateOnly(series []plotSeries) bool {
+	for _, s := range series {
+		for _, point := range s.points {
+			t := toTime(point.X)
+			if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// prepareY 计算Y轴范围和刻度
+func (p *plot) prepareY() {
+	c := p.chart
+	p.yMin, p.yMax = math.Inf(1), math.Inf(-1)
+	for _, series := range p.series {
+		for _, point := range series.points {
+			if !math.IsNaN(point.Y) && !math.IsInf(point.Y, 0) {
+				p.yMin = math.Min(p.yMin, point.Y)
+				p.yMax = math.Max(p.yMax, point.Y)
+			}
+		}
+	}
+	if math.IsInf(p.yMin, 1) {
+		p.empty = true
+		p.yMin, p.yMax = 0, 1
+	}
+	// 柱状图和面积图从0开始
+	if c.chartType == barChart || c.chartType == areaChart {
+		p.yMin = math.Min(p.yMin, 0)
+		p.yMax = math.Max(p.yMax, 0)
+	}
+	if p.yMin == p.yMax {
+		delta := math.Max(math.Abs(p.yMin)*0.1, 1)
+		p.yMin, p.yMax = p.yMin-delta, p.yMax+delta
+	}
+
+	count := max(2, (c.height-60)/50)
+	var step float64
+	if c.yRangeSet {
+		p.yMin, p.yMax = c.yMin, c.yMax
+		step = niceStep(p.yMax-p.yMin, count)
+	} else {
+		p.yMin, p.yMax, step = niceDomain(p.yMin, p.yMax, count)
+	}
+	format := numberFormatter(p.yMin, p.yMax, step)
+	if c.yAxis.Format != nil {
+		format = c.yAxis.Format
+	}
+	p.yTicks = numberTicks(p.yMin, p.yMax, step, format)
+
+	// 左边距容纳最宽的刻度标签和Y轴标题
+	labelWidth := 0.0
+	for _, t := range p.yTicks {
+		labelWidth = math.Max(labelWidth, textWidth(t.label))
+	}
+	p.left = labelWidth + 14
+	if c.yAxis.Title != "" {
+		p.left += fontSize + 8
+	}
+	p.top = 10
+	p.right = float64(c.width) - 16
+	p.bottom = float64(c.height) - (fontSize + 14)
+	if c.xAxis.Title != "" {
+		p.bottom -= fontSize + 8
+	}
+}
+
+// prepareX 计算X轴范围和刻度
+func (p *plot) prepareX() {
+	c := p.chart
+	width := p.right - p.left
+	if p.banded {
+		p.xMin, p.xMax = 0, float64(len(p.categories))
+		if len(p.categories) == 0 {
+			return
+		}
+		// 标签过密时间隔显示
+		labelWidth := 0.0
+		for _, category := range p.categories {
+			labelWidth = math.Max(labelWidth, textWidth(truncate(category, maxLabelRunes)))
+		}
+		every := max(1, int(math.Ceil((labelWidth+8)/(width/float64(len(p.categories))))))
+		for i := 0; i < len(p.categories); i += every {
+			p.xTicks = append(p.xTicks, tick{value: float64(i), label: truncate(p.categories[i], maxLabelRunes)})
+		}
+		return
+	}
+
+	p.xMin, p.xMax = math.Inf(1), math.Inf(-1)
+	for _, series := range p.series {
+		for _, point := range series.points {
+			if !math.IsNaN(point.X) && !math.IsInf(point.X, 0) {
+				p.xMin = math.Min(p.xMin, point.X)
+				p.xMax = math.Max(p.xMax, point.X)
+			}
+		}
+	}
+	if math.IsInf(p.xMin, 1) {
+		p.xMin, p.xMax = 0, 1
+	}
+	if p.xMin == p.xMax {
+		p.xMin, p.xMax = p.xMin-1, p.xMax+1
+	}
+	count := max(2, int(width/tickSpacing))
+	if p.kind == TimeAxis {
+		p.xTicks = timeTicks(p.xMin, p.xMax, count)
+		return
+	}
+	step := niceStep(p.xMax-p.xMin, count)
+	format := numberFormatter(p.xMin, p.xMax, step)
+	if c.xAxis.Format != nil {
+		format = c.xAxis.Format
+	}
+	p.xTicks = numberTicks(p.xMin, p.xMax, step, format)
+}
+
+// band 分类的宽度（像素）
+func (p *plot) band() float64 {
+	return (p.right - p.left) / math.Max(1, float64(len(p.categories)))
+}
+
+// xPos X坐标转换为像素，分类轴取分类的中心
+func (p *plot) xPos(x float64) float64 {
+	if p.banded {
+		return p.left + (x+0.5)*p.band()
+	}
+	return p.left + (x-p.xMin)/(p.xMax-p.xMin)*(p.right-p.left)
+}
+
+// yPos Y坐标转换为像素
+func (p *plot) yPos(y float64) float64 {
+	return p.bottom - (y-p.yMin)/(p.yMax-p.yMin)*(p.bottom-p.top)
+}
+
+// baseline 柱状图和面积图的基线（像素），0不在范围内时取最近的边界
+func (p *plot) baseline() float64 {
+	return p.yPos(math.Max(p.yMin, math.Min(p.yMax, 0)))
+}
+
+// tooltip 数据点的提示文本
+func (p *plot) tooltip(series plotSeries, i int) string {
+	point := series.points[i]
+	x := ""
+	if p.banded {
+		x = series.labels[i]
+	} else {
+		x = p.xFormat(point.X)
+	}
+	text := x + ": " + p.yFormat(point.Y)
+	if series.name != "" {
+		text = series.name + "\n" + text
+	}
+	return text
+}
+
+// renderTitle 渲染标题
+func (p *plot) renderTitle() widgets.SafeHTML {
+	if p.chart.title == "" {
+		return ""
+	}
+	return widgets.HTMLf("<div class=\"st-chart-title\">%s</div>", p.chart.title)
+}
+
+// renderLegend 渲染图例，多个系列时显示
+func (p *plot) renderLegend() widgets.SafeHTML {
+	if len(p.series) < 2 {
+		return ""
+	}
+	var items widgets.SafeHTML
+	for i, series := range p.series {
+		name := series.name
+		if name == "" {
+			name = "系列" + strconv.Itoa(i+1)
+		}
+		items += widgets.HTMLf("<span class=\"st-chart-legend-item\"><span class=\"st-chart-swatch\" style=\"background-color: %s\"></span>%s</span>",
+			series.color, name)
+	}
+	return widgets.HTMLf("<div class=\"st-chart-legend\">%s</div>", items)
+}
+
+// svgWriter 拼接SVG元素，所有参数经HTMLf转义
+type svgWriter struct {
+	strings.Builder
+}
+
+// printf 按格式写入一个元素
+func (w *svgWriter) printf(format string, args ...interface{}) {
+	w.WriteString(widgets.HTMLf(format, args...).String())
+}
+
+// renderSVG 渲染SVG图形
+func (p *plot) renderSVG() widgets.SafeHTML {
+	c := p.chart
+	w := &svgWriter{}
+	w.printf("<svg class=\"st-chart-svg\" viewBox=\"0 0 %d %d\" font-size=\"%d\" role=\"img\" aria-label=\"%s\">",
+		c.width, c.height, fontSize, c.ariaLabel())
+
+	w.printf("<g class=\"st-chart-axes\">%s</g>", p.renderAxes())
+
+	if p.live {
+		p.renderLive(w)
+	} else if !p.empty {
+		if c.yRangeSet {
+			// 固定Y轴范围时裁剪超出范围的部分，留出数据点的半径
+			w.printf("%s<g class=\"st-chart-series\" clip-path=\"url(#%s)\">", p.clipPath(), p.clipID())
+		} else {
+			w.WriteString("<g class=\"st-chart-series\">")
+		}
+		for i, series := range p.series {
+			switch c.chartType {
+			case barChart:
+				p.renderBars(w, series, i)
+			case scatterChart:
+				p.renderScatter(w, series)
+			default:
+				p.renderLine(w, series)
+			}
+		}
+		w.WriteString("</g>")
+	}
+	w.WriteString("</svg>")
+	return widgets.SafeHTML(w.String())
+}
+
+// renderAxes 渲染网格线、坐标轴和没有数据时的提示，实时图表追加数据时整体替换
+func (p *plot) renderAxes() widgets.SafeHTML {
+	c := p.chart
+	w := &svgWriter{}
+	w.WriteString("<g class=\"st-chart-grid\">")
+	for _, t := range p.yTicks {
+		y := p.yPos(t.value)
+		w.printf("<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\"></line>", p.left, y, p.right, y)
+	}
+	w.WriteString("</g><g class=\"st-chart-axis\">")
+	for _, t := range p.yTicks {
+		w.printf("<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\" dominant-baseline=\"middle\">%s</text>", p.left-6, p.yPos(t.value), t.label)
+	}
+	w.printf("<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\"></line>", p.left, p.bottom, p.right, p.bottom)
+	for _, t := range p.xTicks {
+		w.printf("<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%s</text>", p.xPos(t.value), p.bottom+fontSize+6, t.label)
+	}
+	if c.xAxis.Title != "" {
+		w.printf("<text class=\"st-chart-axis-title\" x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>",
+			(p.left+p.right)/2, c.height-6, c.xAxis.Title)
+	}
+	if c.yAxis.Title != "" {
+		w.printf("<text class=\"st-chart-axis-title\" transform=\"rotate(-90)\" x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>",
+			-(p.top+p.bottom)/2, fontSize+2, c.yAxis.Title)
+	}
+	w.WriteString("</g>")
+	if p.empty {
+		w.printf("<text class=\"st-chart-empty\" x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">暂无数据</text>",
+			(p.left+p.right)/2, (p.top+p.bottom)/2)
+	}
+	return widgets.SafeHTML(w.String())
+}
+
+// clipID 图形裁剪区域的ID
+func (p *plot) clipID() string {
+	return "st-chart-clip-" + p.chart.GetID()
+}
+
+// clipPath 把图形裁剪到绘图区域，留出数据点的半径
+func (p *plot) clipPath() widgets.SafeHTML {
+	return widgets.HTMLf("<clipPath id=\"%s\"><rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\"></rect></clipPath>",
+		p.clipID(), p.left-4, p.top-4, p.right-p.left+8, p.bottom-p.top+8)
+}
+
+// ariaLabel 图表的无障碍名称，没有标题时使用图表类型
+func (c *Chart) ariaLabel() string {
+	if c.title != "" {
+		return c.title
+	}
+	return strings.TrimSuffix(string(c.chartType), "_chart") + " chart"
+}
+
+// segments 按缺失值把系列分成连续的段
+func segments(points []Point) [][]Point {
+	result := make([][]Point, 0)
+	start := -1
+	for i, point := range points {
+		missing := math.IsNaN(point.Y) || math.IsInf(point.Y, 0)
+		if !missing && start < 0 {
+			start = i
+		}
+		if missing && start >= 0 {
+			result = append(result, points[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, points[start:])
+	}
+	return result
+}
+
+// renderLine 渲染折线和面积，数据点在鼠标悬停时显示并提示数值
+func (p *plot) renderLine(w *svgWriter, series plotSeries) {
+	var line, area strings.Builder
+	for _, segment := range segments(series.points) {
+		for i, point := range segment {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			line.WriteString(command + formatCoord(p.xPos(point.X), p.yPos(point.Y)))
+		}
+		if p.chart.chartType == areaChart {
+			base := p.baseline()
+			area.WriteString("M" + formatCoord(p.xPos(segment[0].X), base))
+			for _, point := range segment {
+				area.WriteString("L" + formatCoord(p.xPos(point.X), p.yPos(point.Y)))
+			}
+			area.WriteString("L" + formatCoord(p.xPos(segment[len(segment)-1].X), base) + "Z")
+		}
+	}
+	if area.Len() > 0 {
+		w.printf("<path class=\"st-chart-area\" d=\"%s\" fill=\"%s\" fill-opacity=\"0.3\"></path>", area.String(), series.color)
+	}
+	if line.Len() > 0 {
+		w.printf("<path class=\"st-chart-line\" d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linejoin=\"round\"></path>",
+			line.String(), series.color)
+	}
+
+	step := max(1, int(math.Ceil(float64(len(series.points))/maxHoverPoints)))
+	for i := 0; i < len(series.points); i += step {
+		point := series.points[i]
+		if math.IsNaN(point.Y) || math.IsInf(point.Y, 0) {
+			continue
+		}
+		w.printf("<circle class=\"st-chart-point\" cx=\"%.1f\" cy=\"%.1f\" r=\"4\" fill=\"%s\"><title>%s</title></circle>",
+			p.xPos(point.X), p.yPos(point.Y), series.color, p.tooltip(series, i))
+	}
+}
+
+// renderScatter 渲染散点
+func (p *plot) renderScatter(w *svgWriter, series plotSeries) {
+	for i, point := range series.points {
+		if math.IsNaN(point.Y) || math.IsInf(point.Y, 0) || math.IsNaN(point.X) || math.IsInf(point.X, 0) {
+			continue
+		}
+		w.printf("<circle class=\"st-chart-dot\" cx=\"%.1f\" cy=\"%.1f\" r=\"4\" fill=\"%s\" fill-opacity=\"0.7\"><title>%s</title></circle>",
+			p.xPos(point.X), p.yPos(point.Y), series.color, p.tooltip(series, i))
+	}
+}
+
+// renderBars 渲染柱形，多个系列在每个分类内并排
+func (p *plot) renderBars(w *svgWriter, series plotSeries, index int) {
+	group := p.band() * 0.8
+	width := group / float64(len(p.series))
+	base := p.baseline()
+	for i, point := range series.points {
+		if math.IsNaN(point.Y) || math.IsInf(point.Y, 0) {
+			continue
+		}
+		x := p.left + point.X*p.band() + (p.band()-group)/2 + float64(index)*width
+		y := p.yPos(point.Y)
+		w.printf("<rect class=\"st-chart-bar\" x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"><title>%s</title></rect>",
+			x, math.Min(y, base), math.Max(width-1, 1), math.Abs(base-y), series.color, p.tooltip(series, i))
+	}
+}
+
+// formatCoord 格式化路径中的坐标
+func formatCoord(x, y float64) string {
+	return strconv.FormatFloat(x, 'f', 1, 64) + "," + strconv.FormatFloat(y, 'f', 1, 64)
+}
+
+// renderLive 渲染实时图表的系列
+//
+// 图形使用数据坐标（X减去原点），由st-chart-live分组的变换映射到绘图区域，线宽和点的大小不随变换缩放。
+// 每个系列是一个data-series分组，每个数据点对应其中的一个子元素，包含到前一个点的线段、面积和提示点。
+// 追加数据时只需发送新的点、删除移出窗口的点并更新变换和坐标轴，已有的图形不用重新渲染。
+func (p *plot) renderLive(w *svgWriter) {
+	w.printf("%s<g class=\"st-chart-series\" clip-path=\"url(#%s)\"><g class=\"st-chart-live\" transform=\"%s\">",
+		p.clipPath(), p.clipID(), p.liveTransform())
+	for i, series := range p.series {
+		w.printf("<g data-series=\"%d\">", i)
+		for j := range series.points {
+			p.renderLivePoint(w, series, j)
+		}
+		w.WriteString("</g>")
+	}
+	w.WriteString("</g></g>")
+}
+
+// liveTransform 数据坐标到像素的变换
+func (p *plot) liveTransform() string {
+	sx := (p.right - p.left) / (p.xMax - p.xMin)
+	sy := (p.bottom - p.top) / (p.yMax - p.yMin)
+	return "matrix(" + formatNumber(sx) + " 0 0 " + formatNumber(-sy) + " " +
+		formatNumber(p.left-(p.xMin-p.origin)*sx) + " " + formatNumber(p.bottom+p.yMin*sy) + ")"
+}
+
+// renderLivePoint 渲染实时图表的一个数据点，缺失的点渲染为空分组
+func (p *plot) renderLivePoint(w *svgWriter, series plotSeries, i int) {
+	point := series.points[i]
+	if !finitePoint(point) {
+		w.WriteString("<g></g>")
+		return
+	}
+	c := p.chart
+	current := p.liveCoord(point)
+	w.WriteString("<g>")
+	if c.chartType != scatterChart && i > 0 && finitePoint(series.points[i-1]) {
+		previous := p.liveCoord(series.points[i-1])
+		if c.chartType == areaChart {
+			// 基线为0，超出Y轴范围的部分被裁剪
+			w.printf("<path class=\"st-chart-area\" d=\"M%s,0L%sL%sL%s,0Z\" fill=\"%s\" fill-opacity=\"0.3\"></path>",
+				p.liveX(series.points[i-1].X), previous, current, p.liveX(point.X), series.color)
+		}
+		w.printf("<path class=\"st-chart-line\" d=\"M%sL%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linecap=\"round\" vector-effect=\"non-scaling-stroke\"></path>",
+			previous, current, series.color)
+	}
+	class, opacity := "st-chart-live-point", "1"
+	if c.chartType == scatterChart {
+		class, opacity = "st-chart-live-dot", "0.7"
+	}
+	// 长度为0的圆头线段在缩放后仍是圆点
+	w.printf("<path class=\"%s\" d=\"M%sh0\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"8\" stroke-linecap=\"round\" vector-effect=\"non-scaling-stroke\"><title>%s</title></path></g>",
+		class, current, series.color, opacity, p.tooltip(series, i))
+}
+
+// liveX 数据坐标中的X
+func (p *plot) liveX(x float64) string {
+	return formatNumber(x - p.origin)
+}
+
+// liveCoord 数据坐标中的点
+func (p *plot) liveCoord(point Point) string {
+	return p.liveX(point.X) + "," + formatNumber(point.Y)
+}
+
+// finitePoint 判断点的X和Y都是有限值
+func finitePoint(point Point) bool {
+	return !math.IsNaN(point.X) && !math.IsInf(point.X, 0) && !math.IsNaN(point.Y) && !math.IsInf(point.Y, 0)
+}
+
+// formatNumber 格式化变换和数据坐标中的数值，保留8位有效数字
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', 8, 64)
+}
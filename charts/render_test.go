@@ -0,0 +1,241 @@
+package charts
+
+import (
+	"html"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	xTickPattern  = regexp.MustCompile(`<text x="[^"]*" y="[^"]*" text-anchor="middle">([^<]*)</text>`)
+	yTickPattern  = regexp.MustCompile(`text-anchor="end" dominant-baseline="middle">([^<]*)</text>`)
+	linePattern   = regexp.MustCompile(`class="st-chart-line" d="([^"]*)"`)
+	areaPattern   = regexp.MustCompile(`class="st-chart-area" d="([^"]*)"`)
+	numberPattern = regexp.MustCompile(`"[^"]*(NaN|Inf)[^"]*"`)
+)
+
+// tickLabels 获取渲染结果中的刻度标签
+func tickLabels(pattern *regexp.Regexp, output string) []string {
+	labels := make([]string, 0)
+	for _, match := range pattern.FindAllStringSubmatch(output, -1) {
+		labels = append(labels, html.UnescapeString(match[1]))
+	}
+	return labels
+}
+
+// assertFinite 检查渲染结果的属性中没有NaN和Inf
+func assertFinite(t *testing.T, output string) {
+	t.Helper()
+	if match := numberPattern.FindString(output); match != "" {
+		t.Errorf("output contains a non-finite attribute %s", match)
+	}
+}
+
+// TestChartEmpty 没有数据或只有缺失值时显示提示，不渲染图形
+func TestChartEmpty(t *testing.T) {
+	charts := map[string]*Chart{
+		"no series":      NewLineChart(),
+		"empty series":   NewLineChart(NewSeries("a")),
+		"all missing":    NewAreaChart(NewSeries("a", math.NaN(), math.Inf(1))),
+		"empty bar":      NewBarChart(NewCategorySeries("a", nil, nil)),
+		"empty scatter":  NewScatterChart(NewXYSeries("a", []float64{1}, nil)),
+		"empty time":     NewLineChart(NewTimeSeries("a", nil, nil)),
+		"missing scaled": NewLineChart(NewSeries("a", math.NaN())),
+	}
+	charts["missing scaled"].SetYRange(0, 10)
+	for name, chart := range charts {
+		output := chart.Render()
+		if !strings.Contains(output, "暂无数据") {
+			t.Errorf("%s: no empty hint: %s", name, output)
+		}
+		for _, shape := range []string{"st-chart-line", "st-chart-area", "st-chart-point", "st-chart-bar", "st-chart-dot"} {
+			if strings.Contains(output, shape) {
+				t.Errorf("%s: renders %s", name, shape)
+			}
+		}
+		assertFinite(t, output)
+	}
+}
+
+// TestChartMissingValues 缺失值使折线和面积断开，柱形和数据点跳过缺失值
+func TestChartMissingValues(t *testing.T) {
+	values := []float64{1, math.NaN(), 3, 4, math.Inf(-1), 6}
+
+	output := NewLineChart(NewSeries("a", values...)).Render()
+	lines := linePattern.FindAllStringSubmatch(output, -1)
+	if len(lines) != 1 || strings.Count(lines[0][1], "M") != 3 {
+		t.Errorf("line paths %v, want one path with 3 segments", lines)
+	}
+	if n := strings.Count(output, "st-chart-point"); n != 4 {
+		t.Errorf("%d hover points, want 4", n)
+	}
+	assertFinite(t, output)
+
+	output = NewAreaChart(NewSeries("a", values...)).Render()
+	areas := areaPattern.FindAllStringSubmatch(output, -1)
+	if len(areas) != 1 || strings.Count(areas[0][1], "Z") != 3 {
+		t.Errorf("area paths %v, want one path with 3 closed segments", areas)
+	}
+	assertFinite(t, output)
+
+	output = NewBarChart(NewSeries("a", values...)).Render()
+	if n := strings.Count(output, "st-chart-bar"); n != 4 {
+		t.Errorf("%d bars, want 4", n)
+	}
+	assertFinite(t, output)
+
+	output = NewScatterChart(NewXYSeries("a", []float64{1, math.NaN(), 3}, []float64{1, 2, math.NaN()})).Render()
+	if n := strings.Count(output, "st-chart-dot"); n != 1 {
+		t.Errorf("%d dots, want 1", n)
+	}
+	assertFinite(t, output)
+}
+
+// TestChartSinglePoint 只有一个点时坐标轴范围扩展到点的两侧，图形位于绘图区域内
+func TestChartSinglePoint(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	charts := map[string]*Chart{
+		"line":     NewLineChart(NewSeries("a", 5)),
+		"area":     NewAreaChart(NewSeries("a", 0)),
+		"bar":      NewBarChart(NewSeries("a", -3)),
+		"scatter":  NewScatterChart(NewXYSeries("a", []float64{2}, []float64{2})),
+		"time":     NewLineChart(NewTimeSeries("a", []time.Time{at}, []float64{1})),
+		"category": NewLineChart(NewCategorySeries("a", []string{"唯一"}, []float64{1})),
+	}
+	for name, chart := range charts {
+		output := chart.Render()
+		if strings.Contains(output, "暂无数据") {
+			t.Errorf("%s: single point treated as empty", name)
+		}
+		if len(tickLabels(yTickPattern, output)) < 2 {
+			t.Errorf("%s: y ticks %v", name, tickLabels(yTickPattern, output))
+		}
+		if len(tickLabels(xTickPattern, output)) == 0 {
+			t.Errorf("%s: no x ticks", name)
+		}
+		assertFinite(t, output)
+	}
+
+	output := charts["line"].Render()
+	lines := linePattern.FindAllStringSubmatch(output, -1)
+	if len(lines) != 1 || strings.Count(lines[0][1], "M") != 1 || strings.Contains(lines[0][1], "L") {
+		t.Errorf("single point line path %v", lines)
+	}
+	if n := strings.Count(output, "st-chart-point"); n != 1 {
+		t.Errorf("%d hover points, want 1", n)
+	}
+}
+
+// TestChartAxes 数值轴、时间轴和分类轴的刻度标签
+func TestChartAxes(t *testing.T) {
+	output := NewLineChart(NewXYSeries("a", []float64{0, 5, 10}, []float64{0, 50, 100})).Render()
+	if got := tickLabels(xTickPattern, output); len(got) < 2 || got[0] != "0" || got[len(got)-1] != "10" {
+		t.Errorf("number x ticks %v, want 0 to 10", got)
+	}
+	if got := tickLabels(yTickPattern, output); len(got) < 2 || got[0] != "0" || got[len(got)-1] != "100" {
+		t.Errorf("number y ticks %v, want 0 to 100", got)
+	}
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	times := make([]time.Time, 5)
+	for i := range times {
+		times[i] = start.AddDate(0, 0, i*2)
+	}
+	output = NewLineChart(NewTimeSeries("a", times, []float64{1, 2, 3, 4, 5})).Render()
+	got := tickLabels(xTickPattern, output)
+	if len(got) < 2 || got[0] != "03-01" || got[len(got)-1] != "03-09" {
+		t.Errorf("time x ticks %v, want dates from 03-01 to 03-09", got)
+	}
+	if !strings.Contains(output, "2024-03-05 00:00:00: 3") {
+		t.Errorf("time tooltip not formatted as a time: %s", output)
+	}
+
+	// 只有日期的时间点在柱状图中作为日期分类
+	output = NewBarChart(NewTimeSeries("a", times[:3], []float64{1, 2, 3})).Render()
+	if got := tickLabels(xTickPattern, output); !reflect.DeepEqual(got, []string{"2024-03-01", "2024-03-03", "2024-03-05"}) {
+		t.Errorf("time bar categories %v", got)
+	}
+
+	// 分类按首次出现的顺序合并，相同的分类对齐
+	output = NewBarChart(
+		NewCategorySeries("a", []string{"乙", "甲"}, []float64{1, 2}),
+		NewCategorySeries("b", []string{"甲", "丙"}, []float64{3, 4}),
+	).Render()
+	if got := tickLabels(xTickPattern, output); !reflect.DeepEqual(got, []string{"乙", "甲", "丙"}) {
+		t.Errorf("category ticks %v, want [乙 甲 丙]", got)
+	}
+	if n := strings.Count(output, "st-chart-bar"); n != 4 {
+		t.Errorf("%d bars, want 4", n)
+	}
+
+	// 指定轴类型优先于系列推断
+	chart := NewLineChart(NewSeries("a", 1, 2, 3))
+	chart.SetXAxisKind(CategoryAxis)
+	if got := tickLabels(xTickPattern, chart.Render()); !reflect.DeepEqual(got, []string{"0", "1", "2"}) {
+		t.Errorf("forced category ticks %v", got)
+	}
+}
+
+// TestChartYRange 固定Y轴范围时刻度不随数据变化，超出范围的图形被裁剪，无效范围被忽略
+func TestChartYRange(t *testing.T) {
+	chart := NewLineChart(NewSeries("a", -5, 3, 20))
+	chart.SetYRange(0, 10)
+	output := chart.Render()
+	if got := tickLabels(yTickPattern, output); len(got) < 2 || got[0] != "0" || got[len(got)-1] != "10" {
+		t.Errorf("y ticks %v, want 0 to 10", got)
+	}
+	if !strings.Contains(output, `clip-path="url(#st-chart-clip-`+chart.GetID()+`)"`) || !strings.Contains(output, `<clipPath id="st-chart-clip-`+chart.GetID()+`">`) {
+		t.Errorf("fixed range does not clip the series: %s", output)
+	}
+	assertFinite(t, output)
+
+	before := output
+	for _, r := range [][2]float64{{5, 1}, {3, 3}, {math.NaN(), 1}, {0, math.NaN()}} {
+		chart.SetYRange(r[0], r[1])
+		if chart.Render() != before {
+			t.Errorf("invalid range %v changed the chart", r)
+		}
+	}
+
+	auto := NewLineChart(NewSeries("a", -5, 3, 20)).Render()
+	if strings.Contains(auto, "<clipPath") {
+		t.Error("automatic range clips the series")
+	}
+	if got := tickLabels(yTickPattern, auto); len(got) < 2 || got[0] == "0" || got[len(got)-1] == "10" {
+		t.Errorf("automatic y ticks %v do not cover the data", got)
+	}
+}
+
+// TestChartEscapesText 标题、系列名称、坐标轴标题和分类标签在SVG文本和属性中被转义
+func TestChartEscapesText(t *testing.T) {
+	hostile := `"><script>alert(1)</script>`
+	chart := NewLineChart(
+		NewCategorySeries(hostile, []string{hostile, "<b>"}, []float64{1, 2}),
+		Series{Name: "b", Color: `red" onload="alert(1)`, Points: []Point{{X: 0, Y: 1}}},
+	)
+	chart.SetTitle(hostile)
+	chart.SetXAxis(Axis{Title: hostile})
+	chart.SetYAxis(Axis{Title: hostile, Format: func(value float64) string { return "<i>" }})
+	output := chart.Render()
+
+	for _, injected := range []string{"<script", "<b>", "<i>", `onload="`} {
+		if strings.Contains(output, injected) {
+			t.Errorf("output contains %q: %s", injected, output)
+		}
+	}
+	escaped := html.EscapeString(hostile)
+	// 标题、图例、提示、两个坐标轴标题、分类刻度和无障碍名称
+	if n := strings.Count(output, escaped); n < 7 {
+		t.Errorf("escaped text appears %d times, want at least 7: %s", n, output)
+	}
+	if !strings.Contains(output, `aria-label="`+escaped+`"`) {
+		t.Errorf("aria label not escaped: %s", output)
+	}
+	if !strings.Contains(output, `fill="`+palette[1]+`"`) {
+		t.Errorf("invalid color not replaced by the palette: %s", output)
+	}
+}
@@ -0,0 +1,227 @@
+package charts
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tick 刻度，value是数据坐标
+type tick struct {
+	value float64
+	label string
+}
+
+// niceStep 把span分成约count段时的整齐步长（1、2、5乘以10的幂）
+func niceStep(span float64, count int) float64 {
+	if count < 1 {
+		count = 1
+	}
+	if span <= 0 || math.IsInf(span, 0) || math.IsNaN(span) {
+		return 1
+	}
+	raw := span / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch normalized := raw / magnitude; {
+	case normalized < 1.5:
+		return magnitude
+	case normalized < 3:
+		return 2 * magnitude
+	case normalized < 7:
+		return 5 * magnitude
+	default:
+		return 10 * magnitude
+	}
+}
+
+// niceDomain 把范围扩展到步长的整数倍
+func niceDomain(min, max float64, count int) (lo, hi, step float64) {
+	step = niceStep(max-min, count)
+	return math.Floor(min/step) * step, math.Ceil(max/step) * step, step
+}
+
+// numberTicks 生成[min, max]内步长为step整数倍的刻度
+func numberTicks(min, max, step float64, format func(float64) string) []tick {
+	ticks := make([]tick, 0)
+	first := math.Ceil(min/step - 1e-9)
+	for i := first; i*step <= max+step*1e-9 && len(ticks) < 100; i++ {
+		value := i * step
+		if math.Abs(value) < step*1e-9 {
+			value = 0
+		}
+		ticks = append(ticks, tick{value: value, label: format(value)})
+	}
+	return ticks
+}
+
+// numberFormatter 根据范围和步长创建刻度标签格式，大数使用k、M、B后缀，小数位数由步长决定
+func numberFormatter(lo, hi, step float64) func(float64) string {
+	divisor, suffix := 1.0, ""
+	switch largest := math.Max(math.Abs(lo), math.Abs(hi)); {
+	case largest >= 1e9:
+		divisor, suffix = 1e9, "B"
+	case largest >= 1e6:
+		divisor, suffix = 1e6, "M"
+	case largest >= 1e4:
+		divisor, suffix = 1e3, "k"
+	}
+	decimals := 0
+	if scaled := step / divisor; scaled < 1 {
+		decimals = min(int(math.Ceil(-math.Log10(scaled)-1e-9)), 10)
+	}
+	return func(value float64) string {
+		if value == 0 {
+			return "0"
+		}
+		return strconv.FormatFloat(value/divisor, 'f', decimals, 64) + suffix
+	}
+}
+
+// formatValue 提示中数值的格式，最多保留4位小数
+func formatValue(value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+	if abs := math.Abs(value); abs >= 1e15 || (abs < 1e-4 && abs != 0) {
+		return strconv.FormatFloat(value, 'g', 6, 64)
+	}
+	s := strconv.FormatFloat(value, 'f', 4, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// toTime 把Unix秒转换为本地时间
+func toTime(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9))
+}
+
+// formatTime 提示中时间的格式
+func formatTime(seconds float64) string {
+	t := toTime(seconds)
+	if t.Nanosecond() != 0 {
+		return t.Format("2006-01-02 15:04:05.000")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// 时间刻度的单位
+const (
+	unitSecond = iota
+	unitDay
+	unitMonth
+	unitYear
+)
+
+// timeInterval 时间刻度间隔
+type timeInterval struct {
+	unit   int
+	step   int
+	layout string
+	approx float64 // 间隔的近似秒数
+}
+
+// timeIntervals 候选的时间刻度间隔，按从小到大排列
+var timeIntervals = []timeInterval{
+	{unitSecond, 1, "15:04:05", 1},
+	{unitSecond, 5, "15:04:05", 5},
+	{unitSecond, 15, "15:04:05", 15},
+	{unitSecond, 30, "15:04:05", 30},
+	{unitSecond, 60, "15:04", 60},
+	{unitSecond, 5 * 60, "15:04", 5 * 60},
+	{unitSecond, 15 * 60, "15:04", 15 * 60},
+	{unitSecond, 30 * 60, "15:04", 30 * 60},
+	{unitSecond, 3600, "15:04", 3600},
+	{unitSecond, 3 * 3600, "15:04", 3 * 3600},
+	{unitSecond, 6 * 3600, "15:04", 6 * 3600},
+	{unitSecond, 12 * 3600, "15:04", 12 * 3600},
+	{unitDay, 1, "01-02", 86400},
+	{unitDay, 2, "01-02", 2 * 86400},
+	{unitDay, 7, "01-02", 7 * 86400},
+	{unitMonth, 1, "2006-01", 30 * 86400},
+	{unitMonth, 3, "2006-01", 91 * 86400},
+	{unitMonth, 6, "2006-01", 182 * 86400},
+	{unitYear, 1, "2006", 365 * 86400},
+}
+
+// timeTicks 生成[min, max]内约count个对齐到日历的时间刻度
+func timeTicks(min, max float64, count int) []tick {
+	span := max - min
+	interval := timeInterval{unitYear, int(math.Max(1, niceStep(span/(365*86400), count))), "2006", 0}
+	for _, candidate := range timeIntervals {
+		if span/candidate.approx <= float64(count) {
+			interval = candidate
+			break
+		}
+	}
+	if interval.unit == unitSecond && span > 86400 {
+		interval.layout = "01-02 15:04"
+	}
+
+	start := toTime(math.Floor(min))
+	var t time.Time
+	switch interval.unit {
+	case unitSecond:
+		// 从当天零点开始对齐，使小时刻度落在本地时间的整点
+		midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		step := time.Duration(interval.step) * time.Second
+		t = midnight.Add(start.Sub(midnight) / step * step)
+	case unitDay:
+		t = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	case unitMonth:
+		month := (int(start.Month())-1)/interval.step*interval.step + 1
+		t = time.Date(start.Year(), time.Month(month), 1, 0, 0, 0, 0, start.Location())
+	case unitYear:
+		t = time.Date(start.Year()/interval.step*interval.step, 1, 1, 0, 0, 0, 0, start.Location())
+	}
+
+	ticks := make([]tick, 0)
+	for i := 0; i < 1000; i++ {
+		value := float64(t.UnixNano()) / 1e9
+		if value > max {
+			break
+		}
+		if value >= min {
+			ticks = append(ticks, tick{value: value, label: t.Format(interval.layout)})
+		}
+		switch interval.unit {
+		case unitSecond:
+			t = t.Add(time.Duration(interval.step) * time.Second)
+		case unitDay:
+			t = t.AddDate(0, 0, interval.step)
+		case unitMonth:
+			t = t.AddDate(0, interval.step, 0)
+		case unitYear:
+			t = t.AddDate(interval.step, 0, 0)
+		}
+	}
+	return ticks
+}
+
+// textWidth 估算文本在fontSize字号下的宽度，全角字符按一个字号计算
+func textWidth(text string) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 0x1100 {
+			width += fontSize * 0.6
+		} else {
+			width += fontSize
+		}
+	}
+	return width
+}
+
+// truncate 截断过长的标签
+func truncate(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes-1]) + "…"
+}
@@ -0,0 +1,279 @@
+package charts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// VegaSelection Vega-Lite选择参数的当前值
+type VegaSelection struct {
+	Param string `json:"param"`
+	// Points point选择选中的数据，只包含选择参数fields中的字段，未指定fields时包含全部字段
+	Points []map[string]interface{} `json:"points,omitempty"`
+	// Ranges interval选择每个字段的范围：连续字段为[最小值, 最大值]（时间为RFC 3339字符串），离散字段为选中的值
+	Ranges map[string][]interface{} `json:"ranges,omitempty"`
+}
+
+// Empty 判断选择是否为空
+func (s VegaSelection) Empty() bool {
+	return len(s.Points) == 0 && len(s.Ranges) == 0
+}
+
+// VegaChart 使用Vega-Lite规范描述的图表组件
+//
+// 规范在浏览器中由vega-embed使用Vega和Vega-Lite绘制，脚本随程序编译，由服务通过/static/vega/提供，不需要访问外部网络。
+// 点击数据和选择参数的变化通过事件发送到服务端，可以用OnClick和OnSelect处理。
+type VegaChart struct {
+	*widgets.BaseWidget
+	spec           map[string]interface{} // 只整体替换，不原地修改，副本之间可以共享
+	selections     map[string]VegaSelection
+	clicked        map[string]interface{}
+	selected       *VegaSelection // 本次事件绑定的选择，触发回调后清空
+	clickHandlers  []func(session widgets.ISession, datum map[string]interface{})
+	selectHandlers []func(session widgets.ISession, selection VegaSelection)
+}
+
+// NewVegaChart 创建Vega-Lite图表
+//
+// spec可以是JSON字符串、[]byte、map或可以编码为JSON的结构体，必须是对象。
+// 数据可以写在规范的data.values中，也可以用SetData从Go的表格数据设置。
+func NewVegaChart(spec interface{}) (*VegaChart, error) {
+	chart := &VegaChart{
+		BaseWidget: widgets.NewBaseWidget("vega_chart"),
+		selections: make(map[string]VegaSelection),
+	}
+	if err := chart.SetSpec(spec); err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+// SetSpec 替换图表的规范
+//
+// 规范必须是JSON对象，否则返回错误，不修改图表。规范的内容不在服务端检查，由浏览器中的Vega-Lite编译，
+// 编译失败时图表位置显示错误信息。
+func (v *VegaChart) SetSpec(spec interface{}) error {
+	var data []byte
+	switch s := spec.(type) {
+	case string:
+		data = []byte(s)
+	case []byte:
+		data = s
+	case json.RawMessage:
+		data = s
+	default:
+		encoded, err := json.Marshal(spec)
+		if err != nil {
+			return fmt.Errorf("charts: invalid vega-lite spec: %w", err)
+		}
+		data = encoded
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("charts: invalid vega-lite spec: %w", err)
+	}
+	if parsed == nil {
+		return errors.New("charts: vega-lite spec must be a JSON object")
+	}
+	v.spec = parsed
+	return nil
+}
+
+// SetData 把表格数据设置为规范的data.values，替换规范中已有的数据
+//
+// data可以是结构体切片（元素可以是结构体指针）或[]map[string]interface{}，列名规则与Chart.SetData相同。
+// time.Time转换为RFC 3339字符串，在规范中使用temporal类型；NaN和nil转换为null。
+func (v *VegaChart) SetData(data interface{}) error {
+	values, err := vegaValues(data)
+	if err != nil {
+		return err
+	}
+	spec := maps.Clone(v.spec)
+	spec["data"] = map[string]interface{}{"values": values}
+	v.spec = spec
+	return nil
+}
+
+// SetDataset 把表格数据设置为规范datasets中的命名数据集，规范或图层通过{"data": {"name": name}}引用
+func (v *VegaChart) SetDataset(name string, data interface{}) error {
+	values, err := vegaValues(data)
+	if err != nil {
+		return err
+	}
+	datasets, _ := v.spec["datasets"].(map[string]interface{})
+	datasets = maps.Clone(datasets)
+	if datasets == nil {
+		datasets = make(map[string]interface{})
+	}
+	datasets[name] = values
+
+	spec := maps.Clone(v.spec)
+	spec["datasets"] = datasets
+	v.spec = spec
+	return nil
+}
+
+// OnClick 设置点击数据的回调函数，datum是被点击的图形对应的数据（聚合后的字段名与Vega-Lite相同，如sum_price）
+func (v *VegaChart) OnClick(callback func(session widgets.ISession, datum map[string]interface{})) {
+	v.clickHandlers = append(v.clickHandlers, callback)
+}
+
+// OnSelect 设置选择变化的回调函数，选择被清除时selection为空
+//
+// 只有规范的params中定义了point或interval选择时才会触发，鼠标悬停触发的选择不发送到服务端。
+func (v *VegaChart) OnSelect(callback func(session widgets.ISession, selection VegaSelection)) {
+	v.selectHandlers = append(v.selectHandlers, callback)
+}
+
+// GetSelection 获取选择参数的当前值，选择为空时返回false
+func (v *VegaChart) GetSelection(param string) (VegaSelection, bool) {
+	selection, exists := v.selections[param]
+	if !exists || selection.Empty() {
+		return VegaSelection{Param: param}, false
+	}
+	return selection, true
+}
+
+// GetClicked 获取最近一次点击的数据，没有点击时返回nil
+func (v *VegaChart) GetClicked() map[string]interface{} {
+	return v.clicked
+}
+
+// BindValue 保存客户端发送的点击数据或选择
+func (v *VegaChart) BindValue(session widgets.ISession, event string, value string) error {
+	switch event {
+	case "click":
+		var datum map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &datum); err != nil {
+			return fmt.Errorf("vega chart %s: invalid click: %w", v.GetID(), err)
+		}
+		v.clicked = datum
+	case "select":
+		var selection VegaSelection
+		if err := json.Unmarshal([]byte(value), &selection); err != nil {
+			return fmt.Errorf("vega chart %s: invalid selection: %w", v.GetID(), err)
+		}
+		if selection.Param == "" {
+			return fmt.Errorf("vega chart %s: selection without param", v.GetID())
+		}
+		selections := maps.Clone(v.selections)
+		if selections == nil {
+			selections = make(map[string]VegaSelection)
+		}
+		selections[selection.Param] = selection
+		v.selections = selections
+		v.selected = &selection
+	}
+	return nil
+}
+
+// TriggerCallbacks 调用点击或选择回调，再触发组件自身的回调
+func (v *VegaChart) TriggerCallbacks(session widgets.ISession, event string, value string) {
+	switch event {
+	case "click":
+		for _, handler := range v.clickHandlers {
+			handler(session, v.clicked)
+		}
+	case "select":
+		if v.selected != nil {
+			selection := *v.selected
+			v.selected = nil
+			for _, handler := range v.selectHandlers {
+				handler(session, selection)
+			}
+		}
+	}
+	v.BaseWidget.TriggerCallbacks(session, event, value)
+}
+
+// Render 渲染图表容器，规范和当前选择在data属性中，由客户端脚本绘制
+func (v *VegaChart) Render() string {
+	spec, err := json.Marshal(v.spec)
+	if err != nil {
+		return widgets.HTMLf("<div class=\"st-vega st-vega-error\" data-widget-id=\"%s\">%s</div>", v.GetID(), err.Error()).String()
+	}
+
+	var selectionAttr widgets.SafeHTML
+	if len(v.selections) > 0 {
+		names := make([]string, 0, len(v.selections))
+		for name := range v.selections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		selections := make([]VegaSelection, len(names))
+		for i, name := range names {
+			selections[i] = v.selections[name]
+		}
+		if data, err := json.Marshal(selections); err == nil {
+			selectionAttr = widgets.HTMLf(" data-selection=\"%s\"", string(data))
+		}
+	}
+
+	return widgets.HTMLf("<div class=\"st-vega\" data-widget-id=\"%s\" data-spec=\"%s\"%s></div>",
+		v.GetID(), string(spec), selectionAttr).String()
+}
+
+// Clone 复制图表，副本与原图表共享规范和回调函数
+func (v *VegaChart) Clone() widgets.Widget {
+	clone := *v
+	clone.BaseWidget = v.BaseWidget.CloneBase()
+	clone.selections = maps.Clone(v.selections)
+	clone.selected = nil
+	return &clone
+}
+
+// vegaValues 把表格数据转换为可以编码为JSON的data.values
+func vegaValues(data interface{}) ([]map[string]interface{}, error) {
+	t, err := readTable(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]map[string]interface{}, len(t.rows))
+	for i, row := range t.rows {
+		record := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			record[column] = vegaValue(value)
+		}
+		values[i] = record
+	}
+	if _, err := json.Marshal(values); err != nil {
+		return nil, fmt.Errorf("charts: %w", err)
+	}
+	return values, nil
+}
+
+// vegaValue 转换单个值：time.Time转换为RFC 3339字符串，NaN、无穷大和nil指针转换为nil
+func vegaValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			return nil
+		}
+	}
+	return v.Interface()
+}
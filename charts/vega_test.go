@@ -0,0 +1,53 @@
+package charts
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"testing"
+)
+
+// TestVegaSpecPassedThrough 任何JSON对象都原样交给浏览器中的Vega-Lite编译
+func TestVegaSpecPassedThrough(t *testing.T) {
+	specs := []string{
+		`{"mark": "bar", "transform": [{"calculate": "datum.a * 2", "as": "b"}]}`,
+		`{"facet": {"field": "r"}, "spec": {"mark": "boxplot", "transform": [{"fold": ["a", "b"]}]}}`,
+		`{"data": {"url": "data.csv"}, "mark": "point", "encoding": {"y": {"field": "a", "scale": {"type": "log"}}}}`,
+		`{"mark": "point", "params": [{"name": "p", "select": "point", "bind": {"input": "range"}}]}`,
+	}
+	for _, spec := range specs {
+		chart, err := NewVegaChart(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		attr := strings.SplitN(chart.Render(), `data-spec="`, 2)
+		if len(attr) != 2 {
+			t.Fatalf("%s: no data-spec in %s", spec, chart.Render())
+		}
+		var got, want map[string]interface{}
+		json.Unmarshal([]byte(html.UnescapeString(strings.SplitN(attr[1], `"`, 2)[0])), &got)
+		json.Unmarshal([]byte(spec), &want)
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("rendered spec %s, want %s", gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestVegaSpecInvalid(t *testing.T) {
+	chart, err := NewVegaChart(`{"mark": "bar"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := chart.Render()
+	for _, spec := range []interface{}{`[]`, `null`, `"bar"`, `{"mark":`, []int{1}} {
+		if err := chart.SetSpec(spec); err == nil {
+			t.Errorf("%v: expected error", spec)
+		}
+	}
+	if chart.Render() != before {
+		t.Errorf("invalid spec modified the chart: %s", chart.Render())
+	}
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// appState 单个会话的脚本运行状态
+type appState struct {
+	mutex   sync.Mutex
+	widgets map[string]widgets.Widget // 跨运行保留的有状态组件，组件ID -> 组件
+	sources map[string]interface{}    // 媒体组件的来源，组件ID -> 用于判断来源是否变化的值
+	roots   []widgets.Widget          // 最近一次运行生成的组件树
+}
+
+// appRun 单次运行的记录，由同一次运行中的所有Context共享
+type appRun struct {
+	state   *appState
+	session *state.Session
+	counts  map[string]int            // 组件标识 -> 出现次数
+	keep    map[string]widgets.Widget // 本次运行使用的有状态组件
+	sources map[string]interface{}    // 本次运行中媒体组件的来源
+	trigger string                    // 触发本次运行的组件ID
+	roots   []widgets.Widget
+}
+
+// appStates 所有会话的脚本运行状态
+type appStates struct {
+	states map[string]*appState
+	mutex  sync.Mutex
+}
+
+// newAppStates 创建脚本运行状态表
+func newAppStates() *appStates {
+	return &appStates{
+		states: make(map[string]*appState),
+	}
+}
+
+// get 获取会话的运行状态，不存在时创建
+func (a *appStates) get(sessionID string) *appState {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	st, exists := a.states[sessionID]
+	if !exists {
+		st = &appState{widgets: make(map[string]widgets.Widget)}
+		a.states[sessionID] = st
+	}
+	return st
+}
+
+// delete 删除会话的运行状态
+func (a *appStates) delete(sessionID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.states, sessionID)
+}
+
+// rename 会话ID更换后迁移运行状态
+func (a *appStates) rename(oldID, newID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if st, exists := a.states[oldID]; exists {
+		delete(a.states, oldID)
+		a.states[newID] = st
+	}
+}
+
+// SetApp 设置脚本式应用函数
+//
+// 每次页面加载、组件事件和服务端推送时，应用函数都会为对应会话从头到尾重新运行一次，
+// 本次运行中调用的组件方法构成该会话的组件树。应用组件渲染在全局组件之后、会话组件之前。
+// 应用函数中不能调用Rerun等会触发当前会话重新渲染的方法。
+func (s *Service) SetApp(app func(st *Context)) {
+	s.appMutex.Lock()
+	defer s.appMutex.Unlock()
+	s.app = app
+}
+
+// RunApp 设置脚本式应用函数并启动服务，阻塞直到服务停止
+//
+//	service.RunApp(func(st *core.Context) {
+//		name := st.TextInput("姓名", "")
+//		if st.Button("问候") {
+//			st.Text("你好, " + name)
+//		}
+//	})
+func (s *Service) RunApp(app func(st *Context)) error {
+	s.SetApp(app)
+	return s.Start()
+}
+
+// getApp 获取应用函数
+func (s *Service) getApp() func(st *Context) {
+	s.appMutex.RLock()
+	defer s.appMutex.RUnlock()
+	return s.app
+}
+
+// runApp 为会话运行一次应用函数，返回生成的组件树；未设置应用函数时返回nil
+//
+// trigger是触发本次运行的应用组件ID，只传给由该组件的事件引起的这一次运行。
+func (s *Service) runApp(session *state.Session, trigger string) []widgets.Widget {
+	app := s.getApp()
+	if app == nil {
+		return nil
+	}
+
+	st := s.appStates.get(session.ID())
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	run := &appRun{
+		state:   st,
+		session: session,
+		counts:  make(map[string]int),
+		keep:    make(map[string]widgets.Widget),
+		sources: make(map[string]interface{}),
+		trigger: trigger,
+	}
+
+	root := &Context{run: run}
+	root.add = func(widget widgets.Widget) {
+		run.roots = append(run.roots, widget)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("App panic in session %s: %v\n%s", session.ID(), r, debug.Stack())
+				root.add(widgets.NewText(fmt.Sprintf("应用运行错误: %v", r)))
+			}
+		}()
+		app(root)
+	}()
+
+	// 本次运行未调用的组件不再保留状态
+	st.widgets = run.keep
+	st.sources = run.sources
+	st.roots = run.roots
+	return run.roots
+}
+
+// findAppWidget 在会话最近一次运行生成的组件树中查找组件
+func (s *Service) findAppWidget(session *state.Session, componentID string) widgets.Widget {
+	if s.getApp() == nil {
+		return nil
+	}
+
+	st := s.appStates.get(session.ID())
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return widgets.FindWidget(st.roots, componentID)
+}
+
+// widgetID 根据调用位置生成稳定的组件ID
+//
+// 指定了Key的组件由父组件ID和Key生成ID，同一父组件中的Key不能重复，重复时中止本次运行并显示错误。
+// 其余组件的标识由父组件ID、组件类型以及标签（有状态组件）组成，标识相同的组件按出现顺序编号。
+// 显示类组件只使用类型和顺序，内容变化时ID保持不变，从而可以局部更新。
+func (r *appRun) widgetID(parentID, widgetType, label, key string, stateful bool) string {
+	if key != "" {
+		id := widgets.KeyID(parentID, key)
+		if r.counts[id] > 0 {
+			panic(fmt.Errorf("%w: %q", widgets.ErrDuplicateKey, key))
+		}
+		r.counts[id]++
+		return id
+	}
+
+	identity := parentID + "/" + widgetType
+	if stateful {
+		identity += ":" + label
+	}
+
+	n := r.counts[identity]
+	r.counts[identity] = n + 1
+
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s#%d", identity, n)))
+	return fmt.Sprintf("st-%x", h.Sum64())
+}
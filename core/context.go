@@ -0,0 +1,435 @@
+package core
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/lengzhao/streamlit-go/charts"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// Context 脚本式应用的运行上下文
+//
+// 应用函数通过Context声明组件，输入类方法直接返回该组件在当前会话中的值。
+// 布局方法返回子Context，在子Context上声明的组件放入对应的容器。
+type Context struct {
+	run      *appRun
+	parentID string
+	formID   string // 所在表单的ID，不在表单中时为空
+	key      string
+	add      func(widget widgets.Widget)
+}
+
+// Session 获取当前会话
+func (c *Context) Session() widgets.ISession {
+	return c.run.session
+}
+
+// Key 为下一个组件指定Key，组件ID由Key而不是调用位置决定
+//
+//	st.Key("submit").Button("提交")
+func (c *Context) Key(key string) *Context {
+	keyed := *c
+	keyed.key = key
+	return &keyed
+}
+
+// nextID 为下一个组件生成ID
+func (c *Context) nextID(widgetType, label string, stateful bool) string {
+	return c.run.widgetID(c.parentID, widgetType, label, c.key, stateful)
+}
+
+// place 设置组件ID并放入当前容器
+//
+// 应用组件的ID已经由Key生成，不再调用SetKey，避免放入容器时按容器作用域重新生成ID。
+func place[T widgets.Widget](c *Context, id string, widget T) T {
+	widget.SetID(id)
+	c.add(widget)
+	return widget
+}
+
+// keep 获取跨运行保留的有状态组件，不存在或类型不同时调用create创建
+//
+// update不为nil时在每次运行中调用，把本次运行传入的选项、范围等参数应用到组件，
+// 组件据此调整或丢弃不再有效的当前值。
+func keep[T widgets.Widget](c *Context, id string, create func() T, update func(widget T)) T {
+	widget, ok := c.run.state.widgets[id].(T)
+	if !ok {
+		widget = create()
+		widget.SetID(id)
+	}
+	if update != nil {
+		update(widget)
+	}
+	c.run.keep[id] = widget
+	c.add(widget)
+	return widget
+}
+
+// keepMedia 获取跨运行保留的媒体组件，source与上次运行相同时不重新读取和编码
+func keepMedia[T widgets.Widget](c *Context, widgetType string, source interface{}, create func(source interface{}) (T, error)) T {
+	id := c.nextID(widgetType, "", false)
+	current := mediaSource(source)
+	previous, exists := c.run.state.sources[id]
+	c.run.sources[id] = current
+	if !exists || current == nil || previous != current {
+		delete(c.run.state.widgets, id)
+	}
+	return keep(c, id, func() T {
+		widget, err := create(source)
+		if err != nil {
+			panic(err)
+		}
+		return widget
+	}, nil)
+}
+
+// mediaSource 获取用于判断媒体来源是否变化的值，返回nil表示无法判断
+//
+// 字节内容比较哈希，文件路径比较路径、大小和修改时间，其他来源比较是否为同一个值（如同一个指针）。
+func mediaSource(source interface{}) interface{} {
+	switch s := source.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return sha256.Sum256(s)
+	case string:
+		info, err := os.Stat(s)
+		if err != nil {
+			return nil
+		}
+		return fmt.Sprintf("%s\x00%d\x00%d", s, info.Size(), info.ModTime().UnixNano())
+	}
+	if !reflect.TypeOf(source).Comparable() {
+		return nil
+	}
+	return source
+}
+
+// Title 显示标题
+func (c *Context) Title(text string) *widgets.TitleWidget {
+	return place(c, c.nextID("title", text, false), widgets.NewTitle(text))
+}
+
+// Header 显示二级标题
+func (c *Context) Header(text string, divider ...bool) *widgets.HeaderWidget {
+	return place(c, c.nextID("header", text, false), widgets.NewHeader(text, divider...))
+}
+
+// Subheader 显示三级标题
+func (c *Context) Subheader(text string) *widgets.SubheaderWidget {
+	return place(c, c.nextID("subheader", text, false), widgets.NewSubheader(text))
+}
+
+// Text 显示文本
+func (c *Context) Text(text string) *widgets.TextWidget {
+	return place(c, c.nextID("text", text, false), widgets.NewText(text))
+}
+
+// Markdown 显示Markdown文本，输出的HTML经过过滤，可以直接显示用户提交的内容
+func (c *Context) Markdown(text string) *widgets.MarkdownWidget {
+	return place(c, c.nextID("markdown", "", false), widgets.NewMarkdown(text))
+}
+
+// Caption 以较小的灰色字体显示说明文字，支持Markdown
+func (c *Context) Caption(text string) *widgets.CaptionWidget {
+	return place(c, c.nextID("caption", "", false), widgets.NewCaption(text))
+}
+
+// HTML 原样显示可信的HTML，内容不经转义
+func (c *Context) HTML(content widgets.SafeHTML) *widgets.HTMLWidget {
+	return place(c, c.nextID("html", "", false), widgets.NewHTML(content))
+}
+
+// Write 显示任意数据
+func (c *Context) Write(data interface{}) *widgets.WriteWidget {
+	return place(c, c.nextID("write", "", false), widgets.NewWrite(data))
+}
+
+// Metric 显示指标
+func (c *Context) Metric(label string, value interface{}, delta ...string) *widgets.MetricWidget {
+	metric := widgets.NewMetric(label, value)
+	if len(delta) > 0 {
+		metric.SetDelta(delta[0])
+	}
+	return place(c, c.nextID("metric", label, false), metric)
+}
+
+// Table 显示表格
+func (c *Context) Table(data interface{}) *widgets.TableWidget {
+	return place(c, c.nextID("table", "", false), widgets.NewTable(data))
+}
+
+// DataFrame 显示数据框，排序、搜索、筛选、翻页和选中的行跨运行保留
+func (c *Context) DataFrame(data interface{}) *widgets.DataFrameWidget {
+	id := c.nextID("dataframe", "", true)
+	return keep(c, id, func() *widgets.DataFrameWidget {
+		return widgets.NewDataFrame(nil)
+	}, func(frame *widgets.DataFrameWidget) {
+		frame.SetData(data)
+	})
+}
+
+// Image 显示图片，source可以是image.Image、[]byte、文件路径或io.ReadSeeker，无效时本次运行中止并显示错误
+func (c *Context) Image(source interface{}) *widgets.ImageWidget {
+	return keepMedia(c, "image", source, widgets.NewImage)
+}
+
+// Audio 显示音频播放器，source可以是[]byte、文件路径或io.ReadSeeker，无效时本次运行中止并显示错误
+func (c *Context) Audio(source interface{}) *widgets.AudioWidget {
+	return keepMedia(c, "audio", source, widgets.NewAudio)
+}
+
+// Video 显示视频播放器，source可以是[]byte、文件路径或io.ReadSeeker，无效时本次运行中止并显示错误
+func (c *Context) Video(source interface{}) *widgets.VideoWidget {
+	return keepMedia(c, "video", source, widgets.NewVideo)
+}
+
+// LineChart 显示折线图
+func (c *Context) LineChart(series ...charts.Series) *charts.Chart {
+	return place(c, c.nextID("line_chart", "", false), charts.NewLineChart(series...))
+}
+
+// BarChart 显示柱状图
+func (c *Context) BarChart(series ...charts.Series) *charts.Chart {
+	return place(c, c.nextID("bar_chart", "", false), charts.NewBarChart(series...))
+}
+
+// AreaChart 显示面积图
+func (c *Context) AreaChart(series ...charts.Series) *charts.Chart {
+	return place(c, c.nextID("area_chart", "", false), charts.NewAreaChart(series...))
+}
+
+// ScatterChart 显示散点图
+func (c *Context) ScatterChart(series ...charts.Series) *charts.Chart {
+	return place(c, c.nextID("scatter_chart", "", false), charts.NewScatterChart(series...))
+}
+
+// VegaChart 显示Vega-Lite规范描述的图表，spec无效时本次运行中止并显示错误
+//
+// 图表在运行之间保留，点击数据或改变选择时触发下一次运行，可以通过GetClicked和GetSelection读取：
+//
+//	chart := st.VegaChart(spec)
+//	if selection, ok := chart.GetSelection("brush"); ok { ... }
+func (c *Context) VegaChart(spec interface{}) *charts.VegaChart {
+	id := c.nextID("vega_chart", "", true)
+	return keep(c, id, func() *charts.VegaChart {
+		chart, err := charts.NewVegaChart(spec)
+		if err != nil {
+			panic(err)
+		}
+		return chart
+	}, func(chart *charts.VegaChart) {
+		if err := chart.SetSpec(spec); err != nil {
+			panic(err)
+		}
+	})
+}
+
+// Button 显示按钮，本次运行由该按钮的点击触发时返回true
+func (c *Context) Button(label string) bool {
+	id := c.nextID("button", label, true)
+	place(c, id, widgets.NewButton(label))
+	return c.run.trigger == id
+}
+
+// TextInput 显示文本输入框，返回当前会话输入的值，首次运行时为value
+func (c *Context) TextInput(label string, value string) string {
+	id := c.nextID("text_input", label, true)
+	return keep(c, id, func() *widgets.TextInputWidget {
+		return widgets.NewTextInput(label, value)
+	}, nil).GetValue()
+}
+
+// NumberInput 显示数字输入框，返回当前会话输入的值，首次运行时为value
+func (c *Context) NumberInput(label string, value float64) float64 {
+	id := c.nextID("number_input", label, true)
+	return keep(c, id, func() *widgets.NumberInputWidget {
+		return widgets.NewNumberInput(label, value)
+	}, nil).GetValue()
+}
+
+// Checkbox 显示复选框，返回当前会话的选中状态，首次运行时为value
+func (c *Context) Checkbox(label string, value bool) bool {
+	id := c.nextID("checkbox", label, true)
+	return keep(c, id, func() *widgets.CheckboxWidget {
+		return widgets.NewCheckbox(label, value)
+	}, nil).GetValue()
+}
+
+// Toggle 显示开关，返回当前会话的开关状态，首次运行时为value
+func (c *Context) Toggle(label string, value bool) bool {
+	id := c.nextID("toggle", label, true)
+	return keep(c, id, func() *widgets.ToggleWidget {
+		return widgets.NewToggle(label, value)
+	}, nil).GetValue()
+}
+
+// Radio 在脚本式应用中显示单选组件，返回当前选中的选项，未选中时返回T的零值和false
+//
+// Context的方法不能带类型参数，选择类组件以函数形式提供：
+//
+//	size, _ := core.Radio(st, "尺寸", []string{"S", "M", "L"}, 1)
+func Radio[T any](c *Context, label string, options []T, index int) (T, bool) {
+	id := c.nextID("radio", label, true)
+	return keep(c, id, func() *widgets.RadioWidget[T] {
+		return widgets.NewRadio(label, options, index)
+	}, func(radio *widgets.RadioWidget[T]) {
+		radio.SetOptions(options)
+	}).GetValue()
+}
+
+// Selectbox 在脚本式应用中显示下拉选择组件，返回当前选中的选项，未选中时返回T的零值和false
+func Selectbox[T any](c *Context, label string, options []T, index int) (T, bool) {
+	id := c.nextID("selectbox", label, true)
+	return keep(c, id, func() *widgets.SelectboxWidget[T] {
+		return widgets.NewSelectbox(label, options, index)
+	}, func(selectbox *widgets.SelectboxWidget[T]) {
+		selectbox.SetOptions(options)
+	}).GetValue()
+}
+
+// Multiselect 在脚本式应用中显示多选组件，返回当前选中的选项
+func Multiselect[T any](c *Context, label string, options []T, indices ...int) []T {
+	id := c.nextID("multiselect", label, true)
+	return keep(c, id, func() *widgets.MultiselectWidget[T] {
+		return widgets.NewMultiselect(label, options, indices...)
+	}, func(multiselect *widgets.MultiselectWidget[T]) {
+		multiselect.SetOptions(options)
+	}).GetValue()
+}
+
+// Slider 在脚本式应用中显示数值滑块，返回当前的值，首次运行时为value
+func Slider[T widgets.Number](c *Context, label string, min, max, value T) T {
+	id := c.nextID("slider", label, true)
+	return keep(c, id, func() *widgets.SliderWidget[T] {
+		return widgets.NewSlider(label, min, max, value)
+	}, func(slider *widgets.SliderWidget[T]) {
+		slider.SetScale(widgets.NewNumberScale(min, max, 0))
+	}).GetValue()
+}
+
+// RangeSlider 在脚本式应用中显示数值范围滑块，返回当前范围的两端
+func RangeSlider[T widgets.Number](c *Context, label string, min, max, low, high T) (T, T) {
+	id := c.nextID("range_slider", label, true)
+	return keep(c, id, func() *widgets.RangeSliderWidget[T] {
+		return widgets.NewRangeSlider(label, min, max, low, high)
+	}, func(slider *widgets.RangeSliderWidget[T]) {
+		slider.SetScale(widgets.NewNumberScale(min, max, 0))
+	}).GetValue()
+}
+
+// child 创建向指定容器添加组件的子Context
+func (c *Context) child(parentID string, add func(widget widgets.Widget)) *Context {
+	return &Context{run: c.run, parentID: parentID, formID: c.formID, add: add}
+}
+
+// Container 显示容器，返回向容器中添加组件的子Context
+func (c *Context) Container(border bool) *Context {
+	container := place(c, c.nextID("container", "", false), widgets.NewContainer(border))
+	return c.child(container.GetID(), container.AddChild)
+}
+
+// Columns 显示列布局，返回每一列的子Context，ratios为各列宽度比例，默认两列等宽
+func (c *Context) Columns(ratios ...int) []*Context {
+	columns := place(c, c.nextID("columns", "", false), widgets.NewColumns(ratios...))
+
+	contexts := make([]*Context, 0, len(columns.GetColumns()))
+	for i, column := range columns.GetColumns() {
+		column.SetID(c.run.widgetID(columns.GetID(), "column", "", "", false))
+		contexts = append(contexts, c.child(column.GetID(), columns.GetColumns()[i].AddChild))
+	}
+	return contexts
+}
+
+// Sidebar 显示侧边栏，返回向侧边栏中添加组件的子Context
+func (c *Context) Sidebar() *Context {
+	sidebar := place(c, c.nextID("sidebar", "", false), widgets.NewSidebar(true))
+	return c.child(sidebar.GetID(), sidebar.AddChild)
+}
+
+// Expander 显示可展开组件，返回向其中添加组件的子Context
+func (c *Context) Expander(label string, expanded bool) *Context {
+	expander := place(c, c.nextID("expander", label, true), widgets.NewExpander(label, expanded))
+	return c.child(expander.GetID(), expander.AddChild)
+}
+
+// Form 显示表单，返回向表单中添加组件的子Context
+//
+// 表单中的输入组件只在点击FormSubmitButton时一次提交，输入过程中不会触发重新运行。
+func (c *Context) Form() *Context {
+	form := place(c, c.nextID("form", "", false), widgets.NewForm(true))
+	child := c.child(form.GetID(), form.AddChild)
+	child.formID = form.GetID()
+	return child
+}
+
+// FormSubmitButton 显示表单提交按钮，本次运行由所在表单的提交触发时返回true
+//
+// 只能在Form返回的Context（或其中的布局）中调用。
+func (c *Context) FormSubmitButton(label string) bool {
+	if c.formID == "" {
+		panic(errors.New("FormSubmitButton must be called inside a form"))
+	}
+	id := c.nextID("form_submit_button", label, true)
+	place(c, id, widgets.NewFormSubmitButton(label))
+	return c.run.trigger == c.formID
+}
+
+// StructForm 显示根据结构体生成的表单，本次运行由该表单提交且校验通过、值已写回结构体时返回true
+//
+// target为结构体指针，字段映射规则见widgets.NewStructForm。表单在首次运行时创建并跨运行保留，
+// 多次运行应传入同一个指针。target无效时本次运行中止并显示错误。
+func (c *Context) StructForm(target interface{}) bool {
+	id := c.nextID("struct_form", fmt.Sprintf("%T", target), true)
+	form := keep(c, id, func() *widgets.StructFormWidget {
+		form, err := widgets.NewStructForm(target)
+		if err != nil {
+			panic(err)
+		}
+		return form
+	}, nil)
+	return c.run.trigger == id && form.GetError() == ""
+}
+
+// FileUploader 显示文件上传组件，返回当前会话最近一次上传的文件，没有上传时返回nil
+//
+// multiple为true时允许一次选择多个文件，extensions限制允许的扩展名，例如 ".csv"。
+// 上传完成后应用重新运行，返回的文件在会话过期或再次上传前有效。
+func (c *Context) FileUploader(label string, multiple bool, extensions ...string) []*widgets.UploadedFile {
+	id := c.nextID("file_uploader", label, true)
+	return keep(c, id, func() *widgets.FileUploaderWidget {
+		return widgets.NewFileUploader(label, multiple)
+	}, func(uploader *widgets.FileUploaderWidget) {
+		uploader.SetMultiple(multiple)
+		uploader.SetExtensions(extensions...)
+	}).GetFiles()
+}
+
+// DownloadButton 显示下载按钮，点击后浏览器下载data，本次运行由该按钮点击触发时返回true
+func (c *Context) DownloadButton(label, fileName string, data []byte) bool {
+	id := c.nextID("download_button", label, true)
+	keep(c, id, func() *widgets.DownloadButtonWidget {
+		return widgets.NewDownloadButton(label, fileName, data)
+	}, func(button *widgets.DownloadButtonWidget) {
+		button.SetData(data)
+	})
+	return c.run.trigger == id
+}
+
+// DownloadButtonFunc 显示下载按钮，浏览器请求下载时调用generate生成内容，本次运行由该按钮点击触发时返回true
+//
+// 内容直接写入HTTP响应，适合导出较大的报表，generate使用的是最近一次运行传入的函数。
+func (c *Context) DownloadButtonFunc(label, fileName string, generate func(session widgets.ISession, w io.Writer) error) bool {
+	id := c.nextID("download_button", label, true)
+	keep(c, id, func() *widgets.DownloadButtonWidget {
+		return widgets.NewDownloadButtonFunc(label, fileName, generate)
+	}, func(button *widgets.DownloadButtonWidget) {
+		button.SetGenerateFunc(generate)
+	})
+	return c.run.trigger == id
+}
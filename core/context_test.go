@@ -0,0 +1,178 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// appWidget 获取会话最近一次运行保留的组件
+func appWidget(t *testing.T, s *Service, sessionID string, widgetType string) widgets.Widget {
+	t.Helper()
+	id := appWidgetID(t, s, sessionID, widgetType)
+	st := s.appStates.get(sessionID)
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	return st.widgets[id]
+}
+
+// TestAppRerunAppliesChangedOptions 重新运行时应用本次传入的选项和范围，调整或丢弃不再有效的当前值
+func TestAppRerunAppliesChangedOptions(t *testing.T) {
+	service := NewService()
+	options := []string{"a", "b", "c"}
+	max := 100
+	multiple := true
+	extensions := []string{".csv", ".tsv"}
+
+	var choice string
+	var chosen bool
+	var picked []string
+	var level, low, high int
+	var files []*widgets.UploadedFile
+	service.SetApp(func(st *Context) {
+		choice, chosen = Selectbox(st, "选项", options, 0)
+		picked = Multiselect(st, "多选", options, 0)
+		level = Slider(st, "级别", 0, max, 50)
+		low, high = RangeSlider(st, "范围", 0, max, 10, 90)
+		files = st.FileUploader("文件", multiple, extensions...)
+	})
+
+	session := service.GetStateManager().GetSession("options-test")
+	sessionID := session.ID()
+	service.RenderWidgetsForPage(sessionID)
+
+	events := map[string]string{"selectbox": "2", "multiselect": "0,1,2", "slider": "80", "range_slider": "20,95"}
+	for widgetType, value := range events {
+		service.handleEvent(session, appWidgetID(t, service, sessionID, widgetType), "change", value)
+	}
+	uploader := appWidget(t, service, sessionID, "file_uploader").(*widgets.FileUploaderWidget)
+	uploader.BindFiles([]*widgets.UploadedFile{
+		widgets.NewUploadedFile("a.csv", "text/csv", 1, nil),
+		widgets.NewUploadedFile("b.tsv", "text/tab-separated-values", 1, nil),
+		widgets.NewUploadedFile("c.csv", "text/csv", 1, nil),
+	})
+	service.RenderWidgetsForPage(sessionID)
+	if choice != "c" || !reflect.DeepEqual(picked, []string{"a", "b", "c"}) || level != 80 || low != 20 || high != 95 || len(files) != 3 {
+		t.Fatalf("unexpected values before the change: %q %v %d %d-%d %d files", choice, picked, level, low, high, len(files))
+	}
+
+	options = []string{"b", "c", "d"}
+	max = 60
+	multiple = false
+	extensions = []string{".csv"}
+	service.RenderWidgetsForPage(sessionID)
+	if choice != "c" || !chosen {
+		t.Errorf("selectbox = %q, %v, want the kept option c", choice, chosen)
+	}
+	if !reflect.DeepEqual(picked, []string{"b", "c"}) {
+		t.Errorf("multiselect = %v, want [b c]", picked)
+	}
+	if level != 60 || low != 20 || high != 60 {
+		t.Errorf("sliders = %d, %d-%d, want values clamped to 60", level, low, high)
+	}
+	if len(files) != 1 || files[0].Name != "a.csv" {
+		t.Errorf("files = %v, want only a.csv", files)
+	}
+	if html := appWidget(t, service, sessionID, "selectbox").Render(); !strings.Contains(html, ">d</option>") || strings.Contains(html, ">a</option>") {
+		t.Errorf("selectbox does not render the new options: %s", html)
+	}
+
+	options = []string{"x", "y"}
+	service.RenderWidgetsForPage(sessionID)
+	if chosen || len(picked) != 0 {
+		t.Errorf("selection of removed options kept: %q %v %v", choice, chosen, picked)
+	}
+}
+
+// TestAppKeepsMediaWidget 来源不变时重新运行保留媒体组件，来源变化时重新创建
+func TestAppKeepsMediaWidget(t *testing.T) {
+	encode := func(size int) []byte {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	service := NewService()
+	source := encode(2)
+	service.SetApp(func(st *Context) {
+		st.Image(source)
+	})
+	session := service.GetStateManager().GetSession("media-test")
+	sessionID := session.ID()
+
+	service.RenderWidgetsForPage(sessionID)
+	first := appWidget(t, service, sessionID, "image")
+	service.RenderWidgetsForPage(sessionID)
+	if appWidget(t, service, sessionID, "image") != first {
+		t.Fatal("image rebuilt although the source did not change")
+	}
+
+	source = encode(3)
+	service.RenderWidgetsForPage(sessionID)
+	second := appWidget(t, service, sessionID, "image")
+	if second == first {
+		t.Fatal("image kept although the source changed")
+	}
+	if first.GetID() != second.GetID() {
+		t.Fatalf("image id changed from %s to %s", first.GetID(), second.GetID())
+	}
+}
+
+// TestAppWidgetIDs 应用组件ID在重新运行和服务重启之间保持不变，同一作用域中的重复Key使本次运行中止并显示错误
+func TestAppWidgetIDs(t *testing.T) {
+	app := func(st *Context) {
+		st.TextInput("姓名", "")
+		settings := st.Key("settings").Expander("设置", true)
+		settings.Key("level").TextInput("级别", "")
+	}
+	ids := func(service *Service, sessionID string) []string {
+		service.RenderWidgetsForPage(sessionID)
+		st := service.appStates.get(sessionID)
+		st.mutex.Lock()
+		defer st.mutex.Unlock()
+		var result []string
+		widgets.Walk(st.roots, func(widget widgets.Widget, parent widgets.Widget) bool {
+			result = append(result, widget.GetID())
+			return true
+		})
+		return result
+	}
+
+	first := NewService()
+	first.SetApp(app)
+	before := ids(first, first.GetStateManager().GetSession("id-test").ID())
+	if len(before) != 3 {
+		t.Fatalf("expected 3 widgets, got %v", before)
+	}
+	if rerun := ids(first, first.GetStateManager().GetSession("id-test").ID()); !reflect.DeepEqual(rerun, before) {
+		t.Errorf("ids changed on rerun: %v, %v", before, rerun)
+	}
+	restarted := NewService()
+	restarted.SetApp(app)
+	if after := ids(restarted, restarted.GetStateManager().GetSession("other").ID()); !reflect.DeepEqual(after, before) {
+		t.Errorf("ids changed after restart: %v, %v", before, after)
+	}
+	if want := widgets.KeyID(widgets.KeyID("", "settings"), "level"); before[2] != want {
+		t.Errorf("nested keyed id = %s, want %s", before[2], want)
+	}
+
+	duplicate := NewService()
+	duplicate.SetApp(func(st *Context) {
+		st.Key("name").TextInput("姓名", "")
+		st.Key("name").TextInput("名称", "")
+	})
+	sessionID := duplicate.GetStateManager().GetSession("dup-test").ID()
+	duplicate.RenderWidgetsForPage(sessionID)
+	st := duplicate.appStates.get(sessionID)
+	last := st.roots[len(st.roots)-1].Render()
+	if !strings.Contains(last, widgets.ErrDuplicateKey.Error()) {
+		t.Errorf("duplicate key not reported: %s", last)
+	}
+}
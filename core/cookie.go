@@ -0,0 +1,163 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/lengzhao/streamlit-go/state"
+)
+
+// DefaultSessionCookieName 默认的会话Cookie名称
+const DefaultSessionCookieName = "streamlit_session_id"
+
+// errInvalidSession 会话Cookie缺失或签名无效
+var errInvalidSession = errors.New("invalid session")
+
+// signSessionID 使用HMAC-SHA256为会话ID签名，返回Cookie值
+func (s *Service) signSessionID(sessionID string) string {
+	mac := hmac.New(sha256.New, s.config.Session.Key)
+	mac.Write([]byte(sessionID))
+	return sessionID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifySessionCookie 校验Cookie签名，返回其中的会话ID
+func (s *Service) verifySessionCookie(value string) (string, bool) {
+	sessionID, signature, ok := strings.Cut(value, ".")
+	if !ok || sessionID == "" {
+		return "", false
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, s.config.Session.Key)
+	mac.Write([]byte(sessionID))
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return "", false
+	}
+	return sessionID, true
+}
+
+// setSessionCookie 向客户端下发签名的会话Cookie
+func (s *Service) setSessionCookie(w http.ResponseWriter, r *http.Request, sessionID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.config.Session.CookieName,
+		Value:    s.signSessionID(sessionID),
+		Path:     s.config.Server.BasePath + "/",
+		HttpOnly: true,
+		Secure:   s.config.Session.SecureCookie || r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sessionFromRequest 根据请求中签名的会话Cookie获取会话
+//
+// Cookie缺失或签名无效时返回errInvalidSession，会话不存在或已过期时返回state.ErrSessionNotFound。
+func (s *Service) sessionFromRequest(r *http.Request) (*state.Session, error) {
+	cookie, err := r.Cookie(s.config.Session.CookieName)
+	if err != nil {
+		return nil, errInvalidSession
+	}
+
+	sessionID, ok := s.verifySessionCookie(cookie.Value)
+	if !ok {
+		return nil, errInvalidSession
+	}
+
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return nil, state.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// requireSession 获取请求的会话，失败时写入错误响应并返回nil
+func (s *Service) requireSession(w http.ResponseWriter, r *http.Request) *state.Session {
+	session, err := s.sessionFromRequest(r)
+	switch {
+	case errors.Is(err, errInvalidSession):
+		http.Error(w, "Invalid session", http.StatusForbidden)
+		return nil
+	case errors.Is(err, state.ErrSessionNotFound):
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil
+	}
+	return session
+}
+
+// startSession 获取请求的会话，Cookie无效或会话已过期时创建新会话并下发Cookie
+func (s *Service) startSession(w http.ResponseWriter, r *http.Request) (*state.Session, error) {
+	if session, err := s.sessionFromRequest(r); err == nil {
+		return session, nil
+	}
+
+	session, err := s.stateManager.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+	s.setSessionCookie(w, r, session.ID())
+	return session, nil
+}
+
+// RotateSession 为请求的会话更换新ID并下发新的Cookie，旧ID随即失效
+//
+// 适合在登录等权限变化后调用，防止会话固定攻击。会话数据和已建立的WebSocket连接保持不变。
+func (s *Service) RotateSession(w http.ResponseWriter, r *http.Request) (*state.Session, error) {
+	session, err := s.sessionFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err = s.stateManager.RotateSession(session.ID())
+	if err != nil {
+		return nil, err
+	}
+	s.setSessionCookie(w, r, session.ID())
+	return session, nil
+}
+
+// RequestSessionRotation 通知会话的客户端更换会话ID
+//
+// 用于事件回调等无法直接写HTTP响应的场景：客户端收到通知后请求轮换接口，
+// 由其响应下发新的Cookie。返回收到通知的连接数。
+func (s *Service) RequestSessionRotation(sessionID string) int {
+	return s.hub.SendToSession(sessionID, encodeMessage(Message{Type: MessageTypeRotate}))
+}
+
+// serveSessionRotate 处理客户端发起的会话ID轮换请求
+func (s *Service) serveSessionRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, err := s.RotateSession(w, r); err != nil {
+		switch {
+		case errors.Is(err, errInvalidSession):
+			http.Error(w, "Invalid session", http.StatusForbidden)
+		case errors.Is(err, state.ErrSessionNotFound):
+			http.Error(w, "Session not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to rotate session", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// migrateSession 会话ID更换后迁移按会话ID索引的连接、渲染缓存、应用状态、上传文件、下载地址和媒体引用
+func (s *Service) migrateSession(session *state.Session, oldID string) {
+	s.hub.Rename(oldID, session.ID())
+	s.renderCaches.rename(oldID, session.ID())
+	s.appStates.rename(oldID, session.ID())
+	s.uploads.rename(oldID, session.ID())
+	s.downloads.rename(oldID, session.ID())
+	s.media.rename(oldID, session.ID())
+	s.streams.rename(oldID, session.ID())
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// sendWithCookie 携带指定的会话Cookie值发送请求，value为空时不携带Cookie
+func sendWithCookie(t *testing.T, method, target, value string) *http.Response {
+	t.Helper()
+	var body *strings.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(url.Values{"component_id": {"missing"}, "event_type": {"click"}}.Encode())
+	} else {
+		body = strings.NewReader("")
+	}
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if value != "" {
+		req.AddCookie(&http.Cookie{Name: DefaultSessionCookieName, Value: value})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+// issuedSessionID 获取响应下发的会话Cookie中的会话ID，没有下发时返回空字符串
+func issuedSessionID(resp *http.Response) string {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == DefaultSessionCookieName {
+			sessionID, _, _ := strings.Cut(cookie.Value, ".")
+			return sessionID
+		}
+	}
+	return ""
+}
+
+// TestInvalidSessionCookie 伪造、未签名或签名错误的Cookie不能使用已有会话，首页为其创建新会话
+func TestInvalidSessionCookie(t *testing.T) {
+	service := NewService()
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+
+	resp := sendWithCookie(t, http.MethodGet, server.URL+"/", "")
+	victim := issuedSessionID(resp)
+	if victim == "" {
+		t.Fatal("no session cookie issued")
+	}
+	session, _ := service.GetStateManager().LookupSession(victim)
+	session.Set("secret", "victim")
+
+	otherKey := hmac.New(sha256.New, []byte("another key"))
+	otherKey.Write([]byte(victim))
+	cookies := map[string]string{
+		"unsigned":      victim,
+		"empty sig":     victim + ".",
+		"wrong key":     victim + "." + base64.RawURLEncoding.EncodeToString(otherKey.Sum(nil)),
+		"garbage sig":   victim + ".!!!",
+		"forged id":     "attacker." + strings.SplitN(service.signSessionID(victim), ".", 2)[1],
+		"unknown id":    service.signSessionID("expired-session"),
+		"missing id":    "." + strings.SplitN(service.signSessionID(victim), ".", 2)[1],
+		"only a period": ".",
+	}
+	for name, value := range cookies {
+		resp := sendWithCookie(t, http.MethodGet, server.URL+"/", value)
+		issued := issuedSessionID(resp)
+		if resp.StatusCode != http.StatusOK || issued == "" || issued == victim {
+			t.Errorf("%s: status %d, issued session %q, want a new session", name, resp.StatusCode, issued)
+			continue
+		}
+		created, exists := service.GetStateManager().LookupSession(issued)
+		if !exists {
+			t.Errorf("%s: issued session %s does not exist", name, issued)
+		} else if _, leaked := created.Get("secret"); leaked {
+			t.Errorf("%s: new session sees the data of the existing session", name)
+		}
+
+		// 需要已有会话的接口直接拒绝：签名无效返回403，会话不存在返回404
+		want := http.StatusForbidden
+		if name == "unknown id" {
+			want = http.StatusNotFound
+		}
+		if resp := sendWithCookie(t, http.MethodPost, server.URL+"/event", value); resp.StatusCode != want {
+			t.Errorf("%s: event status %d, want %d", name, resp.StatusCode, want)
+		}
+	}
+
+	if value, _ := session.Get("secret"); value != "victim" {
+		t.Errorf("existing session modified: %v", value)
+	}
+}
+
+// TestRotateSession 轮换后会话数据和WebSocket连接迁移到新ID，旧ID失效
+func TestRotateSession(t *testing.T) {
+	service := NewService()
+	server, client, conn, oldID := connectSession(t, service)
+	defer server.Close()
+	defer conn.Close()
+
+	session, _ := service.GetStateManager().LookupSession(oldID)
+	session.Set("user", "alice")
+	oldCookie := service.signSessionID(oldID)
+
+	resp, err := client.Post(server.URL+"/session/rotate", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	newID := issuedSessionID(resp)
+	if resp.StatusCode != http.StatusNoContent || newID == "" || newID == oldID {
+		t.Fatalf("rotate: status %d, new session %q", resp.StatusCode, newID)
+	}
+
+	rotated, exists := service.GetStateManager().LookupSession(newID)
+	if !exists || rotated != session {
+		t.Fatal("rotated session is not the original session")
+	}
+	if value, _ := rotated.Get("user"); value != "alice" {
+		t.Errorf("session data lost: %v", value)
+	}
+	if _, exists := service.GetStateManager().LookupSession(oldID); exists {
+		t.Error("old session id still resolves")
+	}
+
+	// 已建立的连接改为属于新ID
+	if n := service.hub.SendToSession(oldID, encodeMessage(Message{Type: MessageTypePatch})); n != 0 {
+		t.Errorf("%d clients still registered under the old id", n)
+	}
+	if n := service.hub.SendToSession(newID, encodeMessage(Message{Type: MessageTypeRotate})); n != 1 {
+		t.Fatalf("expected the connection under the new id, got %d", n)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != MessageTypeRotate {
+		t.Fatalf("connection did not receive the message: %+v, %v", msg, err)
+	}
+
+	// 旧Cookie不再有效：接口返回404，首页创建新会话
+	if resp := sendWithCookie(t, http.MethodPost, server.URL+"/event", oldCookie); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("event with the old cookie: status %d, want 404", resp.StatusCode)
+	}
+	if issued := issuedSessionID(sendWithCookie(t, http.MethodGet, server.URL+"/", oldCookie)); issued == "" || issued == oldID || issued == newID {
+		t.Errorf("home with the old cookie issued %q, want a new session", issued)
+	}
+	header := http.Header{}
+	header.Add("Cookie", DefaultSessionCookieName+"="+oldCookie)
+	if ws, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header); err == nil {
+		ws.Close()
+		t.Error("websocket accepted the old cookie")
+	} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("websocket with the old cookie: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// pendingDownload 等待客户端访问的下载
+type pendingDownload struct {
+	widgetID string
+	widget   widgets.IDownloader
+}
+
+// downloadStore 按会话保存一次性下载地址，每个下载组件同时只保留最近一次点击生成的地址
+type downloadStore struct {
+	mutex    sync.Mutex
+	sessions map[string]map[string]*pendingDownload // 会话ID -> 令牌 -> 待下载内容
+}
+
+// newDownloadStore 创建下载地址存储
+func newDownloadStore() *downloadStore {
+	return &downloadStore{
+		sessions: make(map[string]map[string]*pendingDownload),
+	}
+}
+
+// add 为组件生成新的下载令牌，该组件之前未使用的令牌随即失效
+func (d *downloadStore) add(sessionID string, pending *pendingDownload) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	downloads, exists := d.sessions[sessionID]
+	if !exists {
+		downloads = make(map[string]*pendingDownload)
+		d.sessions[sessionID] = downloads
+	}
+	for old, download := range downloads {
+		if download.widgetID == pending.widgetID {
+			delete(downloads, old)
+		}
+	}
+	downloads[token] = pending
+	return token, nil
+}
+
+// take 取出并删除令牌对应的下载，令牌只能使用一次
+func (d *downloadStore) take(sessionID, token string) (*pendingDownload, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	downloads := d.sessions[sessionID]
+	pending, exists := downloads[token]
+	if exists {
+		delete(downloads, token)
+	}
+	return pending, exists
+}
+
+// delete 删除会话的所有下载令牌
+func (d *downloadStore) delete(sessionID string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.sessions, sessionID)
+}
+
+// rename 会话ID更换后迁移下载令牌
+func (d *downloadStore) rename(oldID, newID string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if downloads, exists := d.sessions[oldID]; exists {
+		delete(d.sessions, oldID)
+		d.sessions[newID] = downloads
+	}
+}
+
+// prepareDownload 为被点击的下载组件生成一次性下载地址，组件渲染后由客户端访问
+func (s *Service) prepareDownload(session *state.Session, componentID string, downloader widgets.IDownloader) {
+	token, err := s.downloads.add(session.ID(), &pendingDownload{
+		widgetID: componentID,
+		widget:   downloader,
+	})
+	if err != nil {
+		log.Printf("Failed to generate download token: %v", err)
+		return
+	}
+	downloader.SetDownloadURL(s.config.Server.BasePath + "/download/" + token)
+}
+
+// downloadWriter 记录是否已经写入响应内容，生成失败时据此决定能否返回错误状态
+type downloadWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+// Write 写入响应内容
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(p)
+}
+
+// serveDownload 处理一次性下载地址的请求，内容由下载组件直接写入响应
+func (s *Service) serveDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 下载地址只对签发它的会话有效
+	session := s.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	session.Touch()
+
+	// 在会话锁内取出令牌、清除组件的下载地址并取得下载内容，生成内容较慢时不阻塞会话的事件和渲染
+	token := strings.TrimPrefix(r.URL.Path, "/download/")
+	lock := s.lockSession(session)
+	pending, exists := s.downloads.take(session.ID(), token)
+	var download widgets.Download
+	if exists {
+		pending.widget.SetDownloadURL("")
+		download = pending.widget.Download()
+	}
+	s.unlockSession(session, lock)
+	if !exists {
+		http.Error(w, "Download link expired", http.StatusNotFound)
+		return
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", download.ContentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Cache-Control", "no-store")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	writer := &downloadWriter{ResponseWriter: w}
+	if err := download.Write(session, writer); err != nil {
+		log.Printf("Failed to write download: componentID=%s, error=%v", pending.widgetID, err)
+		if !writer.written {
+			header.Del("Content-Disposition")
+			http.Error(w, "Failed to generate download", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// newSessionClient 访问首页创建会话，返回带有会话Cookie的客户端
+func newSessionClient(t *testing.T, server *httptest.Server) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+	resp, err := client.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return client
+}
+
+var downloadURLPattern = regexp.MustCompile(`data-download-url="([^"]+)"`)
+
+// TestDownloadTokenSingleUse 下载地址只对签发它的会话有效并且只能使用一次，内容在会话锁外生成
+func TestDownloadTokenSingleUse(t *testing.T) {
+	service := NewService()
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+
+	owner := newSessionClient(t, server)
+	sessionIDs := service.GetStateManager().GetAllSessionIDs()
+	if len(sessionIDs) != 1 {
+		t.Fatalf("expected one session, got %d", len(sessionIDs))
+	}
+	sessionID := sessionIDs[0]
+
+	button := widgets.NewDownloadButtonFunc("导出", "report.csv", func(session widgets.ISession, w io.Writer) error {
+		// 生成内容时会话锁已经释放，同一会话的其他更新不被阻塞
+		done := make(chan struct{})
+		go func() {
+			service.UpdateSession(sessionID, func(session *state.Session) {})
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return errors.New("session is locked while writing the download")
+		}
+		_, err := io.WriteString(w, "a,b\n1,2\n")
+		return err
+	})
+	if err := service.AddWidget(button); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := owner.PostForm(server.URL+"/event", url.Values{
+		"component_id": {button.GetID()},
+		"event_type":   {"click"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg Message
+	err = json.NewDecoder(resp.Body).Decode(&msg)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var link string
+	for _, fragment := range msg.Fragments {
+		if match := downloadURLPattern.FindStringSubmatch(fragment.HTML); match != nil {
+			link = match[1]
+		}
+	}
+	if match := downloadURLPattern.FindStringSubmatch(msg.HTML); match != nil {
+		link = match[1]
+	}
+	if link == "" {
+		t.Fatalf("click did not render a download url: %+v", msg)
+	}
+
+	get := func(client *http.Client) (int, string) {
+		t.Helper()
+		resp, err := client.Get(server.URL + link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	// 其他会话不能使用该地址，也不会使地址失效
+	if status, _ := get(newSessionClient(t, server)); status != http.StatusNotFound {
+		t.Fatalf("other session got status %d, want 404", status)
+	}
+	if status, body := get(owner); status != http.StatusOK || body != "a,b\n1,2\n" {
+		t.Fatalf("first download got status %d body %q", status, body)
+	}
+	if status, _ := get(owner); status != http.StatusNotFound {
+		t.Fatalf("second download got status %d, want 404", status)
+	}
+
+	// 下载后组件不再渲染下载地址
+	session := service.GetStateManager().GetSession(sessionID)
+	lock := service.lockSession(session)
+	html := session.Local(button).Render()
+	service.unlockSession(session, lock)
+	if downloadURLPattern.MatchString(html) {
+		t.Fatalf("download url still rendered after use: %s", html)
+	}
+}
@@ -0,0 +1,287 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+const (
+	// writeWait 写消息超时时间
+	writeWait = 10 * time.Second
+	// pongWait 等待客户端pong的超时时间
+	pongWait = 60 * time.Second
+	// pingPeriod 发送ping的周期，必须小于pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// maxMessageSize 客户端消息最大长度
+	maxMessageSize = 64 * 1024
+	// sendBufferSize 每个客户端的发送缓冲区大小
+	sendBufferSize = 64
+)
+
+// 消息类型
+const (
+	MessageTypeEvent  = "event"  // 客户端 -> 服务端：组件事件
+	MessageTypeRender = "render" // 服务端 -> 客户端：完整渲染
+	MessageTypePatch  = "patch"  // 服务端 -> 客户端：局部更新
+	MessageTypeError  = "error"  // 服务端 -> 客户端：错误信息
+	MessageTypeRotate = "rotate" // 服务端 -> 客户端：请求更换会话ID
+	MessageTypeStream = "stream" // 服务端 -> 客户端：组件的增量更新
+)
+
+// Message WebSocket消息
+type Message struct {
+	Type        string                `json:"type"`
+	ComponentID string                `json:"component_id,omitempty"`
+	EventType   string                `json:"event_type,omitempty"`
+	Value       string                `json:"value,omitempty"`
+	HTML        string                `json:"html,omitempty"`
+	Fragments   []Fragment            `json:"fragments,omitempty"`
+	Stream      *widgets.StreamUpdate `json:"stream,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// Client WebSocket客户端连接
+type Client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	session   *state.Session
+	sessionID string // Hub中的索引键，只能在持有Hub锁时读写
+	send      chan []byte
+	closed    bool
+	mutex     sync.Mutex
+}
+
+// Hub 管理所有WebSocket连接，按会话分组
+type Hub struct {
+	clients map[string]map[*Client]struct{} // 会话ID -> 客户端集合
+	mutex   sync.RWMutex
+}
+
+// NewHub 创建新的Hub
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[string]map[*Client]struct{}),
+	}
+}
+
+// newClient 创建客户端
+func newClient(hub *Hub, conn *websocket.Conn, session *state.Session) *Client {
+	return &Client{
+		hub:       hub,
+		conn:      conn,
+		session:   session,
+		sessionID: session.ID(),
+		send:      make(chan []byte, sendBufferSize),
+	}
+}
+
+// Register 注册客户端
+func (h *Hub) Register(client *Client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	clients, exists := h.clients[client.sessionID]
+	if !exists {
+		clients = make(map[*Client]struct{})
+		h.clients[client.sessionID] = clients
+	}
+	clients[client] = struct{}{}
+}
+
+// Unregister 注销客户端并关闭其发送队列
+func (h *Hub) Unregister(client *Client) {
+	h.mutex.Lock()
+	if clients, exists := h.clients[client.sessionID]; exists {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.clients, client.sessionID)
+		}
+	}
+	h.mutex.Unlock()
+
+	client.close()
+}
+
+// SendToSession 发送消息到指定会话的所有连接，返回成功投递的连接数
+func (h *Hub) SendToSession(sessionID string, message []byte) int {
+	h.mutex.RLock()
+	clients := make([]*Client, 0, len(h.clients[sessionID]))
+	for client := range h.clients[sessionID] {
+		clients = append(clients, client)
+	}
+	h.mutex.RUnlock()
+
+	delivered := 0
+	for _, client := range clients {
+		if client.enqueue(message) {
+			delivered++
+		} else {
+			// 发送队列已满，说明客户端过慢，断开连接
+			log.Printf("WebSocket client of session %s is too slow, dropping", sessionID)
+			h.Unregister(client)
+		}
+	}
+	return delivered
+}
+
+// Rename 会话ID更换后迁移其连接
+func (h *Hub) Rename(oldID, newID string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	clients, exists := h.clients[oldID]
+	if !exists {
+		return
+	}
+	delete(h.clients, oldID)
+	for client := range clients {
+		client.sessionID = newID
+	}
+	h.clients[newID] = clients
+}
+
+// HasSession 检查会话是否存在活跃连接
+func (h *Hub) HasSession(sessionID string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.clients[sessionID]) > 0
+}
+
+// SessionIDs 获取所有存在活跃连接的会话ID
+func (h *Hub) SessionIDs() []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	ids := make([]string, 0, len(h.clients))
+	for id := range h.clients {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// CloseSession 关闭指定会话的所有连接，会话删除或过期时调用
+func (h *Hub) CloseSession(sessionID string) {
+	h.mutex.Lock()
+	clients := h.clients[sessionID]
+	delete(h.clients, sessionID)
+	h.mutex.Unlock()
+
+	for client := range clients {
+		client.close()
+	}
+}
+
+// Close 关闭所有连接
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	all := h.clients
+	h.clients = make(map[string]map[*Client]struct{})
+	h.mutex.Unlock()
+
+	for _, clients := range all {
+		for client := range clients {
+			client.close()
+		}
+	}
+}
+
+// encodeMessage 将消息编码为JSON
+func encodeMessage(msg Message) []byte {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Failed to encode message: %v", err)
+		return nil
+	}
+	return data
+}
+
+// enqueue 将消息放入发送队列，队列已满时返回false
+func (c *Client) enqueue(message []byte) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// close 关闭发送队列，writePump随后关闭底层连接
+func (c *Client) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// readPump 读取客户端消息并交给handler处理
+func (c *Client) readPump(handler func(client *Client, msg Message)) {
+	defer func() {
+		c.hub.Unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		// 连接保持期间会话视为活跃
+		c.session.Touch()
+		return nil
+	})
+
+	for {
+		var msg Message
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+		handler(c, msg)
+	}
+}
+
+// writePump 将发送队列中的消息写入连接，并定期发送ping
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// 发送队列已关闭
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/lengzhao/streamlit-go/state"
+)
+
+// sessionLock 串行化同一会话的组件修改和渲染
+//
+// 事件处理、上传和页面渲染期间持有锁。Rerun等推送方法从不等待锁：锁被占用时只记录请求，
+// 由持有者在释放前重新渲染并推送，因此在事件回调中调用Rerun或Broadcast不会死锁。
+type sessionLock struct {
+	mutex   sync.Mutex
+	pending atomic.Bool // 持有锁期间收到的重新渲染请求
+}
+
+// sessionLocks 所有会话的锁，按会话对象索引，会话ID轮换后保持不变
+type sessionLocks struct {
+	locks map[*state.Session]*sessionLock
+	mutex sync.Mutex
+}
+
+// newSessionLocks 创建会话锁表
+func newSessionLocks() *sessionLocks {
+	return &sessionLocks{
+		locks: make(map[*state.Session]*sessionLock),
+	}
+}
+
+// get 获取会话的锁，不存在时创建
+func (l *sessionLocks) get(session *state.Session) *sessionLock {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	lock, exists := l.locks[session]
+	if !exists {
+		lock = &sessionLock{}
+		l.locks[session] = lock
+	}
+	return lock
+}
+
+// delete 删除会话的锁
+func (l *sessionLocks) delete(session *state.Session) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.locks, session)
+}
+
+// lockSession 获取会话锁，等待其他事件处理或渲染完成
+func (s *Service) lockSession(session *state.Session) *sessionLock {
+	lock := s.sessionLocks.get(session)
+	lock.mutex.Lock()
+	return lock
+}
+
+// tryLockSession 尝试获取会话锁，锁被占用时记录重新渲染请求并返回false
+func (s *Service) tryLockSession(session *state.Session) (*sessionLock, bool) {
+	lock := s.sessionLocks.get(session)
+	if lock.mutex.TryLock() {
+		return lock, true
+	}
+	lock.pending.Store(true)
+	// 持有者可能在记录请求之前已经释放，再尝试一次
+	if lock.mutex.TryLock() {
+		return lock, true
+	}
+	return nil, false
+}
+
+// unlockSession 释放会话锁，先推送持有期间收到的重新渲染请求
+func (s *Service) unlockSession(session *state.Session, lock *sessionLock) {
+	for {
+		if lock.pending.Swap(false) {
+			s.pushUpdate(session.ID(), "")
+		}
+		lock.mutex.Unlock()
+
+		// 释放前后记录的请求由再次获得锁的一方处理，获取失败说明其他持有者会处理
+		if !lock.pending.Load() || !lock.mutex.TryLock() {
+			return
+		}
+	}
+}
+
+// pushUpdate 重新渲染会话并推送发生变化的组件，返回收到更新的连接数，调用时需持有会话锁
+//
+// trigger是触发本次脚本式应用运行的组件ID，不是由应用组件的事件引起时为空。
+func (s *Service) pushUpdate(sessionID string, trigger string) int {
+	if !s.hub.HasSession(sessionID) {
+		return 0
+	}
+
+	update := s.renderUpdate(sessionID, trigger)
+	if update == nil {
+		// 页面没有变化
+		return 0
+	}
+	return s.hub.SendToSession(sessionID, encodeMessage(*update))
+}
+
+// UpdateSession 在会话锁内修改会话的组件，然后重新渲染并推送，返回收到更新的连接数
+//
+// 后台goroutine修改会话组件时应使用此方法，使修改与事件处理和渲染互斥：
+//
+//	service.UpdateSession(sessionID, func(session *state.Session) {
+//		widgets.For(session, progress).SetText("已完成")
+//	})
+//
+// 会话不存在时不调用fn并返回0。fn中不能再调用UpdateSession，可以调用Rerun等推送方法。
+func (s *Service) UpdateSession(sessionID string, fn func(session *state.Session)) int {
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return 0
+	}
+
+	lock := s.lockSession(session)
+	fn(session)
+	lock.pending.Store(false)
+	delivered := s.pushUpdate(sessionID, "")
+	s.unlockSession(session, lock)
+	return delivered
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// mediaEntry 媒体内容及引用它的会话数
+type mediaEntry struct {
+	file *widgets.MediaFile
+	refs int
+}
+
+// mediaStore 按内容哈希保存组件引用的媒体
+//
+// 每个会话记录最近一次渲染引用的媒体，会话不再引用或过期后释放引用，
+// 不再被任何会话引用的媒体随即被移除。
+type mediaStore struct {
+	mutex    sync.Mutex
+	files    map[string]*mediaEntry         // 哈希 -> 媒体
+	sessions map[string]map[string]struct{} // 会话ID -> 引用的哈希
+}
+
+// newMediaStore 创建媒体存储
+func newMediaStore() *mediaStore {
+	return &mediaStore{
+		files:    make(map[string]*mediaEntry),
+		sessions: make(map[string]map[string]struct{}),
+	}
+}
+
+// track 记录会话引用的媒体，replace为true时替换会话之前的引用（完整渲染），否则追加（局部更新）
+func (m *mediaStore) track(sessionID string, files []*widgets.MediaFile, replace bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous := m.sessions[sessionID]
+	current := make(map[string]struct{}, len(files))
+	if !replace {
+		for hash := range previous {
+			current[hash] = struct{}{}
+		}
+	}
+
+	for _, file := range files {
+		hash := file.Hash()
+		if _, exists := current[hash]; exists {
+			continue
+		}
+		current[hash] = struct{}{}
+		if _, exists := previous[hash]; exists {
+			continue
+		}
+		entry, exists := m.files[hash]
+		if !exists {
+			entry = &mediaEntry{file: file}
+			m.files[hash] = entry
+		}
+		entry.refs++
+	}
+
+	for hash := range previous {
+		if _, exists := current[hash]; !exists {
+			m.release(hash)
+		}
+	}
+
+	if len(current) == 0 {
+		delete(m.sessions, sessionID)
+	} else {
+		m.sessions[sessionID] = current
+	}
+}
+
+// release 释放一个引用，调用时需持有m.mutex
+func (m *mediaStore) release(hash string) {
+	entry, exists := m.files[hash]
+	if !exists {
+		return
+	}
+	entry.refs--
+	if entry.refs <= 0 {
+		delete(m.files, hash)
+	}
+}
+
+// lookup 获取会话引用的媒体
+func (m *mediaStore) lookup(sessionID, hash string) (*widgets.MediaFile, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.sessions[sessionID][hash]; !exists {
+		return nil, false
+	}
+	entry, exists := m.files[hash]
+	if !exists {
+		return nil, false
+	}
+	return entry.file, true
+}
+
+// delete 释放会话的所有引用
+func (m *mediaStore) delete(sessionID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for hash := range m.sessions[sessionID] {
+		m.release(hash)
+	}
+	delete(m.sessions, sessionID)
+}
+
+// rename 会话ID更换后迁移引用
+func (m *mediaStore) rename(oldID, newID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if refs, exists := m.sessions[oldID]; exists {
+		delete(m.sessions, oldID)
+		m.sessions[newID] = refs
+	}
+}
+
+// collectMedia 收集组件树中引用的媒体
+func collectMedia(roots []widgets.Widget) []*widgets.MediaFile {
+	var files []*widgets.MediaFile
+	widgets.Walk(roots, func(widget widgets.Widget, parent widgets.Widget) bool {
+		if media, ok := widget.(widgets.IMedia); ok {
+			files = append(files, media.MediaFiles()...)
+		}
+		return true
+	})
+	return files
+}
+
+// serveMedia 提供会话引用的媒体内容，支持Range请求和条件请求
+//
+// 地址由内容哈希生成，内容不会变化，因此允许浏览器长期缓存。
+func (s *Service) serveMedia(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 媒体只对引用它的会话可见
+	session := s.requireSession(w, r)
+	if session == nil {
+		return
+	}
+
+	hash := strings.TrimPrefix(r.URL.Path, "/media/")
+	file, exists := s.media.lookup(session.ID(), hash)
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		log.Printf("Failed to open media %s: %v", hash, err)
+		http.Error(w, "Failed to open media", http.StatusInternalServerError)
+		return
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", file.ContentType())
+	header.Set("Cache-Control", "private, max-age=31536000, immutable")
+	header.Set("ETag", "\""+hash+"\"")
+	header.Set("X-Content-Type-Options", "nosniff")
+	// 直接打开媒体地址时禁止执行脚本，例如SVG中的脚本
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	http.ServeContent(w, r, "", file.ModTime(), content)
+}
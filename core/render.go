@@ -0,0 +1,275 @@
+package core
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// Fragment 单个组件的渲染片段，客户端按data-widget-id原地替换
+type Fragment struct {
+	ID   string `json:"id"`
+	HTML string `json:"html"`
+}
+
+// renderCache 会话最近一次发送给客户端的渲染结果
+type renderCache struct {
+	order     []string          // 顶层组件ID顺序
+	fragments map[string]string // 组件ID -> HTML片段
+}
+
+// renderCaches 所有会话的渲染缓存
+type renderCaches struct {
+	caches map[string]*renderCache
+	mutex  sync.Mutex
+}
+
+// newRenderCaches 创建渲染缓存
+func newRenderCaches() *renderCaches {
+	return &renderCaches{
+		caches: make(map[string]*renderCache),
+	}
+}
+
+// delete 删除会话的渲染缓存
+func (c *renderCaches) delete(sessionID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.caches, sessionID)
+}
+
+// rename 会话ID更换后迁移渲染缓存
+func (c *renderCaches) rename(oldID, newID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cache, exists := c.caches[oldID]; exists {
+		delete(c.caches, oldID)
+		c.caches[newID] = cache
+	}
+}
+
+// renderFragments 渲染会话的所有可见顶层组件，会话不存在（例如已过期）时返回false
+//
+// trigger是触发本次应用运行的组件ID，由事件处理传入，其他渲染为空。
+func (s *Service) renderFragments(sessionID string, trigger string) ([]Fragment, bool) {
+	// 获取会话对象，不为已删除的会话重新创建
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return nil, false
+	}
+
+	// 获取全局组件
+	globalWidgets := s.globalWidgetsFor(session)
+
+	// 运行脚本式应用，生成本次的应用组件
+	appWidgets := s.runApp(session, trigger)
+
+	// 获取指定会话组件
+	sessionWidgets := session.GetWidgets()
+
+	// 合并三个列表
+	allWidgets := make([]widgets.Widget, 0, len(globalWidgets)+len(appWidgets)+len(sessionWidgets))
+	allWidgets = append(allWidgets, globalWidgets...)
+	allWidgets = append(allWidgets, appWidgets...)
+	allWidgets = append(allWidgets, sessionWidgets...)
+
+	fragments := make([]Fragment, 0, len(allWidgets))
+	visible := make([]widgets.Widget, 0, len(allWidgets))
+	for _, widget := range allWidgets {
+		if widget.IsVisible() {
+			fragments = append(fragments, Fragment{ID: widget.GetID(), HTML: widget.Render()})
+			visible = append(visible, widget)
+		}
+	}
+
+	// 记录本次渲染引用的媒体和显示的数据流，不再引用的媒体随之释放
+	s.media.track(sessionID, collectMedia(visible), true)
+	s.trackStreams(sessionID, visible, true)
+	return fragments, true
+}
+
+// globalWidgetsFor 获取会话看到的全局组件，会话存在私有副本时使用副本
+func (s *Service) globalWidgetsFor(session *state.Session) []widgets.Widget {
+	globalWidgets := s.GetWidgets()
+	for i, widget := range globalWidgets {
+		if local, exists := session.LocalWidget(widget.GetID()); exists {
+			globalWidgets[i] = local
+		}
+	}
+	return globalWidgets
+}
+
+// joinFragments 拼接渲染片段为完整HTML
+func joinFragments(fragments []Fragment) string {
+	var b strings.Builder
+	for _, f := range fragments {
+		b.WriteString(f.HTML)
+	}
+	return b.String()
+}
+
+// renderPage 完整渲染会话并重置渲染缓存，用于页面首次加载
+func (s *Service) renderPage(sessionID string) string {
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return ""
+	}
+	lock := s.lockSession(session)
+	defer s.unlockSession(session, lock)
+
+	fragments, exists := s.renderFragments(sessionID, "")
+	if !exists {
+		return ""
+	}
+
+	s.renderCaches.mutex.Lock()
+	s.renderCaches.caches[sessionID] = newRenderCache(fragments)
+	s.renderCaches.mutex.Unlock()
+
+	return joinFragments(fragments)
+}
+
+// newRenderCache 根据渲染片段创建缓存
+func newRenderCache(fragments []Fragment) *renderCache {
+	cache := &renderCache{
+		order:     make([]string, len(fragments)),
+		fragments: make(map[string]string, len(fragments)),
+	}
+	for i, f := range fragments {
+		cache.order[i] = f.ID
+		cache.fragments[f.ID] = f.HTML
+	}
+	return cache
+}
+
+// renderUpdate 渲染会话并与上次发送的结果比较
+//
+// 顶层组件的集合或顺序不变时只返回发生变化的片段（patch消息），
+// 否则返回完整渲染（render消息）。没有任何变化或会话不存在时返回nil。
+func (s *Service) renderUpdate(sessionID string, trigger string) *Message {
+	fragments, exists := s.renderFragments(sessionID, trigger)
+	if !exists {
+		return nil
+	}
+
+	s.renderCaches.mutex.Lock()
+	defer s.renderCaches.mutex.Unlock()
+
+	cache := s.renderCaches.caches[sessionID]
+	s.renderCaches.caches[sessionID] = newRenderCache(fragments)
+
+	if cache == nil || !sameOrder(cache.order, fragments) {
+		return &Message{Type: MessageTypeRender, HTML: joinFragments(fragments)}
+	}
+
+	changed := make([]Fragment, 0)
+	for _, f := range fragments {
+		if cache.fragments[f.ID] != f.HTML {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return &Message{Type: MessageTypePatch, Fragments: changed}
+}
+
+// sameOrder 检查顶层组件顺序是否与缓存一致
+func sameOrder(order []string, fragments []Fragment) bool {
+	if len(order) != len(fragments) {
+		return false
+	}
+	for i, f := range fragments {
+		if order[i] != f.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// SendPartialUpdate 只重新渲染指定的顶层组件并推送到会话，返回收到更新的连接数
+//
+// 适用于只有少数组件变化的高频更新，避免渲染整个页面。
+// 与Rerun相同，会话正在处理事件或渲染时由处理方在完成后推送完整比较的结果，此时返回0。
+func (s *Service) SendPartialUpdate(sessionID string, targets ...widgets.Widget) int {
+	if !s.hub.HasSession(sessionID) {
+		return 0
+	}
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return 0
+	}
+	lock, ok := s.tryLockSession(session)
+	if !ok {
+		return 0
+	}
+	defer s.unlockSession(session, lock)
+
+	s.renderCaches.mutex.Lock()
+	cache := s.renderCaches.caches[sessionID]
+	fragments := make([]Fragment, 0, len(targets))
+	for _, widget := range targets {
+		if cache == nil || !widget.IsVisible() {
+			// 缺少基准或需要移除组件时回退到完整比较
+			s.renderCaches.mutex.Unlock()
+			lock.pending.Store(false)
+			return s.pushUpdate(sessionID, "")
+		}
+		if _, exists := cache.fragments[widget.GetID()]; !exists {
+			// 组件不在客户端页面上，无法局部更新
+			continue
+		}
+		f := Fragment{ID: widget.GetID(), HTML: widget.Render()}
+		cache.fragments[f.ID] = f.HTML
+		fragments = append(fragments, f)
+		s.media.track(sessionID, collectMedia([]widgets.Widget{widget}), false)
+		s.trackStreams(sessionID, []widgets.Widget{widget}, false)
+	}
+	s.renderCaches.mutex.Unlock()
+
+	if len(fragments) == 0 {
+		return 0
+	}
+	return s.hub.SendToSession(sessionID, encodeMessage(Message{
+		Type:      MessageTypePatch,
+		Fragments: fragments,
+	}))
+}
+
+// renderWidgetUpdate 只重新渲染发生视图事件的组件，返回patch消息
+//
+// 目标是顶层组件时同时更新渲染缓存；嵌套的组件只在客户端原地替换，
+// 其顶层组件在下一次完整比较时重新发送。没有渲染基准时回退到完整比较。
+func (s *Service) renderWidgetUpdate(sessionID string, widget widgets.Widget) *Message {
+	s.renderCaches.mutex.Lock()
+	cache := s.renderCaches.caches[sessionID]
+	if cache == nil || !widget.IsVisible() {
+		s.renderCaches.mutex.Unlock()
+		return s.renderUpdate(sessionID, "")
+	}
+	f := Fragment{ID: widget.GetID(), HTML: widget.Render()}
+	if _, exists := cache.fragments[f.ID]; exists {
+		cache.fragments[f.ID] = f.HTML
+	}
+	s.media.track(sessionID, collectMedia([]widgets.Widget{widget}), false)
+	s.trackStreams(sessionID, []widgets.Widget{widget}, false)
+	s.renderCaches.mutex.Unlock()
+
+	return &Message{Type: MessageTypePatch, Fragments: []Fragment{f}}
+}
+
+// releaseSession 关闭会话的WebSocket连接，释放关联的渲染缓存、应用状态、上传文件、下载地址、媒体引用和数据流订阅
+func (s *Service) releaseSession(session *state.Session) {
+	s.hub.CloseSession(session.ID())
+	s.sessionLocks.delete(session)
+	s.renderCaches.delete(session.ID())
+	s.appStates.delete(session.ID())
+	s.uploads.delete(session.ID())
+	s.downloads.delete(session.ID())
+	s.media.delete(session.ID())
+	s.streams.delete(session.ID())
+}
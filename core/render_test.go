@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// TestRenderUpdate 页面不变时不发送消息，组件变化时只发送变化的顶层组件，顶层组件增删时回退到完整渲染
+func TestRenderUpdate(t *testing.T) {
+	service := NewService()
+	session := service.GetStateManager().GetSession("render-test")
+	sessionID := session.ID()
+
+	title := widgets.NewText("标题")
+	status := widgets.NewText("状态")
+	nested := widgets.NewText("嵌套")
+	group := widgets.NewContainer(false)
+	group.AddChild(nested)
+	for _, widget := range []widgets.Widget{title, status, group} {
+		if err := session.AddWidget(widget); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if html := service.renderPage(sessionID); !strings.Contains(html, "标题") || !strings.Contains(html, "嵌套") {
+		t.Fatalf("page does not contain the widgets: %s", html)
+	}
+	if msg := service.renderUpdate(sessionID, ""); msg != nil {
+		t.Fatalf("unchanged page sent %+v", msg)
+	}
+
+	status.SetText("已更新")
+	msg := service.renderUpdate(sessionID, "")
+	if msg == nil || msg.Type != MessageTypePatch || len(msg.Fragments) != 1 || msg.Fragments[0].ID != status.GetID() {
+		t.Fatalf("changed widget: %+v, want a patch with only the status", msg)
+	}
+	if !strings.Contains(msg.Fragments[0].HTML, "已更新") {
+		t.Errorf("patch does not contain the new text: %s", msg.Fragments[0].HTML)
+	}
+	if msg := service.renderUpdate(sessionID, ""); msg != nil {
+		t.Fatalf("patch sent again: %+v", msg)
+	}
+
+	// 嵌套组件的变化以所在的顶层组件发送
+	nested.SetText("嵌套已更新")
+	msg = service.renderUpdate(sessionID, "")
+	if msg == nil || msg.Type != MessageTypePatch || len(msg.Fragments) != 1 || msg.Fragments[0].ID != group.GetID() {
+		t.Fatalf("nested change: %+v, want a patch with only the container", msg)
+	}
+
+	added := widgets.NewText("新增")
+	if err := session.AddWidget(added); err != nil {
+		t.Fatal(err)
+	}
+	msg = service.renderUpdate(sessionID, "")
+	if msg == nil || msg.Type != MessageTypeRender || !strings.Contains(msg.HTML, "新增") {
+		t.Fatalf("added widget: %+v, want a full render", msg)
+	}
+
+	session.RemoveWidget(title.GetID())
+	msg = service.renderUpdate(sessionID, "")
+	if msg == nil || msg.Type != MessageTypeRender || strings.Contains(msg.HTML, "标题") {
+		t.Fatalf("removed widget: %+v, want a full render without it", msg)
+	}
+
+	status.SetVisible(false)
+	msg = service.renderUpdate(sessionID, "")
+	if msg == nil || msg.Type != MessageTypeRender || strings.Contains(msg.HTML, `"`+status.GetID()+`"`) {
+		t.Fatalf("hidden widget: %+v, want a full render without it", msg)
+	}
+}
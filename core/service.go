@@ -3,13 +3,17 @@ package core
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
 	"html/template"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/lengzhao/streamlit-go/ptemplate"
 	"github.com/lengzhao/streamlit-go/state"
 	"github.com/lengzhao/streamlit-go/widgets"
@@ -18,20 +22,29 @@ import (
 // Config 服务配置
 type Config struct {
 	Server struct {
-		Host string
-		Port int
+		Host     string
+		Port     int
+		BasePath string       // 挂载路径前缀，例如 "/app"，为空表示挂载在根路径
+		Listener net.Listener // 调用方提供的监听器，设置后Start忽略Host和Port
 	}
 	App struct {
 		Title string
 	}
+	Session struct {
+		CookieName   string // 会话Cookie名称
+		Key          []byte // 会话Cookie签名密钥，为空时启动时随机生成
+		SecureCookie bool   // 是否强制为Cookie设置Secure标志（HTTPS请求会自动设置）
+	}
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
 	return &Config{
 		Server: struct {
-			Host string
-			Port int
+			Host     string
+			Port     int
+			BasePath string
+			Listener net.Listener
 		}{
 			Host: "localhost",
 			Port: 8501,
@@ -41,6 +54,13 @@ func DefaultConfig() *Config {
 		}{
 			Title: "Streamlit Go App",
 		},
+		Session: struct {
+			CookieName   string
+			Key          []byte
+			SecureCookie bool
+		}{
+			CookieName: DefaultSessionCookieName,
+		},
 	}
 }
 
@@ -48,13 +68,27 @@ func DefaultConfig() *Config {
 type Service struct {
 	config        *Config
 	stateManager  *state.Manager
+	hub           *Hub
+	renderCaches  *renderCaches
+	sessionLocks  *sessionLocks
+	upgrader      websocket.Upgrader
 	widgets       []widgets.Widget
 	widgetsMutex  sync.RWMutex
 	ctx           context.Context
 	cancel        context.CancelFunc
 	server        *http.Server
+	serverMutex   sync.Mutex
+	mux           *http.ServeMux
+	handler       http.Handler
 	eventCallback func(session *state.Session, componentID string, eventType string, value string)
 	callbackMutex sync.RWMutex
+	app           func(st *Context)
+	appMutex      sync.RWMutex
+	appStates     *appStates
+	uploads       *uploadStore
+	downloads     *downloadStore
+	media         *mediaStore
+	streams       *streamStore
 }
 
 // Option 配置选项
@@ -81,6 +115,53 @@ func WithTitle(title string) Option {
 	}
 }
 
+// WithBasePath 设置挂载路径前缀，例如 "/app"，页面地址为 "/app/"
+func WithBasePath(basePath string) Option {
+	return func(c *Config) {
+		c.Server.BasePath = basePath
+	}
+}
+
+// WithListener 使用调用方提供的监听器启动服务，Host和Port配置将被忽略
+func WithListener(listener net.Listener) Option {
+	return func(c *Config) {
+		c.Server.Listener = listener
+	}
+}
+
+// normalizeBasePath 规范化路径前缀：以"/"开头、不以"/"结尾，根路径返回空字符串
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// WithSessionKey 设置会话Cookie签名密钥
+//
+// 多个实例共享同一密钥时，任一实例签发的Cookie都能通过校验。
+// 未设置时每次启动随机生成，重启后旧Cookie失效。
+func WithSessionKey(key []byte) Option {
+	return func(c *Config) {
+		c.Session.Key = key
+	}
+}
+
+// WithSessionCookieName 设置会话Cookie名称
+func WithSessionCookieName(name string) Option {
+	return func(c *Config) {
+		c.Session.CookieName = name
+	}
+}
+
+// WithSecureCookie 强制为会话Cookie设置Secure标志，适用于在HTTPS反向代理之后部署
+func WithSecureCookie(secure bool) Option {
+	return func(c *Config) {
+		c.Session.SecureCookie = secure
+	}
+}
+
 // NewService 创建新的核心服务
 func NewService(options ...Option) *Service {
 	config := DefaultConfig()
@@ -90,42 +171,132 @@ func NewService(options ...Option) *Service {
 		opt(config)
 	}
 
+	config.Server.BasePath = normalizeBasePath(config.Server.BasePath)
+
+	// 未配置签名密钥时随机生成
+	if len(config.Session.Key) == 0 {
+		config.Session.Key = make([]byte, 32)
+		if _, err := rand.Read(config.Session.Key); err != nil {
+			panic(fmt.Sprintf("failed to generate session key: %v", err))
+		}
+	}
+
 	// 创建状态管理器，会话超时5分钟，每1分钟清理一次
 	stateManager := state.NewManager(1*time.Minute, 5*time.Minute)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	service := &Service{
-		config:        config,
-		stateManager:  stateManager,
+		config:       config,
+		stateManager: stateManager,
+		hub:          NewHub(),
+		renderCaches: newRenderCaches(),
+		sessionLocks: newSessionLocks(),
+		appStates:    newAppStates(),
+		uploads:      newUploadStore(),
+		downloads:    newDownloadStore(),
+		media:        newMediaStore(),
+		streams:      newStreamStore(),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+		},
+		mux:           http.NewServeMux(),
 		widgets:       make([]widgets.Widget, 0),
 		ctx:           ctx,
 		cancel:        cancel,
 		eventCallback: nil,
 	}
 
+	// 会话可以通过Session.Rerun主动请求重新渲染
+	stateManager.SetRerunHandler(func(session *state.Session) {
+		service.Rerun(session.ID())
+	})
+	stateManager.OnSessionExpired(service.releaseSession)
+	stateManager.SetGlobalWidgets(service.GetWidgets)
+	stateManager.OnSessionRotated(service.migrateSession)
+
+	// 在服务私有的路由器上注册路由，多个服务可以共存于同一进程
+	service.registerRoutes()
+	service.handler = service.mountHandler()
+
 	return service
 }
 
-// Start 启动服务
-func (s *Service) Start() error {
-	log.Printf("Starting Streamlit Go service on %s:%d", s.config.Server.Host, s.config.Server.Port)
-
-	// 启动状态管理器的清理任务
+// Handler 返回服务的HTTP处理器，可以挂载到已有的HTTP服务器中
+//
+// 处理器匹配包含路径前缀的完整路径，配置了WithBasePath("/app")时应这样挂载：
+//
+//	mux.Handle("/app/", service.Handler())
+//
+// 首次调用时启动状态管理器的会话清理任务，通过Stop停止。
+func (s *Service) Handler() http.Handler {
 	s.stateManager.Start()
+	return s.handler
+}
+
+// mountHandler 创建处理路径前缀的处理器
+func (s *Service) mountHandler() http.Handler {
+	basePath := s.config.Server.BasePath
+	if basePath == "" {
+		return s.mux
+	}
+
+	stripped := http.StripPrefix(basePath, s.mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 页面地址需要以"/"结尾
+		if r.URL.Path == basePath {
+			http.Redirect(w, r, basePath+"/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
+}
+
+// Start 启动服务，配置了WithListener时使用该监听器，否则监听Host和Port
+func (s *Service) Start() error {
+	if s.config.Server.Listener != nil {
+		return s.Serve(s.config.Server.Listener)
+	}
 
-	// 创建HTTP服务器
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
-	s.server = &http.Server{
-		Addr: addr,
+	log.Printf("Starting Streamlit Go service on %s", addr)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
 	}
+	return s.Serve(listener)
+}
 
-	// 注册路由处理器
-	s.registerRoutes()
+// Serve 在调用方提供的监听器上启动服务，阻塞直到服务停止
+func (s *Service) Serve(listener net.Listener) error {
+	server := &http.Server{
+		Handler: s.Handler(),
+	}
+
+	s.serverMutex.Lock()
+	if s.server != nil {
+		s.serverMutex.Unlock()
+		return fmt.Errorf("service is already running")
+	}
+	s.server = server
+	s.serverMutex.Unlock()
 
 	// 启动HTTP服务器
-	log.Printf("HTTP server starting on %s", addr)
-	return s.server.ListenAndServe()
+	log.Printf("HTTP server starting on %s%s/", listener.Addr(), s.config.Server.BasePath)
+	err := server.Serve(listener)
+
+	s.serverMutex.Lock()
+	if s.server == server {
+		s.server = nil
+	}
+	s.serverMutex.Unlock()
+	return err
 }
 
 // Stop 停止服务
@@ -135,15 +306,24 @@ func (s *Service) Stop() error {
 	// 停止状态管理器
 	s.stateManager.Stop()
 
+	// 关闭所有WebSocket连接
+	s.hub.Close()
+
 	// 停止HTTP服务器
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if s.server != nil {
-		if err := s.server.Shutdown(ctx); err != nil {
+	s.serverMutex.Lock()
+	server := s.server
+	s.serverMutex.Unlock()
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
 			log.Printf("HTTP server stop error: %v", err)
 		}
 	}
 
+	// 删除所有会话的上传文件
+	s.uploads.deleteAll()
+
 	// 取消应用上下文
 	if s.cancel != nil {
 		s.cancel()
@@ -153,11 +333,21 @@ func (s *Service) Stop() error {
 }
 
 // AddWidget 添加组件到全局队列
-func (s *Service) AddWidget(widget widgets.Widget) {
+//
+// 组件树中设置了Key的组件与已有全局组件的Key重复时不添加，返回包装widgets.ErrDuplicateKey的错误。
+func (s *Service) AddWidget(widget widgets.Widget) error {
+	if err := widgets.AssignKeys([]widgets.Widget{widget}); err != nil {
+		return err
+	}
+
 	s.widgetsMutex.Lock()
 	defer s.widgetsMutex.Unlock()
 
+	if err := widgets.CheckKeys(s.widgets, widget); err != nil {
+		return err
+	}
 	s.widgets = append(s.widgets, widget)
+	return nil
 }
 
 // GetWidgets 获取全局组件
@@ -171,22 +361,22 @@ func (s *Service) GetWidgets() []widgets.Widget {
 	return widgetsCopy
 }
 
-// Title 添加标题组件
-func (s *Service) Title(text string) {
+// Title 添加标题组件，返回AddWidget的错误
+func (s *Service) Title(text string) error {
 	title := widgets.NewText(text)
-	s.AddWidget(title)
+	return s.AddWidget(title)
 }
 
-// Header 添加头部组件
-func (s *Service) Header(text string, withDivider bool) {
+// Header 添加头部组件，返回AddWidget的错误
+func (s *Service) Header(text string, withDivider bool) error {
 	header := widgets.NewText(text)
-	s.AddWidget(header)
+	return s.AddWidget(header)
 }
 
-// Text 添加文本组件
-func (s *Service) Text(text string) {
+// Text 添加文本组件，返回AddWidget的错误
+func (s *Service) Text(text string) error {
 	textWidget := widgets.NewText(text)
-	s.AddWidget(textWidget)
+	return s.AddWidget(textWidget)
 }
 
 // SetEventCallback 设置事件回调函数
@@ -196,52 +386,40 @@ func (s *Service) SetEventCallback(callback func(session *state.Session, compone
 	s.eventCallback = callback
 }
 
-// handleEvent 处理事件
-func (s *Service) handleEvent(session *state.Session, componentID string, eventType string, value string) {
+// handleEvent 处理事件，返回触发脚本式应用运行的组件ID，由随后的渲染传给应用
+func (s *Service) handleEvent(session *state.Session, componentID string, eventType string, value string) (trigger string) {
+	session.Touch()
+
 	s.callbackMutex.RLock()
 	defer s.callbackMutex.RUnlock()
 
 	if s.eventCallback != nil {
 		s.eventCallback(session, componentID, eventType, value)
-	} else {
-		// 默认处理：查找对应的组件并触发回调
-		s.handleComponentEvent(session, componentID, eventType, value)
+		return ""
 	}
+	// 默认处理：查找对应的组件并触发回调
+	return s.handleComponentEvent(session, componentID, eventType, value)
 }
 
-// handleComponentEvent 处理组件事件
-func (s *Service) handleComponentEvent(session *state.Session, componentID string, eventType string, value string) {
+// handleComponentEvent 处理组件事件，事件来自应用组件时返回该组件ID
+func (s *Service) handleComponentEvent(session *state.Session, componentID string, eventType string, value string) (trigger string) {
 	log.Printf("Component event received: sessionID=%s, componentID=%s, eventType=%s, value=%v",
 		session.ID(), componentID, eventType, value)
 
-	// 查找对应的组件并更新其值（优先在会话组件中查找）
-	var targetWidget widgets.Widget
-	found := false
-
-	// 先在会话组件中查找
-	sessionWidgets := session.GetWidgets()
-	for _, widget := range sessionWidgets {
-		if widget.GetID() == componentID {
-			targetWidget = widget
-			found = true
-			break
-		}
-	}
+	targetWidget, fromApp := s.lookupWidget(session, componentID)
+	found := targetWidget != nil
 
-	// 如果没找到，再在全局组件中查找
-	if !found {
-		globalWidgets := s.GetWidgets()
-		for _, widget := range globalWidgets {
-			if widget.GetID() == componentID {
-				targetWidget = widget
-				found = true
-				break
+	if found {
+		log.Printf("Event widget: %s, Type: %s, Value: %v", targetWidget.GetID(), targetWidget.GetType(), value)
+
+		// 先将事件值绑定到组件状态，回调中即可读取最新值
+		if binder, ok := targetWidget.(widgets.IValueBinder); ok {
+			if err := binder.BindValue(session, eventType, value); err != nil {
+				log.Printf("Failed to bind event value: %v", err)
+				return ""
 			}
 		}
-	}
 
-	if found {
-		log.Printf("Event widget: %s, Type: %s, Value: %v", targetWidget.GetID(), targetWidget.GetType(), value)
 		bw, ok := targetWidget.(widgets.ITriggerCallbacks)
 		if ok {
 			// 在触发回调时传递会话对象，允许回调函数直接操作会话
@@ -249,36 +427,123 @@ func (s *Service) handleComponentEvent(session *state.Session, componentID strin
 		} else {
 			log.Printf("Widget does not implement ITriggerCallbacks")
 		}
+
+		// 下载组件被点击后生成一次性下载地址
+		if downloader, ok := targetWidget.(widgets.IDownloader); ok && eventType == "click" {
+			s.prepareDownload(session, componentID, downloader)
+		}
+
+		// 应用组件的事件触发随后的运行，视图事件只改变组件自身的显示，不重新运行
+		if fromApp && !isViewEvent(targetWidget, eventType) {
+			return componentID
+		}
 	} else {
 		log.Printf("Widget with ID %s not found", componentID)
 	}
+	return ""
+}
+
+// isViewEvent 检查事件是否只改变组件自身的显示
+func isViewEvent(widget widgets.Widget, eventType string) bool {
+	viewer, ok := widget.(widgets.IViewEvent)
+	return ok && viewer.IsViewEvent(eventType)
+}
+
+// viewEventTarget 获取视图事件的目标组件，不是视图事件时返回nil
+func (s *Service) viewEventTarget(session *state.Session, componentID string, eventType string) widgets.Widget {
+	widget, _ := s.lookupWidget(session, componentID)
+	if widget == nil || !isViewEvent(widget, eventType) {
+		return nil
+	}
+	return widget
+}
+
+// lookupWidget 查找事件的目标组件，fromApp表示组件来自脚本式应用最近一次运行
+//
+// 依次在会话组件、应用组件和全局组件中查找，支持嵌套在容器中的组件。
+// 全局的输入、上传和下载组件返回该会话的私有副本，使组件状态按会话隔离。
+func (s *Service) lookupWidget(session *state.Session, componentID string) (widget widgets.Widget, fromApp bool) {
+	if widget := session.FindWidget(componentID); widget != nil {
+		return widget, false
+	}
+
+	if widget := s.findAppWidget(session, componentID); widget != nil {
+		return widget, true
+	}
+
+	index := widgets.NewIndex(s.globalWidgetsFor(session))
+	widget, exists := index.Lookup(componentID)
+	if !exists {
+		return nil, false
+	}
+	switch widget.(type) {
+	case widgets.IValueBinder, widgets.IFileUploader, widgets.IDownloader:
+		widget = session.Local(widget)
+	}
+	return widget, false
 }
 
 // RenderWidgetsForPage 为指定页面渲染所有组件为HTML
+//
+// 渲染期间持有会话锁，不能在事件回调或UpdateSession中调用。
 func (s *Service) RenderWidgetsForPage(sessionID string) string {
-	// 获取会话对象
-	session := s.stateManager.GetSession(sessionID)
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return ""
+	}
 
-	// 获取全局组件
-	globalWidgets := s.GetWidgets()
+	lock := s.lockSession(session)
+	defer s.unlockSession(session, lock)
+	fragments, _ := s.renderFragments(sessionID, "")
+	return joinFragments(fragments)
+}
 
-	// 获取指定会话组件
-	sessionWidgets := session.GetWidgets()
+// Rerun 重新渲染指定会话并通过WebSocket推送，返回收到更新的连接数
+//
+// 只有发生变化的顶层组件会被发送给客户端。可以在任意goroutine中调用，例如后台任务修改组件后刷新页面。
+// 会话没有活跃的WebSocket连接时不做任何处理。会话正在处理事件或渲染时（包括在事件回调中调用）
+// 不等待，由处理方在完成后推送，此时返回0。
+func (s *Service) Rerun(sessionID string) int {
+	if !s.hub.HasSession(sessionID) {
+		return 0
+	}
+	session, exists := s.stateManager.LookupSession(sessionID)
+	if !exists {
+		return 0
+	}
 
-	// 合并两个列表
-	allWidgets := make([]widgets.Widget, 0, len(globalWidgets)+len(sessionWidgets))
-	allWidgets = append(allWidgets, globalWidgets...)
-	allWidgets = append(allWidgets, sessionWidgets...)
+	lock, ok := s.tryLockSession(session)
+	if !ok {
+		return 0
+	}
+	lock.pending.Store(false)
+	delivered := s.pushUpdate(sessionID, "")
+	s.unlockSession(session, lock)
+	return delivered
+}
 
-	// 直接渲染组件为HTML，不使用ui.Renderer
-	html := ""
-	for _, widget := range allWidgets {
-		if widget.IsVisible() {
-			html += widget.Render()
-		}
+// SendToSession 重新渲染一组会话并推送，返回收到更新的连接总数
+func (s *Service) SendToSession(sessionIDs ...string) int {
+	delivered := 0
+	for _, sessionID := range sessionIDs {
+		delivered += s.Rerun(sessionID)
 	}
+	return delivered
+}
+
+// Broadcast 重新渲染状态管理器中的所有会话并推送，返回收到更新的连接总数
+func (s *Service) Broadcast() int {
+	return s.SendToSession(s.stateManager.GetAllSessionIDs()...)
+}
+
+// GetStateManager 获取状态管理器
+func (s *Service) GetStateManager() *state.Manager {
+	return s.stateManager
+}
 
-	return html
+// GetBasePath 获取挂载路径前缀
+func (s *Service) GetBasePath() string {
+	return s.config.Server.BasePath
 }
 
 // GetAddress 获取服务器地址
@@ -286,34 +551,54 @@ func (s *Service) GetAddress() string {
 	return fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 }
 
-// registerRoutes 注册路由处理器
+// registerRoutes 在服务私有的路由器上注册路由处理器
 func (s *Service) registerRoutes() {
 	// 静态文件服务
-	http.HandleFunc("/static/", s.serveStatic)
+	s.mux.HandleFunc("/static/", s.serveStatic)
 
-	// WebSocket连接 - 简化版本中不实现
-	http.HandleFunc("/ws", s.serveWebSocket)
+	// WebSocket连接
+	s.mux.HandleFunc("/ws", s.serveWebSocket)
 
 	// 主页
-	http.HandleFunc("/", s.serveHome)
+	s.mux.HandleFunc("/", s.serveHome)
 
 	// 健康检查
-	http.HandleFunc("/health", s.serveHealth)
+	s.mux.HandleFunc("/health", s.serveHealth)
 
 	// 组件事件处理
-	http.HandleFunc("/event", s.serveEvent)
-}
+	s.mux.HandleFunc("/event", s.serveEvent)
+
+	// 会话ID轮换
+	s.mux.HandleFunc("/session/rotate", s.serveSessionRotate)
+
+	// 文件上传
+	s.mux.HandleFunc("/upload", s.serveUpload)
 
-// serveStatic 处理静态文件请求
-func (s *Service) serveStatic(w http.ResponseWriter, r *http.Request) {
-	// 简单实现，实际项目中应该使用http.FileServer
-	http.NotFound(w, r)
+	// 一次性下载地址
+	s.mux.HandleFunc("/download/", s.serveDownload)
+
+	// 图片、音频和视频内容
+	s.mux.HandleFunc("/media/", s.serveMedia)
 }
 
 // serveHome 处理主页请求
 func (s *Service) serveHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// 获取会话，没有有效Cookie时由服务端签发新会话
+	session, err := s.startSession(w, r)
+	if err != nil {
+		log.Printf("Failed to create session: %v", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	session.Touch()
+
 	// 生成初始HTML页面
-	html := s.generateInitialPage(r)
+	html := s.generateInitialPage(session.ID())
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(html))
@@ -339,54 +624,91 @@ func (s *Service) serveEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionID := r.FormValue("session_id")
 	componentID := r.FormValue("component_id")
 	eventType := r.FormValue("event_type")
 	value := r.FormValue("value")
 
-	// 获取会话对象
-	session := s.stateManager.GetSession(sessionID)
+	// 获取会话对象，只接受服务端签发的会话
+	session := s.requireSession(w, r)
 	if session == nil {
-		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
+	// 处理事件并重新渲染，与该会话的其他事件和渲染互斥
+	lock := s.lockSession(session)
+	trigger := s.handleEvent(session, componentID, eventType, value)
+
+	// 重新渲染页面，只返回发生变化的组件；视图事件只渲染目标组件
+	lock.pending.Store(false)
+	var update *Message
+	if target := s.viewEventTarget(session, componentID, eventType); target != nil {
+		update = s.renderWidgetUpdate(session.ID(), target)
+	} else {
+		update = s.renderUpdate(session.ID(), trigger)
+	}
+	s.unlockSession(session, lock)
+	if update == nil {
+		update = &Message{Type: MessageTypePatch}
+	}
 
-	// 处理事件
-	s.handleEvent(session, componentID, eventType, value)
-
-	// 重新渲染页面并返回
-	widgetsHTML := s.RenderWidgetsForPage(sessionID)
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(widgetsHTML))
+	w.Write(encodeMessage(*update))
 }
 
-// serveWebSocket 简化的WebSocket处理（不实现实际功能）
+// serveWebSocket 处理WebSocket连接，同一连接上行传输事件、下行推送渲染结果
 func (s *Service) serveWebSocket(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "WebSocket not implemented in simplified version", http.StatusNotImplemented)
+	// 获取会话对象，只接受服务端签发的会话
+	session := s.requireSession(w, r)
+	if session == nil {
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	client := newClient(s.hub, conn, session)
+	s.hub.Register(client)
+
+	go client.writePump()
+	go client.readPump(s.handleSocketMessage)
+}
+
+// handleSocketMessage 处理WebSocket客户端消息
+func (s *Service) handleSocketMessage(client *Client, msg Message) {
+	if msg.Type != MessageTypeEvent {
+		log.Printf("Unknown WebSocket message type: %s", msg.Type)
+		return
+	}
+
+	// 处理事件并重新渲染，与该会话的其他事件和渲染互斥
+	session := client.session
+	lock := s.lockSession(session)
+	defer s.unlockSession(session, lock)
+	trigger := s.handleEvent(session, msg.ComponentID, msg.EventType, msg.Value)
+
+	// 视图事件只推送目标组件，其余事件重新渲染并推送到该会话的所有连接
+	lock.pending.Store(false)
+	if target := s.viewEventTarget(session, msg.ComponentID, msg.EventType); target != nil {
+		if update := s.renderWidgetUpdate(session.ID(), target); update != nil {
+			s.hub.SendToSession(session.ID(), encodeMessage(*update))
+		}
+		return
+	}
+	s.pushUpdate(session.ID(), trigger)
 }
 
 // generateInitialPage 生成初始HTML页面
-func (s *Service) generateInitialPage(r *http.Request) string {
+func (s *Service) generateInitialPage(sessionID string) string {
 	title := "Streamlit Go App"
 	if s.config.App.Title != "" {
 		title = s.config.App.Title
 	}
 
-	// 获取会话ID
-	sessionID := r.URL.Query().Get("sessionId")
-	if sessionID == "" {
-		// 生成新的会话ID
-		var err error
-		sessionID, err = state.GenerateSessionID()
-		if err != nil {
-			sessionID = "default-session-id"
-		}
-	}
-
 	// 生成组件HTML
-	widgetsHTML := s.RenderWidgetsForPage(sessionID)
+	widgetsHTML := s.renderPage(sessionID)
 
 	// 从模板包中获取页面模板
 	tmpl, err := ptemplate.GetPageTemplate()
@@ -396,9 +718,9 @@ func (s *Service) generateInitialPage(r *http.Request) string {
 	}
 
 	data := map[string]interface{}{
-		"Title":     title,
-		"Content":   template.HTML(widgetsHTML),
-		"SessionID": sessionID,
+		"Title":    title,
+		"Content":  template.HTML(widgetsHTML),
+		"BasePath": s.config.Server.BasePath,
 	}
 
 	var buf bytes.Buffer
@@ -0,0 +1,440 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lengzhao/streamlit-go/charts"
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// TestConcurrentEventsAndBroadcasts 并发发送事件、广播和后台更新，配合-race检查会话组件的修改和渲染互斥
+func TestConcurrentEventsAndBroadcasts(t *testing.T) {
+	service := NewService()
+	input := widgets.NewNumberInput("数量", 0)
+	text := widgets.NewText("")
+	input.OnChange(func(session widgets.ISession, event string, value string) {
+		widgets.For(session, text).SetText("数量: " + value)
+		// 回调中请求重新渲染不能死锁
+		session.(*state.Session).Rerun()
+	})
+	if err := service.AddWidget(input); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.AddWidget(text); err != nil {
+		t.Fatal(err)
+	}
+	service.SetApp(func(st *Context) {
+		st.Text(fmt.Sprint("应用: ", st.NumberInput("应用数量", 0)))
+	})
+
+	server, client, conn, sessionID := connectSession(t, service)
+	defer server.Close()
+	defer conn.Close()
+
+	// 持续读取推送，避免发送队列写满后连接被断开
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	appInputID := appWidgetID(t, service, sessionID, "number_input")
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	var writeMutex sync.Mutex
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				fn(i)
+			}
+		}()
+	}
+
+	run(func(i int) {
+		resp, err := client.PostForm(server.URL+"/event", url.Values{
+			"component_id": {input.GetID()},
+			"event_type":   {"input"},
+			"value":        {fmt.Sprint(i)},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+	})
+	run(func(i int) {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		err := conn.WriteJSON(Message{Type: MessageTypeEvent, ComponentID: appInputID, EventType: "input", Value: fmt.Sprint(i)})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(int) { service.Broadcast() })
+	run(func(int) { service.Rerun(sessionID) })
+	run(func(i int) {
+		service.UpdateSession(sessionID, func(session *state.Session) {
+			widgets.For(session, text).SetText(fmt.Sprint("后台: ", i))
+		})
+	})
+	wg.Wait()
+
+	// 所有请求完成后页面仍然可以渲染
+	if html := service.RenderWidgetsForPage(sessionID); !strings.Contains(html, text.GetID()) {
+		t.Fatalf("rendered page does not contain text widget: %s", html)
+	}
+}
+
+// connectSession 启动测试服务器，加载页面创建会话并建立WebSocket连接
+func connectSession(t *testing.T, service *Service) (*httptest.Server, *http.Client, *websocket.Conn, string) {
+	t.Helper()
+	server := httptest.NewServer(service.Handler())
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+	resp, err := client.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	header := http.Header{}
+	for _, cookie := range jar.Cookies(serverURL) {
+		header.Add("Cookie", cookie.String())
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionIDs := service.GetStateManager().GetAllSessionIDs()
+	if len(sessionIDs) != 1 {
+		t.Fatalf("expected one session, got %d", len(sessionIDs))
+	}
+	return server, client, conn, sessionIDs[0]
+}
+
+// appWidgetID 在会话最近一次运行的应用组件中查找指定类型的组件ID
+func appWidgetID(t *testing.T, s *Service, sessionID string, widgetType string) string {
+	t.Helper()
+	st := s.appStates.get(sessionID)
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	for _, widget := range st.roots {
+		if widget.GetType() == widgetType {
+			return widget.GetID()
+		}
+	}
+	t.Fatalf("app widget of type %s not found", widgetType)
+	return ""
+}
+
+// TestRenderSkipsMissingSession 为不存在的会话渲染时不重新创建会话
+func TestRenderSkipsMissingSession(t *testing.T) {
+	service := NewService()
+	if update := service.renderUpdate("missing", ""); update != nil {
+		t.Fatalf("expected no update for missing session, got %+v", update)
+	}
+	if _, exists := service.GetStateManager().LookupSession("missing"); exists {
+		t.Fatal("rendering recreated a missing session")
+	}
+}
+
+// TestTriggerOnlyReachesEventRun 按钮事件只对其引起的运行可见，其他渲染不会消耗或看到触发
+func TestTriggerOnlyReachesEventRun(t *testing.T) {
+	service := NewService()
+	clicks := 0
+	service.SetApp(func(st *Context) {
+		if st.Button("点击") {
+			clicks++
+		}
+	})
+
+	session := service.GetStateManager().GetSession("trigger-test")
+	sessionID := session.ID()
+	service.RenderWidgetsForPage(sessionID)
+	buttonID := appWidgetID(t, service, sessionID, "button")
+
+	trigger := service.handleEvent(session, buttonID, "click", "")
+	if trigger != buttonID {
+		t.Fatalf("expected trigger %s, got %q", buttonID, trigger)
+	}
+	// 事件处理和其引起的渲染之间的其他渲染看不到这次点击
+	service.RenderWidgetsForPage(sessionID)
+	if clicks != 0 {
+		t.Fatalf("unrelated render observed the click, clicks = %d", clicks)
+	}
+	service.renderUpdate(sessionID, trigger)
+	if clicks != 1 {
+		t.Fatalf("expected the event run to observe one click, got %d", clicks)
+	}
+	service.RenderWidgetsForPage(sessionID)
+	if clicks != 1 {
+		t.Fatalf("click observed by a later run, clicks = %d", clicks)
+	}
+}
+
+// TestLiveChartAppendSendsDelta 实时图表追加数据后只推送新增的点和删除的点数，错过更新的页面重新获取整个图表
+func TestLiveChartAppendSendsDelta(t *testing.T) {
+	service := NewService()
+	chart := charts.NewLineChart(charts.NewTimeSeries("请求数", nil, nil))
+	chart.SetWindow(100, 0)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) charts.Point {
+		return charts.At(start.Add(time.Duration(i)*time.Second), float64(i%7))
+	}
+	// 页面显示之前窗口已满
+	for i := 0; i < 100; i++ {
+		chart.Append(at(i))
+	}
+	text := widgets.NewText("说明")
+	for _, widget := range []widgets.Widget{chart, text} {
+		if err := service.AddWidget(widget); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, _, conn, _ := connectSession(t, service)
+	defer server.Close()
+	defer conn.Close()
+
+	read := func() (Message, int) {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg, len(data)
+	}
+
+	var version uint64
+	for i := 100; i < 103; i++ {
+		chart.Append(at(i))
+		msg, size := read()
+		if msg.Type != MessageTypeStream || msg.ComponentID != chart.GetID() || msg.Stream == nil {
+			t.Fatalf("expected a stream update of the chart, got %+v", msg)
+		}
+		if i > 100 && msg.Stream.From != version {
+			t.Fatalf("update from version %d, want %d", msg.Stream.From, version)
+		}
+		version = msg.Stream.To
+
+		var series *widgets.StreamOp
+		for j, op := range msg.Stream.Ops {
+			if strings.Contains(op.HTML+op.Append, "<svg") {
+				t.Fatalf("update contains the whole chart: %+v", op)
+			}
+			if op.Target == `[data-series="0"]` {
+				series = &msg.Stream.Ops[j]
+			}
+		}
+		// 窗口已满，每次追加一个点并删除最旧的一个点
+		if series == nil || series.Drop != 1 || strings.Count(series.Append, "st-chart-live-point") != 1 {
+			t.Fatalf("expected one appended and one dropped point, got %+v", series)
+		}
+		if full := len(chart.Render()); size*4 > full {
+			t.Fatalf("update of %d bytes is not much smaller than the full chart of %d bytes", size, full)
+		}
+	}
+
+	// 错过更新的页面请求重新渲染，服务只发送该图表
+	if err := conn.WriteJSON(Message{Type: MessageTypeEvent, ComponentID: chart.GetID(), EventType: "resync"}); err != nil {
+		t.Fatal(err)
+	}
+	msg, _ := read()
+	if msg.Type != MessageTypePatch || len(msg.Fragments) != 1 || msg.Fragments[0].ID != chart.GetID() {
+		t.Fatalf("expected a patch of the chart only, got %+v", msg)
+	}
+	if !strings.Contains(msg.Fragments[0].HTML, fmt.Sprintf(`data-version="%d"`, version)) {
+		t.Fatalf("resynced chart is not at version %d", version)
+	}
+	if points := strings.Count(msg.Fragments[0].HTML, "st-chart-live-point"); points != 100 {
+		t.Fatalf("expected 100 points in the window, got %d", points)
+	}
+}
+
+// TestAddWidgetDuplicateKey 全局组件和会话组件的Key与已有组件重复时返回ErrDuplicateKey
+func TestAddWidgetDuplicateKey(t *testing.T) {
+	service := NewService()
+	keyed := func(key string) *widgets.TextInputWidget {
+		input := widgets.NewTextInput(key, "")
+		input.SetKey(key)
+		return input
+	}
+
+	if err := service.AddWidget(keyed("name")); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.AddWidget(keyed("name")); !errors.Is(err, widgets.ErrDuplicateKey) {
+		t.Errorf("duplicate global key: error %v, want ErrDuplicateKey", err)
+	}
+	form := widgets.NewForm(true)
+	form.AddChild(keyed("email"))
+	form.AddChild(keyed("email"))
+	if err := service.AddWidget(form); !errors.Is(err, widgets.ErrDuplicateKey) {
+		t.Errorf("duplicate sibling keys: error %v, want ErrDuplicateKey", err)
+	}
+	if len(service.GetWidgets()) != 1 {
+		t.Errorf("widgets with duplicate keys were added: %d widgets", len(service.GetWidgets()))
+	}
+
+	session := service.GetStateManager().GetSession("key-test")
+	if err := session.AddWidget(keyed("name")); !errors.Is(err, widgets.ErrDuplicateKey) {
+		t.Errorf("session key duplicating a global key: error %v, want ErrDuplicateKey", err)
+	}
+	if err := session.AddWidget(keyed("note")); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.AddWidget(keyed("note")); !errors.Is(err, widgets.ErrDuplicateKey) {
+		t.Errorf("duplicate session key: error %v, want ErrDuplicateKey", err)
+	}
+}
+
+// TestBasePathRouting 配置路径前缀后所有接口都在前缀下提供，前缀外的地址返回404
+func TestBasePathRouting(t *testing.T) {
+	service := NewService(WithBasePath("/prefix/"))
+	button := widgets.NewButton("按钮")
+	if err := service.AddWidget(button); err != nil {
+		t.Fatal(err)
+	}
+	outer := http.NewServeMux()
+	outer.Handle("/prefix/", service.Handler())
+	outer.Handle("/prefix", service.Handler())
+	server := httptest.NewServer(outer)
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(server.URL + "/prefix/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := new(strings.Builder)
+	_, err = io.Copy(body, resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("page: status %d, %v", resp.StatusCode, err)
+	}
+	if !strings.Contains(body.String(), `const basePath = "/prefix"`) {
+		t.Errorf("page does not use the base path")
+	}
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/prefix/" {
+		t.Fatalf("session cookie = %v, want path /prefix/", cookies)
+	}
+
+	resp, err = client.Get(server.URL + "/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != "/prefix/" {
+		t.Errorf("prefix without slash: status %d, location %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	get := func(path string) int {
+		t.Helper()
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := get("/prefix/static/vega/README.md"); status != http.StatusOK {
+		t.Errorf("static file: status %d", status)
+	}
+	if status := get("/prefix/health"); status != http.StatusOK {
+		t.Errorf("health: status %d", status)
+	}
+
+	resp, err = client.PostForm(server.URL+"/prefix/event", url.Values{
+		"component_id": {button.GetID()},
+		"event_type":   {"click"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg Message
+	err = json.NewDecoder(resp.Body).Decode(&msg)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || err != nil {
+		t.Errorf("event: status %d, %v", resp.StatusCode, err)
+	}
+
+	serverURL, _ := url.Parse(server.URL + "/prefix/")
+	header := http.Header{}
+	for _, cookie := range jar.Cookies(serverURL) {
+		header.Add("Cookie", cookie.String())
+	}
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/prefix/ws", header)
+	if err != nil {
+		t.Fatalf("websocket under the prefix: %v", err)
+	}
+	conn.Close()
+
+	// 前缀外的地址，包括只有前缀开头相同的地址，都不由服务处理
+	for _, path := range []string{"/", "/ws", "/event", "/health", "/static/vega/README.md", "/prefixed/", "/other/prefix/"} {
+		if status := get(path); status != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, status)
+		}
+	}
+	if ws, resp, err := websocket.DefaultDialer.Dial(wsURL+"/ws", header); err == nil {
+		ws.Close()
+		t.Error("websocket accepted outside the prefix")
+	} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("websocket outside the prefix: %v", err)
+	}
+
+	// 直接使用处理器时同样只处理前缀下的地址
+	direct := httptest.NewServer(service.Handler())
+	defer direct.Close()
+	for path, want := range map[string]int{"/prefix/health": http.StatusOK, "/health": http.StatusNotFound, "/prefixhealth": http.StatusNotFound} {
+		resp, err := http.Get(direct.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("direct %s: status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
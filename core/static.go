@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/ptemplate"
+)
+
+// staticFile 内置的静态资源
+type staticFile struct {
+	content     []byte
+	contentType string
+	etag        string
+}
+
+// staticFiles 内置静态资源，首次请求时读取并计算ETag
+var staticFiles = sync.OnceValue(func() map[string]*staticFile {
+	files := make(map[string]*staticFile)
+	static := ptemplate.StaticFS()
+	err := fs.WalkDir(static, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(static, name)
+		if err != nil {
+			return err
+		}
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = http.DetectContentType(content)
+		}
+		sum := sha256.Sum256(content)
+		files[name] = &staticFile{
+			content:     content,
+			contentType: contentType,
+			etag:        "\"" + hex.EncodeToString(sum[:16]) + "\"",
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return files
+})
+
+// serveStatic 提供页面使用的内置静态资源，如Vega-Lite渲染器
+//
+// 资源随程序一起编译，地址不包含版本，浏览器每次使用前按ETag重新验证。
+func (s *Service) serveStatic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	file, exists := staticFiles()[strings.TrimPrefix(r.URL.Path, "/static/")]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", file.contentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("ETag", file.etag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(file.content))
+}
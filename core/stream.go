@@ -0,0 +1,128 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// streamStore 记录每个会话最近一次渲染中显示的数据流
+type streamStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]map[*widgets.Stream]widgets.Widget // 会话ID -> 显示的数据流 -> 会话中的组件实例
+}
+
+// newStreamStore 创建数据流订阅记录
+func newStreamStore() *streamStore {
+	return &streamStore{
+		sessions: make(map[string]map[*widgets.Stream]widgets.Widget),
+	}
+}
+
+// track 记录会话显示的数据流，replace为true时替换会话之前的记录（完整渲染），否则追加（局部更新）
+func (m *streamStore) track(sessionID string, streams map[*widgets.Stream]widgets.Widget, replace bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current := make(map[*widgets.Stream]widgets.Widget, len(streams))
+	if !replace {
+		for stream, widget := range m.sessions[sessionID] {
+			current[stream] = widget
+		}
+	}
+	for stream, widget := range streams {
+		current[stream] = widget
+	}
+
+	if len(current) == 0 {
+		delete(m.sessions, sessionID)
+	} else {
+		m.sessions[sessionID] = current
+	}
+}
+
+// widget 获取会话中显示数据流的组件，会话没有显示该数据流时返回false
+func (m *streamStore) widget(sessionID string, stream *widgets.Stream) (widgets.Widget, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	widget, exists := m.sessions[sessionID][stream]
+	return widget, exists
+}
+
+// delete 删除会话的记录
+func (m *streamStore) delete(sessionID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.sessions, sessionID)
+}
+
+// rename 会话ID更换后迁移记录
+func (m *streamStore) rename(oldID, newID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if streams, exists := m.sessions[oldID]; exists {
+		delete(m.sessions, oldID)
+		m.sessions[newID] = streams
+	}
+}
+
+// collectStreams 收集组件树中的数据流及其组件
+func collectStreams(roots []widgets.Widget) map[*widgets.Stream]widgets.Widget {
+	streams := make(map[*widgets.Stream]widgets.Widget)
+	widgets.Walk(roots, func(widget widgets.Widget, parent widgets.Widget) bool {
+		if streamer, ok := widget.(widgets.IStreamer); ok {
+			if stream := streamer.Stream(); stream != nil {
+				streams[stream] = widget
+			}
+		}
+		return true
+	})
+	return streams
+}
+
+// trackStreams 记录会话本次渲染显示的数据流，并让数据流通过服务发布
+func (s *Service) trackStreams(sessionID string, roots []widgets.Widget, replace bool) {
+	streams := collectStreams(roots)
+	for stream := range streams {
+		stream.Attach(s.publishStream)
+	}
+	s.streams.track(sessionID, streams, replace)
+}
+
+// publishStream 把组件的更新推送给最近一次渲染中显示该数据流的会话
+//
+// 带有增量更新时直接发送给这些会话的连接，不渲染组件、不获取会话锁。
+// 没有增量更新时为每个会话重新渲染对应的组件并通过patch消息推送；与Rerun相同，
+// 会话正在处理事件或渲染时不等待，由处理方在完成后推送完整比较的结果。
+func (s *Service) publishStream(stream *widgets.Stream, update *widgets.StreamUpdate) {
+	for _, sessionID := range s.stateManager.GetAllSessionIDs() {
+		widget, exists := s.streams.widget(sessionID, stream)
+		if !exists || !s.hub.HasSession(sessionID) {
+			continue
+		}
+		if update != nil {
+			s.hub.SendToSession(sessionID, encodeMessage(Message{
+				Type:        MessageTypeStream,
+				ComponentID: widget.GetID(),
+				Stream:      update,
+			}))
+			continue
+		}
+
+		session, exists := s.stateManager.LookupSession(sessionID)
+		if !exists {
+			continue
+		}
+		lock, ok := s.tryLockSession(session)
+		if !ok {
+			continue
+		}
+		if update := s.renderWidgetUpdate(sessionID, widget); update != nil {
+			s.hub.SendToSession(sessionID, encodeMessage(*update))
+		}
+		s.unlockSession(session, lock)
+	}
+}
@@ -0,0 +1,321 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lengzhao/streamlit-go/state"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+// uploadOverhead 上传请求中文件内容以外部分（分隔符、头部）允许的字节数
+const uploadOverhead = 1 << 20
+
+// maxUploadBytes 上传请求体允许的最大字节数，超过int64范围时取math.MaxInt64
+func maxUploadBytes(limits widgets.UploadLimits) int64 {
+	files := int64(limits.MaxFiles)
+	if files > 0 && limits.MaxFileSize > (math.MaxInt64-uploadOverhead)/files {
+		return math.MaxInt64
+	}
+	return files*limits.MaxFileSize + uploadOverhead
+}
+
+// saturatingAdd 计算非负数a+b，超过int64范围时取math.MaxInt64
+func saturatingAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// sessionUploads 单个会话的上传文件
+type sessionUploads struct {
+	dir   string                // 会话的临时目录
+	files map[string][]*os.File // 组件ID -> 该组件最近一次上传的文件
+}
+
+// uploadStore 按会话保存上传的文件，会话过期或被删除时删除其临时目录
+type uploadStore struct {
+	mutex    sync.Mutex
+	sessions map[string]*sessionUploads
+}
+
+// newUploadStore 创建上传文件存储
+func newUploadStore() *uploadStore {
+	return &uploadStore{
+		sessions: make(map[string]*sessionUploads),
+	}
+}
+
+// dir 获取会话的临时目录，不存在时创建
+func (u *uploadStore) dir(sessionID string) (string, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if uploads, exists := u.sessions[sessionID]; exists {
+		return uploads.dir, nil
+	}
+
+	dir, err := os.MkdirTemp("", "streamlit-go-upload-")
+	if err != nil {
+		return "", err
+	}
+	u.sessions[sessionID] = &sessionUploads{
+		dir:   dir,
+		files: make(map[string][]*os.File),
+	}
+	return dir, nil
+}
+
+// replace 保存组件新上传的文件并删除该组件之前上传的文件
+//
+// 调用方在会话锁内、组件绑定新文件之后调用，之前的文件在新文件保存后才删除。
+// 会话在上传过程中被释放时返回错误，新文件由调用方删除。
+func (u *uploadStore) replace(sessionID, componentID string, files []*os.File) error {
+	u.mutex.Lock()
+	uploads, exists := u.sessions[sessionID]
+	var previous []*os.File
+	if exists {
+		previous = uploads.files[componentID]
+		uploads.files[componentID] = files
+	}
+	u.mutex.Unlock()
+
+	if !exists {
+		return errors.New("session released during upload")
+	}
+	removeFiles(previous)
+	return nil
+}
+
+// delete 删除会话的所有上传文件和临时目录
+func (u *uploadStore) delete(sessionID string) {
+	u.mutex.Lock()
+	uploads, exists := u.sessions[sessionID]
+	delete(u.sessions, sessionID)
+	u.mutex.Unlock()
+
+	if exists {
+		uploads.remove()
+	}
+}
+
+// rename 会话ID更换后迁移上传文件
+func (u *uploadStore) rename(oldID, newID string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if uploads, exists := u.sessions[oldID]; exists {
+		delete(u.sessions, oldID)
+		u.sessions[newID] = uploads
+	}
+}
+
+// deleteAll 删除所有会话的上传文件，服务停止时调用
+func (u *uploadStore) deleteAll() {
+	u.mutex.Lock()
+	sessions := u.sessions
+	u.sessions = make(map[string]*sessionUploads)
+	u.mutex.Unlock()
+
+	for _, uploads := range sessions {
+		uploads.remove()
+	}
+}
+
+// remove 关闭并删除会话的所有上传文件和临时目录
+func (s *sessionUploads) remove() {
+	for _, files := range s.files {
+		removeFiles(files)
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Printf("Failed to remove upload directory: %v", err)
+	}
+}
+
+// removeFiles 关闭并删除文件
+func removeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
+
+// serveUpload 处理文件上传组件的multipart上传请求
+//
+// 目标组件由查询参数component_id指定，文件位于名为files的字段中。
+// 文件按组件的上传限制流式写入会话的临时目录，成功后以upload事件触发组件回调，
+// 响应与/event相同，为渲染更新消息。
+func (s *Service) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 获取会话对象，只接受服务端签发的会话
+	session := s.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	session.Touch()
+
+	// 接收文件前读取组件的上传限制
+	componentID := r.URL.Query().Get("component_id")
+	lock := s.lockSession(session)
+	uploader, ok := s.lookupUploader(session, componentID)
+	var limits widgets.UploadLimits
+	if ok {
+		limits = uploader.UploadLimits()
+	}
+	s.unlockSession(session, lock)
+	if !ok {
+		http.Error(w, "Upload target not found", http.StatusBadRequest)
+		return
+	}
+
+	// 文件在会话锁外接收，不阻塞该会话的其他事件和渲染
+	files, saved, err := s.receiveFiles(w, r, session.ID(), limits)
+
+	// 绑定文件、处理事件并重新渲染，与该会话的其他事件和渲染互斥
+	lock = s.lockSession(session)
+	trigger := ""
+	if err == nil {
+		// 接收期间应用可能重新运行，重新查找组件并按当前的限制检查
+		err = s.commitUpload(session, componentID, files, saved)
+	}
+	if err != nil {
+		log.Printf("Upload rejected: componentID=%s, error=%v", componentID, err)
+		if uploader, ok := s.lookupUploader(session, componentID); ok {
+			uploader.RejectFiles(err)
+		}
+	} else {
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = file.Name
+		}
+		trigger = s.handleEvent(session, componentID, "upload", strings.Join(names, ","))
+	}
+
+	// 重新渲染页面，只返回发生变化的组件
+	lock.pending.Store(false)
+	update := s.renderUpdate(session.ID(), trigger)
+	s.unlockSession(session, lock)
+	if update == nil {
+		update = &Message{Type: MessageTypePatch}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(encodeMessage(*update))
+}
+
+// lookupUploader 查找会话中的文件上传组件，调用方持有会话锁
+func (s *Service) lookupUploader(session *state.Session, componentID string) (widgets.IFileUploader, bool) {
+	target, _ := s.lookupWidget(session, componentID)
+	uploader, ok := target.(widgets.IFileUploader)
+	return uploader, ok
+}
+
+// commitUpload 检查接收的文件并绑定到组件，成功后替换组件之前上传的文件，调用方持有会话锁
+//
+// 失败时删除本次接收的文件，组件和之前上传的文件保持不变。
+func (s *Service) commitUpload(session *state.Session, componentID string, files []*widgets.UploadedFile, saved []*os.File) error {
+	uploader, ok := s.lookupUploader(session, componentID)
+	err := errors.New("上传组件已不存在，请刷新页面")
+	if ok {
+		err = uploader.UploadLimits().CheckFiles(files)
+	}
+	if err == nil {
+		err = uploader.BindFiles(files)
+	}
+	if err != nil {
+		removeFiles(saved)
+		return err
+	}
+	if err := s.uploads.replace(session.ID(), componentID, saved); err != nil {
+		removeFiles(saved)
+		return errors.New("会话已失效，请刷新页面")
+	}
+	return nil
+}
+
+// receiveFiles 按上传限制接收请求中的文件并保存到会话的临时目录，返回文件和保存它们的临时文件
+//
+// 任一文件不符合限制时删除本次已保存的文件并返回错误，组件之前上传的文件保持不变。
+func (s *Service) receiveFiles(w http.ResponseWriter, r *http.Request, sessionID string, limits widgets.UploadLimits) ([]*widgets.UploadedFile, []*os.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes(limits))
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, errors.New("无效的上传请求")
+	}
+
+	dir, err := s.uploads.dir(sessionID)
+	if err != nil {
+		log.Printf("Failed to create upload directory: %v", err)
+		return nil, nil, errors.New("上传失败，请稍后重试")
+	}
+
+	var saved []*os.File
+	var uploaded []*widgets.UploadedFile
+	fail := func(err error) ([]*widgets.UploadedFile, []*os.File, error) {
+		removeFiles(saved)
+		return nil, nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return fail(errors.New("上传内容超过大小限制"))
+			}
+			return fail(errors.New("无效的上传请求"))
+		}
+
+		name := part.FileName()
+		if part.FormName() != "files" || name == "" {
+			part.Close()
+			continue
+		}
+		if err := limits.CheckCount(len(saved) + 1); err != nil {
+			return fail(err)
+		}
+		if err := limits.CheckName(name); err != nil {
+			return fail(err)
+		}
+
+		file, err := os.CreateTemp(dir, "upload-*")
+		if err != nil {
+			log.Printf("Failed to create upload file: %v", err)
+			return fail(errors.New("上传失败，请稍后重试"))
+		}
+		saved = append(saved, file)
+
+		// 多读取一个字节用于判断是否超过大小限制
+		size, err := io.Copy(file, io.LimitReader(part, saturatingAdd(limits.MaxFileSize, 1)))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return fail(errors.New("上传内容超过大小限制"))
+			}
+			return fail(fmt.Errorf("上传失败: %s", name))
+		}
+		if err := limits.CheckSize(name, size); err != nil {
+			return fail(err)
+		}
+
+		uploaded = append(uploaded, widgets.NewUploadedFile(name, part.Header.Get("Content-Type"), size, file))
+	}
+
+	return uploaded, saved, nil
+}
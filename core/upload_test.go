@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+func TestMaxUploadBytes(t *testing.T) {
+	tests := []struct {
+		files int
+		size  int64
+		want  int64
+	}{
+		{1, 10, 10 + uploadOverhead},
+		{3, 1 << 20, 3<<20 + uploadOverhead},
+		{2, math.MaxInt64 / 2, math.MaxInt64},
+		{1, math.MaxInt64, math.MaxInt64},
+		{math.MaxInt32, math.MaxInt64 / 4, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got := maxUploadBytes(widgets.UploadLimits{MaxFiles: tt.files, MaxFileSize: tt.size})
+		if got != tt.want {
+			t.Errorf("maxUploadBytes(%d files, %d bytes) = %d, want %d", tt.files, tt.size, got, tt.want)
+		}
+	}
+
+	if got := saturatingAdd(math.MaxInt64, 1); got != math.MaxInt64 {
+		t.Errorf("saturatingAdd(MaxInt64, 1) = %d", got)
+	}
+}
+
+// uploadFiles 以multipart请求上传文件，files为文件名到内容的有序列表
+func uploadFiles(t *testing.T, client *http.Client, target string, files ...[2]string) int {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile("files", file[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, file[1])
+	}
+	writer.Close()
+	resp, err := client.Post(target, writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// TestUploadReplacesFilesAfterCommit 新文件绑定到组件后才删除之前上传的文件，被拒绝的上传不影响之前的文件
+func TestUploadReplacesFilesAfterCommit(t *testing.T) {
+	service := NewService()
+	uploader := widgets.NewFileUploader("文件", false)
+	uploader.SetExtensions(".csv")
+	if err := service.AddWidget(uploader); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+	client := newSessionClient(t, server)
+	sessionID := service.GetStateManager().GetAllSessionIDs()[0]
+	session, _ := service.GetStateManager().LookupSession(sessionID)
+	target := server.URL + "/upload?component_id=" + uploader.GetID()
+
+	// stored 返回组件当前保存的临时文件路径和组件显示的文件
+	stored := func() ([]string, []*widgets.UploadedFile, string) {
+		lock := service.lockSession(session)
+		defer service.unlockSession(session, lock)
+		local := session.Local(uploader).(*widgets.FileUploaderWidget)
+		service.uploads.mutex.Lock()
+		defer service.uploads.mutex.Unlock()
+		var paths []string
+		if uploads, exists := service.uploads.sessions[sessionID]; exists {
+			for _, file := range uploads.files[uploader.GetID()] {
+				paths = append(paths, file.Name())
+			}
+		}
+		return paths, local.GetFiles(), local.GetError()
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	if status := uploadFiles(t, client, target, [2]string{"a.csv", "1,2"}); status != http.StatusOK {
+		t.Fatalf("upload: status %d", status)
+	}
+	first, files, _ := stored()
+	if len(first) != 1 || len(files) != 1 || files[0].Name != "a.csv" {
+		t.Fatalf("first upload: paths %v, files %v", first, files)
+	}
+
+	// 不符合限制的上传被拒绝，之前的文件保留在磁盘上并且仍然绑定在组件上
+	for _, rejected := range [][][2]string{
+		{{"b.txt", "text"}},
+		{{"b.csv", "1"}, {"c.csv", "2"}},
+	} {
+		uploadFiles(t, client, target, rejected...)
+		paths, files, errText := stored()
+		if errText == "" || len(files) != 1 || files[0].Name != "a.csv" || len(paths) != 1 || paths[0] != first[0] || !exists(first[0]) {
+			t.Fatalf("rejected upload %v changed the files: paths %v, files %v, error %q", rejected, paths, files, errText)
+		}
+	}
+
+	// 接收后组件的限制已变化时（例如应用重新运行），按当前的限制检查并删除新文件
+	dir, err := service.uploads.dir(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := service.lockSession(session)
+	err = service.commitUpload(session, uploader.GetID(), []*widgets.UploadedFile{
+		widgets.NewUploadedFile("d.csv", "text/csv", 1, extra),
+		widgets.NewUploadedFile("e.csv", "text/csv", 1, extra),
+	}, []*os.File{extra})
+	service.unlockSession(session, lock)
+	if err == nil || exists(extra.Name()) || !exists(first[0]) {
+		t.Fatalf("commit over the limit: error %v, new file kept %v, old file kept %v", err, exists(extra.Name()), exists(first[0]))
+	}
+
+	if status := uploadFiles(t, client, target, [2]string{"f.csv", "3,4"}); status != http.StatusOK {
+		t.Fatalf("replacement upload: status %d", status)
+	}
+	paths, files, errText := stored()
+	if len(paths) != 1 || paths[0] == first[0] || len(files) != 1 || files[0].Name != "f.csv" || errText != "" {
+		t.Fatalf("replacement: paths %v, files %v, error %q", paths, files, errText)
+	}
+	if exists(first[0]) || !exists(paths[0]) {
+		t.Errorf("old file kept %v, new file kept %v", exists(first[0]), exists(paths[0]))
+	}
+	content := make([]byte, 3)
+	if _, err := files[0].ReadAt(content, 0); err != nil || string(content) != "3,4" {
+		t.Errorf("content = %q, %v", content, err)
+	}
+
+	if status := uploadFiles(t, client, server.URL+"/upload?component_id=missing", [2]string{"g.csv", "5"}); status != http.StatusBadRequest {
+		t.Errorf("missing target: status %d, want 400", status)
+	}
+}
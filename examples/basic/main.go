@@ -1,11 +1,20 @@
 package main
 
 import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
 	"log"
+	"math"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/lengzhao/streamlit-go/charts"
 	"github.com/lengzhao/streamlit-go/core"
 	"github.com/lengzhao/streamlit-go/widgets"
 )
@@ -28,6 +37,16 @@ func main() {
 	st.AddWidget(widgets.NewWrite(42))
 	st.AddWidget(widgets.NewWrite(3.14159))
 	st.AddWidget(widgets.NewWrite(true))
+	st.AddWidget(widgets.NewMarkdown(`Markdown组件支持 **粗体**、*斜体*、~~删除线~~、` + "`代码`" + ` 和 :blue[彩色文本] :sparkles:
+
+| 功能 | 状态 |
+|------|:----:|
+| 表格 | :white_check_mark: |
+| <script>脚本</script> | 作为文本显示 |
+
+- [x] 任务列表
+- [ ] [链接](https://github.com/lengzhao/streamlit-go)`))
+	st.AddWidget(widgets.NewCaption("说明文字以较小的灰色字体显示"))
 
 	// 指标组件
 	st.AddWidget(widgets.NewSubheader("📊 指标展示"))
@@ -50,6 +69,71 @@ func main() {
 	data := []string{"苹果", "香蕉", "橙子"}
 	st.AddWidget(widgets.NewTable(data))
 
+	// 带表头的二维数据
+	stock := widgets.NewTable(widgets.Rows{
+		Headers: []string{"水果", "库存", "单价"},
+		Data: [][]interface{}{
+			{"苹果", 120, 6.5},
+			{"香蕉", 80, 3.2},
+			{"橙子", 45, 5.8},
+		},
+	})
+	stock.SetColumns(widgets.TableColumn{Name: "单价", Format: "¥%.2f"})
+	st.AddWidget(stock)
+
+	// 结构体切片，列设置写在st标签中
+	type release struct {
+		Version string    `st:"label=版本,width=100"`
+		Date    time.Time `st:"label=发布日期,format=2006-01-02"`
+		Commits int       `st:"label=提交数"`
+		Notes   string    `st:"label=说明"`
+		Hash    string    `st:"hidden"`
+	}
+	releases := []release{
+		{"0.1.0", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), 86, "首个版本", "a1b2c3d"},
+		{"0.2.0", time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local), 142, "增加表单和布局组件", "e4f5a6b"},
+		{"0.3.0", time.Date(2024, 9, 30, 0, 0, 0, 0, time.Local), 203, "增加图表组件", "c7d8e9f"},
+	}
+	st.AddWidget(widgets.NewDataFrame(releases))
+
+	// 大数据量的数据框：排序、搜索、筛选和翻页在服务端执行，只发送当前页
+	type sale struct {
+		ID       int       `st:"label=订单号,width=80"`
+		Region   string    `st:"label=地区"`
+		Product  string    `st:"label=产品"`
+		Quantity int       `st:"label=数量"`
+		Amount   float64   `st:"label=金额,format=%.2f"`
+		Date     time.Time `st:"label=日期,format=2006-01-02"`
+	}
+	regions := []string{"华东", "华南", "华北", "西南"}
+	products := []string{"苹果", "香蕉", "橙子", "葡萄", "西瓜"}
+	saleRecords := make([]sale, 0, 20000)
+	for i := 0; i < 20000; i++ {
+		quantity := 1 + (i*7)%50
+		saleRecords = append(saleRecords, sale{
+			ID:       100000 + i,
+			Region:   regions[i%len(regions)],
+			Product:  products[(i/3)%len(products)],
+			Quantity: quantity,
+			Amount:   float64(quantity) * (2.5 + float64(i%13)/2),
+			Date:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local).AddDate(0, 0, i%366),
+		})
+	}
+	salesFrame := widgets.NewDataFrame(saleRecords)
+	salesFrame.SetPageSize(50)
+	salesFrame.SetFilterable(true)
+	salesFrame.SetSelectionMode(widgets.MultiRow)
+	salesSummary := widgets.NewText("未选择订单")
+	salesFrame.OnSelect(func(session widgets.ISession, records []interface{}) {
+		total := 0.0
+		for _, record := range records {
+			total += record.(sale).Amount
+		}
+		widgets.For(session, salesSummary).SetText(fmt.Sprintf("已选择 %d 个订单，合计金额 %.2f", len(records), total))
+	})
+	st.AddWidget(salesFrame)
+	st.AddWidget(salesSummary)
+
 	// Map数据
 	mapData := map[string]interface{}{
 		"名称": "Streamlit Go",
@@ -58,6 +142,103 @@ func main() {
 	}
 	st.AddWidget(widgets.NewDataFrame(mapData))
 
+	// 图表组件，在服务端渲染为SVG
+	st.AddWidget(widgets.NewSubheader("📉 图表组件"))
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	times := make([]time.Time, 0)
+	cpu := make([]float64, 0)
+	memory := make([]float64, 0)
+	for i := 0; i < 120; i++ {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+		cpu = append(cpu, 50+30*math.Sin(float64(i)/10))
+		memory = append(memory, 40+float64(i)/4)
+	}
+	usage := charts.NewLineChart(charts.NewTimeSeries("CPU", times, cpu), charts.NewTimeSeries("内存", times, memory))
+	usage.SetTitle("资源使用率")
+	usage.SetYAxis(charts.Axis{Title: "%"})
+	st.AddWidget(usage)
+
+	// 实时图表，追加后只重新渲染并推送这一个图表
+	live := charts.NewAreaChart(charts.NewTimeSeries("请求数", nil, nil))
+	live.SetTitle("实时请求数（最近5分钟）")
+	live.SetWindow(0, 5*time.Minute)
+	st.AddWidget(live)
+	go func() {
+		for now := range time.Tick(time.Second) {
+			live.Append(charts.At(now, 100+50*math.Sin(float64(now.Unix())/30)))
+		}
+	}()
+
+	type monthly struct {
+		Month   string
+		Revenue float64 `json:"收入"`
+		Cost    float64 `json:"成本"`
+	}
+	sales := charts.NewBarChart()
+	if err := sales.SetData([]monthly{{"1月", 120, 80}, {"2月", 150, 95}, {"3月", 90, 70}, {"4月", 180, 110}}, "Month"); err != nil {
+		log.Fatal(err)
+	}
+	sales.SetTitle("月度收支")
+	st.AddWidget(sales)
+
+	// Vega-Lite图表，由内置的渲染器在浏览器中绘制，拖动选择的范围发送到服务端
+	type order struct {
+		Region string  `json:"region"`
+		Amount float64 `json:"amount"`
+		Profit float64 `json:"profit"`
+	}
+	orders := make([]order, 0)
+	for i, region := range []string{"华东", "华北", "华南"} {
+		for j := 0; j < 20; j++ {
+			amount := float64(20 + (j*37+i*11)%80)
+			orders = append(orders, order{region, amount, amount*0.3 - float64((j*13)%15)})
+		}
+	}
+	scatter, err := charts.NewVegaChart(`{
+		"title": "订单金额与利润（拖动选择）",
+		"params": [{"name": "brush", "select": "interval"}],
+		"mark": "point",
+		"encoding": {
+			"x": {"field": "amount", "type": "quantitative", "title": "金额"},
+			"y": {"field": "profit", "type": "quantitative", "title": "利润"},
+			"color": {"condition": {"param": "brush", "field": "region", "type": "nominal", "title": "地区"}, "value": "lightgray"},
+			"tooltip": [{"field": "region"}, {"field": "amount"}, {"field": "profit"}]
+		}
+	}`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := scatter.SetData(orders); err != nil {
+		log.Fatal(err)
+	}
+	brushText := widgets.NewText("拖动选择一个范围")
+	scatter.OnSelect(func(session widgets.ISession, selection charts.VegaSelection) {
+		text := widgets.For(session, brushText)
+		if selection.Empty() {
+			text.SetText("拖动选择一个范围")
+			return
+		}
+		text.SetText(fmt.Sprintf("金额 %v，利润 %v", selection.Ranges["amount"], selection.Ranges["profit"]))
+	})
+	st.AddWidget(scatter)
+	st.AddWidget(brushText)
+
+	// 媒体组件，图片内容通过/media/路由提供，不内联到页面中
+	st.AddWidget(widgets.NewSubheader("🖼️ 媒体组件"))
+	gradient := image.NewRGBA(image.Rect(0, 0, 1200, 300))
+	for x := 0; x < 1200; x++ {
+		for y := 0; y < 300; y++ {
+			gradient.Set(x, y, color.RGBA{R: uint8(x * 255 / 1200), G: uint8(y * 255 / 300), B: 200, A: 255})
+		}
+	}
+	picture, err := widgets.NewImage(gradient)
+	if err != nil {
+		log.Fatal(err)
+	}
+	picture.SetWidth(400)
+	picture.SetCaption("服务端生成的渐变图，点击查看原图")
+	st.AddWidget(picture)
+
 	// 布局组件
 	st.AddWidget(widgets.NewSubheader("📏 布局组件"))
 
@@ -71,6 +252,128 @@ func main() {
 	expander.AddChild(expanderText)
 	st.AddWidget(expander)
 
+	// 选择组件
+	st.AddWidget(widgets.NewSubheader("☑️ 选择组件"))
+	selection := widgets.NewText("请选择")
+
+	agree := widgets.NewCheckbox("我已阅读并同意", false)
+	st.AddWidget(agree)
+	st.AddWidget(widgets.NewToggle("深色模式", false))
+
+	size := widgets.NewRadio("尺寸", []string{"S", "M", "L"}, 1)
+	size.SetHorizontal(true)
+	st.AddWidget(size)
+
+	type city struct {
+		Name string
+		Code string
+	}
+	cities := widgets.NewSelectbox("城市", []city{{"北京", "BJ"}, {"上海", "SH"}, {"深圳", "SZ"}}, -1)
+	cities.SetFormatFunc(func(c city) string { return c.Name + " (" + c.Code + ")" })
+	cities.OnChange(func(session widgets.ISession, event string, value string) {
+		if c, ok := widgets.For(session, cities).GetValue(); ok {
+			widgets.For(session, selection).SetText("已选择城市: " + c.Name)
+		}
+	})
+	st.AddWidget(cities)
+
+	fruits := widgets.NewMultiselect("水果", []string{"苹果", "香蕉", "橙子"}, 0)
+	fruits.OnChange(func(session widgets.ISession, event string, value string) {
+		widgets.For(session, selection).SetText(fmt.Sprintf("已选择水果: %v", widgets.For(session, fruits).GetValue()))
+	})
+	st.AddWidget(fruits)
+	st.AddWidget(selection)
+
+	// 滑块组件，松开滑块后才发送事件
+	st.AddWidget(widgets.NewSubheader("🎚️ 滑块组件"))
+	st.AddWidget(widgets.NewSlider("温度", 0.0, 1.0, 0.7))
+	st.AddWidget(widgets.NewRangeSlider("价格区间", 0, 1000, 200, 800))
+	today := time.Now().Truncate(24 * time.Hour)
+	st.AddWidget(widgets.NewTimeSlider("日期", today.AddDate(0, 0, -30), today, today.AddDate(0, 0, -7), 24*time.Hour))
+	st.AddWidget(widgets.NewOptionSlider("尺码", []string{"XS", "S", "M", "L", "XL"}, 2))
+
+	// 表单组件，字段在点击提交按钮时一次发送
+	st.AddWidget(widgets.NewSubheader("📋 表单组件"))
+	form := widgets.NewForm(true)
+	username := widgets.NewTextInput("用户名", "")
+	email := widgets.NewTextInput("邮箱", "")
+	subscribe := widgets.NewCheckbox("订阅通知", true)
+	form.AddChild(username)
+	form.AddChild(email)
+	form.AddChild(subscribe)
+	form.AddChild(widgets.NewFormSubmitButton("注册"))
+	form.SetClearOnSubmit(true)
+	formResult := widgets.NewText("")
+	form.OnSubmit(func(session widgets.ISession, values widgets.FormValues) error {
+		name, _ := values.Value(username)
+		mail, _ := values.Value(email)
+		if name == "" || !strings.Contains(mail, "@") {
+			return fmt.Errorf("请填写用户名和有效的邮箱")
+		}
+		widgets.For(session, formResult).SetText(fmt.Sprintf("注册成功: %s <%s>，订阅: %v", name, mail, widgets.For(session, subscribe).GetValue()))
+		return nil
+	})
+	st.AddWidget(form)
+	st.AddWidget(formResult)
+
+	// 结构体表单，字段和校验规则来自st标签
+	type profile struct {
+		Name  string    `st:"label=昵称,required,max=20"`
+		Age   int       `st:"label=年龄,min=0,max=150"`
+		Level string    `st:"label=级别,options=初级|中级|高级"`
+		Since time.Time `st:"label=加入日期"`
+	}
+	settings := &profile{Age: 18, Level: "初级", Since: today}
+	structForm, err := widgets.NewStructForm(settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	structForm.SetSubmitLabel("保存资料")
+	profileResult := widgets.NewText("")
+	structForm.OnSubmit(func(session widgets.ISession, values widgets.FormValues) error {
+		// 结构体由所有会话共享，读取时加锁
+		structForm.Lock()
+		defer structForm.Unlock()
+		widgets.For(session, profileResult).SetText(fmt.Sprintf("已保存: %s, %d岁, %s, %s",
+			settings.Name, settings.Age, settings.Level, settings.Since.Format("2006-01-02")))
+		return nil
+	})
+	st.AddWidget(structForm)
+	st.AddWidget(profileResult)
+
+	// 文件上传组件，文件保存在会话的临时目录中，会话过期后删除
+	st.AddWidget(widgets.NewSubheader("📁 文件上传"))
+	uploader := widgets.NewFileUploader("上传CSV文件", true)
+	uploader.SetExtensions(".csv")
+	uploader.SetMaxFileSize(10 << 20)
+	uploadResult := widgets.NewText("")
+	uploader.OnChange(func(session widgets.ISession, event string, value string) {
+		summary := make([]string, 0)
+		for _, file := range widgets.For(session, uploader).GetFiles() {
+			records, err := csv.NewReader(file.Reader()).ReadAll()
+			if err != nil {
+				summary = append(summary, fmt.Sprintf("%s: 解析失败 %v", file.Name, err))
+				continue
+			}
+			summary = append(summary, fmt.Sprintf("%s: %d 行", file.Name, len(records)))
+		}
+		widgets.For(session, uploadResult).SetText(strings.Join(summary, "; "))
+	})
+	st.AddWidget(uploader)
+	st.AddWidget(uploadResult)
+
+	// 下载按钮，内容在下载时生成并直接写入响应
+	export := widgets.NewDownloadButtonFunc("下载示例CSV", "示例.csv", func(session widgets.ISession, w io.Writer) error {
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"序号", "时间"})
+		for i := 1; i <= 100; i++ {
+			writer.Write([]string{fmt.Sprint(i), time.Now().Add(time.Duration(i) * time.Minute).Format(time.RFC3339)})
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	st.AddWidget(export)
+
 	// 会话特定Widgets示例
 	st.AddWidget(widgets.NewSubheader("👥 会话特定Widgets示例"))
 	st.Text("以下组件演示了如何为不同用户创建独立的Widgets")
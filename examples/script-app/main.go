@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lengzhao/streamlit-go/core"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+func main() {
+	// 创建服务实例
+	service := core.NewService(
+		core.WithTitle("脚本式应用示例"),
+		core.WithPort(8507),
+	)
+
+	// 每次交互时应用函数都会为当前会话从头运行一次，输入组件直接返回当前值
+	service.SetApp(func(st *core.Context) {
+		st.Title("🧮 脚本式应用")
+
+		name := st.TextInput("姓名", "")
+		if st.Button("问候") {
+			if name == "" {
+				st.Text("请先输入姓名")
+			} else {
+				st.Text(fmt.Sprintf("你好, %s!", name))
+			}
+		}
+
+		st.Header("计算器", true)
+		cols := st.Columns(1, 1)
+		a := cols[0].NumberInput("A", 1)
+		b := cols[1].NumberInput("B", 2)
+		st.Metric("A + B", a+b)
+
+		// 选择和滑块组件以泛型函数形式提供
+		op, _ := core.Selectbox(st, "运算", []string{"相加", "相乘"}, 0)
+		scale := core.Slider(st, "倍数", 1, 10, 1)
+		if op == "相乘" {
+			st.Text(fmt.Sprintf("A × B × %d = %g", scale, a*b*float64(scale)))
+		} else {
+			st.Text(fmt.Sprintf("(A + B) × %d = %g", scale, (a+b)*float64(scale)))
+		}
+
+		// 计数器保存在会话中，点击按钮后立即生效
+		if st.Key("increment").Button("+1") {
+			widgets.UpdateSessionValue(st.Session(), "count", func(n int) int { return n + 1 })
+		}
+		count := widgets.SessionValueOr(st.Session(), "count", 0)
+		st.Text(fmt.Sprintf("已点击 %d 次", count))
+
+		details := st.Expander("详细信息", false)
+		details.Text("会话ID: " + st.Session().ID())
+	})
+
+	log.Println("服务创建成功")
+	log.Println("请在浏览器中访问 http://localhost:8507 查看应用")
+
+	// 设置信号处理，优雅关闭
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// 在单独的goroutine中启动服务
+	go func() {
+		if err := service.Start(); err != nil {
+			log.Printf("服务器错误: %v", err)
+		}
+	}()
+
+	// 等待中断信号
+	<-sigChan
+	log.Println("\n收到中断信号，关闭中...")
+
+	// 优雅关闭
+	if err := service.Stop(); err != nil {
+		log.Printf("关闭时错误: %v", err)
+	}
+
+	log.Println("服务已成功停止")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lengzhao/streamlit-go/core"
+	"github.com/lengzhao/streamlit-go/widgets"
+)
+
+func main() {
+	// 创建服务实例
+	service := core.NewService(
+		core.WithTitle("服务端推送示例"),
+		core.WithPort(8506),
+	)
+
+	service.AddWidget(widgets.NewTitle("📡 服务端推送"))
+
+	// 全局时钟，由后台goroutine定时刷新并广播给所有会话
+	clock := widgets.NewText("当前时间: " + time.Now().Format("15:04:05"))
+	service.AddWidget(clock)
+
+	// 长时间计算，完成后只刷新发起计算的会话
+	computeButton := widgets.NewButton("开始计算")
+	computeButton.OnChange(func(session widgets.ISession, event string, value string) {
+		status := widgets.NewText("计算中...")
+		session.AddWidget(status)
+
+		go func() {
+			time.Sleep(3 * time.Second)
+			status.SetText(fmt.Sprintf("计算完成: %s", time.Now().Format("15:04:05")))
+			session.Rerun()
+		}()
+	})
+	service.AddWidget(computeButton)
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			clock.SetText("当前时间: " + now.Format("15:04:05"))
+			service.Broadcast()
+		}
+	}()
+
+	log.Println("服务创建成功")
+	log.Println("请在浏览器中访问 http://localhost:8506 查看应用")
+
+	// 设置信号处理，优雅关闭
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// 在单独的goroutine中启动服务
+	go func() {
+		if err := service.Start(); err != nil {
+			log.Printf("服务器错误: %v", err)
+		}
+	}()
+
+	// 等待中断信号
+	<-sigChan
+	log.Println("\n收到中断信号，关闭中...")
+
+	// 优雅关闭
+	if err := service.Stop(); err != nil {
+		log.Printf("关闭时错误: %v", err)
+	}
+
+	log.Println("服务已成功停止")
+}
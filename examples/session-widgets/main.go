@@ -26,11 +26,13 @@ func main() {
 	// 为用户1创建按钮
 	user1Button := widgets.NewButton("用户1按钮")
 
-	user1Count := 0
 	user1Button.OnChange(func(session widgets.ISession, event string, value string) {
 		log.Println("Button clicked by user:", session.ID())
-		user1Count++
-		stat := widgets.NewText("用户计数器: " + fmt.Sprintf("%d", user1Count))
+		// 计数器保存在会话状态中，每个用户独立计数
+		count := widgets.UpdateSessionValue(session, "click_count", func(count int) int {
+			return count + 1
+		})
+		stat := widgets.NewText("用户计数器: " + fmt.Sprintf("%d", count))
 		session.AddWidget(stat)
 	})
 	service.AddWidget(user1Button)
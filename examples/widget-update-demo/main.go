@@ -32,8 +32,8 @@ func main() {
 	// 创建更新按钮
 	updateButton := widgets.NewButton("更新文本")
 	updateButton.OnChange(func(session widgets.ISession, event string, value string) {
-		// 更新文本组件的内容
-		updateText.SetText("文本已更新！当前时间戳")
+		// 更新文本组件在当前会话中的副本，其他用户看到的内容不受影响
+		widgets.For(session, updateText).SetText("文本已更新！当前时间戳")
 		// 注意：组件更新通过服务端重新渲染所有组件实现，无需显式调用更新方法
 	})
 	service.AddWidget(updateButton)
@@ -41,7 +41,8 @@ func main() {
 	// 创建删除按钮
 	deleteButton := widgets.NewButton("删除文本")
 	deleteButton.OnChange(func(session widgets.ISession, event string, value string) {
-		session.DeleteWidget(deleteText.GetID())
+		// 只对当前会话隐藏全局组件
+		widgets.For(session, deleteText).SetVisible(false)
 	})
 	service.AddWidget(deleteButton)
 
@@ -1,3 +1,5 @@
 module github.com/lengzhao/streamlit-go
 
 go 1.24.2
+
+require github.com/gorilla/websocket v1.5.3
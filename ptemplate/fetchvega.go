@@ -0,0 +1,91 @@
+//go:build ignore
+
+// fetchvega 从npm下载Vega、Vega-Lite和vega-embed的发布包，把浏览器使用的脚本和许可证复制到static/vega
+//
+// 在ptemplate目录中运行 go generate，下载的文件随程序编译，页面运行时不访问外部网络。
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// vegaPackages 页面使用的发布包和版本，vega-embed 6需要Vega 5和Vega-Lite 5
+var vegaPackages = []struct {
+	name    string
+	version string
+}{
+	{"vega", "5.30.0"},
+	{"vega-lite", "5.21.0"},
+	{"vega-embed", "6.26.0"},
+}
+
+func main() {
+	dir := filepath.Join("static", "vega")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatal(err)
+	}
+	client := &http.Client{Timeout: 2 * time.Minute}
+	for _, pkg := range vegaPackages {
+		files := map[string]string{
+			"package/build/" + pkg.name + ".min.js": pkg.name + ".min.js",
+			"package/LICENSE":                       pkg.name + ".LICENSE",
+		}
+		url := fmt.Sprintf("https://registry.npmjs.org/%s/-/%s-%s.tgz", pkg.name, pkg.name, pkg.version)
+		if err := fetch(client, url, dir, files); err != nil {
+			log.Fatalf("%s@%s: %v", pkg.name, pkg.version, err)
+		}
+		log.Printf("%s@%s", pkg.name, pkg.version)
+	}
+}
+
+// fetch 下载npm发布包，把files中的文件（包内路径 -> 文件名）写入dir
+func fetch(client *http.Client, url string, dir string, files map[string]string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	archive := tar.NewReader(gz)
+	found := 0
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, exists := files[header.Name]
+		if !exists {
+			continue
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+		found++
+	}
+	if found != len(files) {
+		return fmt.Errorf("package %s is missing expected files", url)
+	}
+	return nil
+}
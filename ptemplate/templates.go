@@ -3,12 +3,27 @@ package ptemplate
 import (
 	"embed"
 	"html/template"
+	"io/fs"
 )
 
 //go:embed page.html
 var pageTemplateFS embed.FS
 
+//go:generate go run fetchvega.go
+
+//go:embed static
+var staticFS embed.FS
+
 // GetPageTemplate 获取页面模板
 func GetPageTemplate() (*template.Template, error) {
 	return template.ParseFS(pageTemplateFS, "page.html")
 }
+
+// StaticFS 获取页面使用的静态资源，如static/vega中的Vega、Vega-Lite和vega-embed脚本
+func StaticFS() fs.FS {
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	return static
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"sync"
 	"time"
+
+	"github.com/lengzhao/streamlit-go/widgets"
 )
 
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("session not found")
+
 // Manager 状态管理器，管理所有会话
 type Manager struct {
 	sessions         map[string]*Session // 会话映射表
@@ -17,6 +23,12 @@ type Manager struct {
 	cleanupCtx       context.Context     // 清理任务上下文
 	cleanupCancel    context.CancelFunc  // 清理任务取消函数
 	cleanupWaitGroup sync.WaitGroup      // 等待清理任务完成
+	lifecycleMutex   sync.Mutex          // 保护清理任务的启动和停止
+	rerunHandler     func(session *Session)
+	expireHandlers   []func(session *Session)
+	rotateHandlers   []func(session *Session, oldID string)
+	globalWidgets    func() []widgets.Widget
+	handlerMutex     sync.RWMutex
 }
 
 // NewManager 创建新的状态管理器
@@ -50,33 +62,182 @@ func (m *Manager) GetSession(sessionID string) *Session {
 	}
 
 	session = NewSession(sessionID)
+	session.manager = m
 	m.sessions[sessionID] = session
 	return session
 }
 
-// DeleteSession 删除指定会话
-func (m *Manager) DeleteSession(sessionID string) {
+// SetRerunHandler 设置会话重新渲染处理函数，由Session.Rerun调用
+func (m *Manager) SetRerunHandler(handler func(session *Session)) {
+	m.handlerMutex.Lock()
+	defer m.handlerMutex.Unlock()
+	m.rerunHandler = handler
+}
+
+// SetGlobalWidgets 设置全局组件来源，会话据此为嵌套的全局组件创建私有副本
+func (m *Manager) SetGlobalWidgets(provider func() []widgets.Widget) {
+	m.handlerMutex.Lock()
+	defer m.handlerMutex.Unlock()
+	m.globalWidgets = provider
+}
+
+// getGlobalWidgets 获取全局组件列表
+func (m *Manager) getGlobalWidgets() []widgets.Widget {
+	m.handlerMutex.RLock()
+	provider := m.globalWidgets
+	m.handlerMutex.RUnlock()
+
+	if provider == nil {
+		return nil
+	}
+	return provider()
+}
+
+// rerun 调用重新渲染处理函数
+func (m *Manager) rerun(session *Session) {
+	m.handlerMutex.RLock()
+	handler := m.rerunHandler
+	m.handlerMutex.RUnlock()
+
+	if handler != nil {
+		handler(session)
+	}
+}
+
+// OnSessionExpired 注册会话删除或过期时的处理函数，用于释放与会话关联的资源
+func (m *Manager) OnSessionExpired(handler func(session *Session)) {
+	m.handlerMutex.Lock()
+	defer m.handlerMutex.Unlock()
+	m.expireHandlers = append(m.expireHandlers, handler)
+}
+
+// expired 调用会话过期处理函数，调用时不能持有m.mutex
+func (m *Manager) expired(sessions []*Session) {
+	m.handlerMutex.RLock()
+	handlers := make([]func(session *Session), len(m.expireHandlers))
+	copy(handlers, m.expireHandlers)
+	m.handlerMutex.RUnlock()
+
+	for _, session := range sessions {
+		for _, handler := range handlers {
+			handler(session)
+		}
+	}
+}
+
+// LookupSession 获取已存在的会话，不存在时不会创建
+func (m *Manager) LookupSession(sessionID string) (*Session, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	return session, exists
+}
+
+// CreateSession 使用随机生成的会话ID创建新会话
+func (m *Manager) CreateSession() (*Session, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	sessionID, err := m.newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := NewSession(sessionID)
+	session.manager = m
+	m.sessions[sessionID] = session
+	return session, nil
+}
+
+// RotateSession 为会话更换新的随机ID，会话数据保持不变，旧ID随即失效
+func (m *Manager) RotateSession(oldID string) (*Session, error) {
+	m.mutex.Lock()
+	session, exists := m.sessions[oldID]
+	if !exists {
+		m.mutex.Unlock()
+		return nil, ErrSessionNotFound
+	}
+
+	newID, err := m.newSessionID()
+	if err != nil {
+		m.mutex.Unlock()
+		return nil, err
+	}
+
+	delete(m.sessions, oldID)
+	session.setID(newID)
+	m.sessions[newID] = session
+	m.mutex.Unlock()
+
+	m.handlerMutex.RLock()
+	handlers := make([]func(session *Session, oldID string), len(m.rotateHandlers))
+	copy(handlers, m.rotateHandlers)
+	m.handlerMutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(session, oldID)
+	}
+	return session, nil
+}
+
+// OnSessionRotated 注册会话ID更换后的处理函数，用于迁移按会话ID索引的资源
+func (m *Manager) OnSessionRotated(handler func(session *Session, oldID string)) {
+	m.handlerMutex.Lock()
+	defer m.handlerMutex.Unlock()
+	m.rotateHandlers = append(m.rotateHandlers, handler)
+}
+
+// newSessionID 生成未被使用的会话ID，调用时需持有m.mutex
+func (m *Manager) newSessionID() (string, error) {
+	for {
+		sessionID, err := GenerateSessionID()
+		if err != nil {
+			return "", err
+		}
+		if _, exists := m.sessions[sessionID]; !exists {
+			return sessionID, nil
+		}
+	}
+}
+
+// DeleteSession 删除指定会话
+func (m *Manager) DeleteSession(sessionID string) {
+	m.mutex.Lock()
+	session, exists := m.sessions[sessionID]
 	delete(m.sessions, sessionID)
+	m.mutex.Unlock()
+
+	if exists {
+		m.expired([]*Session{session})
+	}
 }
 
 // CleanupExpiredSessions 清理过期会话
 func (m *Manager) CleanupExpiredSessions() {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
 	now := time.Now()
+	expired := make([]*Session, 0)
 	for id, session := range m.sessions {
 		if now.Sub(session.LastAccessedAt()) > m.sessionTimeout {
 			delete(m.sessions, id)
+			expired = append(expired, session)
 		}
 	}
+	m.mutex.Unlock()
+
+	m.expired(expired)
 }
 
-// Start 启动定期清理任务
+// Start 启动定期清理任务，已经启动时不做任何处理
 func (m *Manager) Start() {
+	m.lifecycleMutex.Lock()
+	defer m.lifecycleMutex.Unlock()
+
+	if m.cleanupCancel != nil {
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	m.cleanupCtx = ctx
 	m.cleanupCancel = cancel
@@ -100,8 +261,12 @@ func (m *Manager) Start() {
 
 // Stop 停止清理任务
 func (m *Manager) Stop() {
+	m.lifecycleMutex.Lock()
+	defer m.lifecycleMutex.Unlock()
+
 	if m.cleanupCancel != nil {
 		m.cleanupCancel()
+		m.cleanupCancel = nil
 	}
 	m.cleanupWaitGroup.Wait()
 }
@@ -126,7 +291,7 @@ func (m *Manager) GetAllSessionIDs() []string {
 	return ids
 }
 
-// GenerateSessionID 生成新的会话ID
+// GenerateSessionID 生成新的会话ID（128位随机数）
 func GenerateSessionID() (string, error) {
 	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
@@ -9,12 +9,16 @@ import (
 
 // Session 会话结构，存储单个用户的会话状态
 type Session struct {
-	id             string           // 会话唯一标识
-	widgets        []widgets.Widget // 会话私有组件
-	widgetsMutex   sync.RWMutex     // 组件队列锁
-	createdAt      time.Time        // 创建时间
-	lastAccessedAt time.Time        // 最后访问时间
-	mutex          sync.RWMutex     // 读写锁，保护并发访问
+	id             string                    // 会话唯一标识
+	widgets        []widgets.Widget          // 会话私有组件
+	locals         map[string]widgets.Widget // 全局组件在本会话中的副本，组件ID -> 副本
+	widgetsMutex   sync.RWMutex              // 组件队列锁
+	createdAt      time.Time                 // 创建时间
+	lastAccessedAt time.Time                 // 最后访问时间
+	mutex          sync.RWMutex              // 读写锁，保护并发访问
+	manager        *Manager                  // 所属管理器，用于转发重新渲染请求
+	values         map[string]interface{}    // 会话键值状态
+	valuesMutex    sync.RWMutex              // 键值状态锁
 }
 
 // NewSession 创建新的会话
@@ -23,6 +27,8 @@ func NewSession(id string) *Session {
 	return &Session{
 		id:             id,
 		widgets:        make([]widgets.Widget, 0),
+		locals:         make(map[string]widgets.Widget),
+		values:         make(map[string]interface{}),
 		widgetsMutex:   sync.RWMutex{},
 		createdAt:      now,
 		lastAccessedAt: now,
@@ -32,9 +38,20 @@ func NewSession(id string) *Session {
 
 // ID 返回会话ID
 func (s *Session) ID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	return s.id
 }
 
+// setID 更换会话ID，由Manager.RotateSession调用
+func (s *Session) setID(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.id = id
+}
+
 // LastAccessedAt 返回最后访问时间
 func (s *Session) LastAccessedAt() time.Time {
 	s.mutex.RLock()
@@ -43,28 +60,184 @@ func (s *Session) LastAccessedAt() time.Time {
 	return s.lastAccessedAt
 }
 
+// Touch 刷新最后访问时间，防止活跃会话被当作过期会话清理
+func (s *Session) Touch() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastAccessedAt = time.Now()
+}
+
 // CreatedAt 返回创建时间
 func (s *Session) CreatedAt() time.Time {
 	return s.createdAt
 }
 
+// Set 设置会话状态
+func (s *Session) Set(key string, value interface{}) {
+	s.valuesMutex.Lock()
+	defer s.valuesMutex.Unlock()
+
+	s.values[key] = value
+}
+
+// Get 获取会话状态
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.valuesMutex.RLock()
+	defer s.valuesMutex.RUnlock()
+
+	value, exists := s.values[key]
+	return value, exists
+}
+
+// Update 原子地读取并修改会话状态，返回新值
+//
+// fn在持有锁时调用，不能在其中访问同一会话的状态。
+func (s *Session) Update(key string, fn func(value interface{}, exists bool) interface{}) interface{} {
+	s.valuesMutex.Lock()
+	defer s.valuesMutex.Unlock()
+
+	value, exists := s.values[key]
+	value = fn(value, exists)
+	s.values[key] = value
+	return value
+}
+
+// Delete 删除会话状态
+func (s *Session) Delete(key string) {
+	s.valuesMutex.Lock()
+	defer s.valuesMutex.Unlock()
+
+	delete(s.values, key)
+}
+
+// Has 检查状态是否存在
+func (s *Session) Has(key string) bool {
+	s.valuesMutex.RLock()
+	defer s.valuesMutex.RUnlock()
+
+	_, exists := s.values[key]
+	return exists
+}
+
+// Clear 清空所有状态
+func (s *Session) Clear() {
+	s.valuesMutex.Lock()
+	defer s.valuesMutex.Unlock()
+
+	s.values = make(map[string]interface{})
+}
+
 // AddWidget 添加组件到会话
-func (s *Session) AddWidget(widget widgets.Widget) {
+//
+// 组件树中设置了Key的组件与全局组件或会话已有组件的Key重复时不添加，返回包装widgets.ErrDuplicateKey的错误。
+func (s *Session) AddWidget(widget widgets.Widget) error {
+	if err := widgets.AssignKeys([]widgets.Widget{widget}); err != nil {
+		return err
+	}
+
+	// 在加锁前获取全局组件，避免与管理器锁嵌套
+	var globals []widgets.Widget
+	if s.manager != nil {
+		globals = s.manager.getGlobalWidgets()
+	}
+	if err := widgets.CheckKeys(globals, widget); err != nil {
+		return err
+	}
+
 	s.widgetsMutex.Lock()
 	defer s.widgetsMutex.Unlock()
 
+	if err := widgets.CheckKeys(s.widgets, widget); err != nil {
+		return err
+	}
 	s.widgets = append(s.widgets, widget)
+	return nil
 }
 
+// SetWidget 替换会话中ID相同的组件，支持嵌套在容器中的组件
 func (s *Session) SetWidget(widget widgets.Widget) {
 	s.widgetsMutex.Lock()
 	defer s.widgetsMutex.Unlock()
-	for i, w := range s.widgets {
-		if w.GetID() == widget.GetID() {
-			s.widgets[i] = widget
-			return
-		}
+
+	widgets.ReplaceWidget(s.widgets, widget.GetID(), widget)
+}
+
+// FindWidget 在会话组件树中查找指定ID的组件，不存在时返回nil
+func (s *Session) FindWidget(componentID string) widgets.Widget {
+	s.widgetsMutex.RLock()
+	defer s.widgetsMutex.RUnlock()
+
+	return widgets.FindWidget(s.widgets, componentID)
+}
+
+// Local 返回全局组件在本会话中的私有副本，首次调用时复制（写时复制）
+//
+// 嵌套在容器中的全局组件会连同其顶层组件一起复制，返回副本树中对应的组件。
+// 会话自己的组件和不可克隆的组件直接返回其本身。
+func (s *Session) Local(widget widgets.Widget) widgets.Widget {
+	// 在加锁前获取全局组件，避免与管理器锁嵌套
+	var globals []widgets.Widget
+	if s.manager != nil {
+		globals = s.manager.getGlobalWidgets()
+	}
+
+	s.widgetsMutex.Lock()
+	defer s.widgetsMutex.Unlock()
+
+	// 会话组件或已经是副本
+	locals := s.localRoots()
+	if widgets.Contains(s.widgets, widget) || widgets.Contains(locals, widget) {
+		return widget
+	}
+
+	id := widget.GetID()
+	if local := widgets.FindWidget(locals, id); local != nil {
+		return local
+	}
+
+	// 复制包含该组件的顶层全局组件
+	root := widgets.FindRoot(globals, id)
+	if root == nil {
+		root = widget
+	}
+	cloneable, ok := root.(widgets.ICloneable)
+	if !ok {
+		return widget
+	}
+	localRoot := cloneable.Clone()
+	s.locals[root.GetID()] = localRoot
+
+	if local := widgets.FindWidget([]widgets.Widget{localRoot}, id); local != nil {
+		return local
+	}
+	return widget
+}
+
+// localRoots 获取所有副本，调用时需持有widgetsMutex
+func (s *Session) localRoots() []widgets.Widget {
+	roots := make([]widgets.Widget, 0, len(s.locals))
+	for _, local := range s.locals {
+		roots = append(roots, local)
 	}
+	return roots
+}
+
+// LocalWidget 获取全局组件在本会话中的副本，不存在时返回false
+func (s *Session) LocalWidget(componentID string) (widgets.Widget, bool) {
+	s.widgetsMutex.RLock()
+	defer s.widgetsMutex.RUnlock()
+
+	local, exists := s.locals[componentID]
+	return local, exists
+}
+
+// ResetLocal 丢弃全局组件在本会话中的副本，恢复显示全局模板
+func (s *Session) ResetLocal(componentID string) {
+	s.widgetsMutex.Lock()
+	defer s.widgetsMutex.Unlock()
+
+	delete(s.locals, componentID)
 }
 
 // GetWidgets 获取会话组件
@@ -86,19 +259,13 @@ func (s *Session) ClearWidgets() {
 	s.widgets = make([]widgets.Widget, 0)
 }
 
-// RemoveWidget 从会话中移除指定ID的组件
+// RemoveWidget 从会话中移除指定ID的组件，支持嵌套在容器中的组件
 func (s *Session) RemoveWidget(componentID string) {
 	s.widgetsMutex.Lock()
 	defer s.widgetsMutex.Unlock()
 
-	// 查找并移除指定ID的组件
-	for i, widget := range s.widgets {
-		if widget.GetID() == componentID {
-			// 从切片中移除该组件
-			s.widgets = append(s.widgets[:i], s.widgets[i+1:]...)
-			break
-		}
-	}
+	// 查找并移除指定ID的组件，支持嵌套在容器中的组件
+	s.widgets, _ = widgets.RemoveWidget(s.widgets, componentID)
 }
 
 // DeleteWidget 删除组件（占位方法，实际实现在前端）
@@ -109,8 +276,18 @@ func (s *Session) DeleteWidget(componentID string) {
 	// 占位方法，实际删除逻辑由前端处理
 }
 
+// Rerun 重新渲染会话并推送到已连接的客户端，可以在任意goroutine中调用
+func (s *Session) Rerun() {
+	if s.manager != nil {
+		s.manager.rerun(s)
+	}
+}
+
 // LastAccessedAtStr 返回最后访问时间的字符串表示
 func (s *Session) LastAccessedAtStr() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	return s.lastAccessedAt.Format(time.RFC3339)
 }
 
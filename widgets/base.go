@@ -11,11 +11,56 @@ type ISession interface {
 	ID() string
 	LastAccessedAt() time.Time
 	CreatedAt() time.Time
-	AddWidget(widget Widget)
+	AddWidget(widget Widget) error
 	SetWidget(widget Widget)
 	GetWidgets() []Widget
 	ClearWidgets()
 	DeleteWidget(componentID string)
+	Local(widget Widget) Widget
+	Rerun()
+
+	// 会话键值状态
+	Set(key string, value interface{})
+	Get(key string) (interface{}, bool)
+	Update(key string, fn func(value interface{}, exists bool) interface{}) interface{}
+	Delete(key string)
+	Has(key string) bool
+	Clear()
+}
+
+// SessionValue 获取指定类型的会话状态，不存在或类型不匹配时返回false
+func SessionValue[T any](session ISession, key string) (T, bool) {
+	var zero T
+	value, exists := session.Get(key)
+	if !exists {
+		return zero, false
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
+
+// SessionValueOr 获取指定类型的会话状态，不存在或类型不匹配时返回默认值
+func SessionValueOr[T any](session ISession, key string, defaultValue T) T {
+	if value, ok := SessionValue[T](session, key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// UpdateSessionValue 原子地修改指定类型的会话状态并返回新值
+//
+// 状态不存在或类型不匹配时，fn收到T的零值。
+func UpdateSessionValue[T any](session ISession, key string, fn func(value T) T) T {
+	updated := session.Update(key, func(value interface{}, exists bool) interface{} {
+		typed, _ := value.(T)
+		return fn(typed)
+	})
+	// T是接口类型且fn返回nil时，updated为nil，返回T的零值
+	value, _ := updated.(T)
+	return value
 }
 
 // Widget 组件接口，所有组件必须实现此接口
@@ -32,6 +77,12 @@ type Widget interface {
 	// GetType 获取组件类型
 	GetType() string
 
+	// SetKey 设置组件Key
+	SetKey(key string)
+
+	// GetKey 获取组件Key
+	GetKey() string
+
 	// OnChange 设置值变更回调函数
 	OnChange(callback func(session ISession, event string, value string))
 
@@ -44,12 +95,72 @@ type ITriggerCallbacks interface {
 	TriggerCallbacks(session ISession, event string, value string)
 }
 
+// IValueBinder 值绑定接口
+//
+// 输入组件实现此接口，在回调触发前解析、校验并保存客户端发送的事件值。
+// 返回错误时组件值保持不变，回调不会被触发。
+type IValueBinder interface {
+	BindValue(session ISession, event string, value string) error
+}
+
+// IResettable 可以恢复初始值的输入组件接口
+//
+// 表单设置SetClearOnSubmit后，提交成功时对其中的每个输入组件调用Reset，原地恢复创建时的值，不触发回调。
+type IResettable interface {
+	Reset()
+}
+
+// IViewEvent 视图事件接口
+//
+// 组件实现此接口声明哪些事件只改变自身的显示，例如表格的排序和翻页。
+// 视图事件绑定值后不重新运行脚本式应用，服务端只重新渲染该组件并发送给客户端。
+type IViewEvent interface {
+	IsViewEvent(event string) bool
+}
+
+// ICloneable 可克隆组件接口
+//
+// 全局组件作为模板被所有会话共享。实现此接口的组件在会话首次修改时
+// 复制出该会话的私有副本（写时复制），副本与模板ID相同、共享回调函数。
+type ICloneable interface {
+	Clone() Widget
+}
+
+// For 返回组件在指定会话中的私有副本
+//
+// 在回调中读取或修改全局组件时使用，例如 widgets.For(session, text).SetText("...")，
+// 修改只对该会话可见。会话组件和不可克隆的组件返回其本身。
+func For[T Widget](session ISession, widget T) T {
+	if session == nil {
+		return widget
+	}
+	if local, ok := session.Local(widget).(T); ok {
+		return local
+	}
+	return widget
+}
+
 // BaseWidget 组件基类，提供通用功能
 type BaseWidget struct {
-	id         string                                               // 唯一标识符
-	widgetType string                                               // 组件类型
-	visible    bool                                                 // 可见性标志
-	callbacks  []func(session ISession, event string, value string) // 值变更回调函数列表
+	id          string                                               // 唯一标识符
+	widgetType  string                                               // 组件类型
+	key         string                                               // 用户指定的Key
+	parentScope string                                               // 父组件的Key作用域路径
+	visible     bool                                                 // 可见性标志
+	callbacks   []func(session ISession, event string, value string) // 值变更回调函数列表
+}
+
+// cloneChildren 深度复制子组件列表，不可克隆的子组件保持共享
+func cloneChildren(children []Widget) []Widget {
+	cloned := make([]Widget, len(children))
+	for i, child := range children {
+		if cloneable, ok := child.(ICloneable); ok {
+			cloned[i] = cloneable.Clone()
+		} else {
+			cloned[i] = child
+		}
+	}
+	return cloned
 }
 
 // NewBaseWidget 创建基础组件
@@ -77,6 +188,38 @@ func (w *BaseWidget) GetType() string {
 	return w.widgetType
 }
 
+// SetKey 设置组件Key，组件ID改由Key和父组件的作用域路径生成
+//
+// 生成的ID与构造顺序无关，在不同会话和服务重启之间保持不变。
+// 应在组件添加到容器、服务或会话之前调用，同一作用域中的Key不能重复。
+func (w *BaseWidget) SetKey(key string) {
+	w.key = key
+	if key != "" {
+		w.id = KeyID(w.parentScope, key)
+	}
+}
+
+// GetKey 获取组件Key
+func (w *BaseWidget) GetKey() string {
+	return w.key
+}
+
+// keyScope 组件为子组件提供的作用域路径，未设置Key的组件沿用父组件的作用域
+func (w *BaseWidget) keyScope() string {
+	if w.key == "" {
+		return w.parentScope
+	}
+	return joinScope(w.parentScope, w.key)
+}
+
+// setParentScope 设置父组件的作用域路径
+func (w *BaseWidget) setParentScope(scope string) {
+	w.parentScope = scope
+	if w.key != "" {
+		w.id = KeyID(scope, w.key)
+	}
+}
+
 // OnChange 设置值变更回调函数
 func (w *BaseWidget) OnChange(callback func(session ISession, event string, value string)) {
 	w.callbacks = append(w.callbacks, callback)
@@ -91,6 +234,14 @@ func (w *BaseWidget) TriggerCallbacks(session ISession, event string, value stri
 	}
 }
 
+// CloneBase 复制基础组件，供组件实现Clone时使用，回调函数列表独立但回调本身共享
+func (w *BaseWidget) CloneBase() *BaseWidget {
+	c := *w
+	c.callbacks = make([]func(session ISession, event string, value string), len(w.callbacks))
+	copy(c.callbacks, w.callbacks)
+	return &c
+}
+
 // SetVisible 设置可见性
 func (w *BaseWidget) SetVisible(visible bool) {
 	w.visible = visible
@@ -103,7 +254,7 @@ func (w *BaseWidget) IsVisible() bool {
 
 var widgetIDCounter uint64
 
-// generateID 生成组件唯一ID
+// generateID 为未设置Key的组件生成进程内唯一ID，取决于组件的构造顺序
 func generateID() string {
 	id := atomic.AddUint64(&widgetIDCounter, 1)
 	return fmt.Sprintf("widget_%d", id)
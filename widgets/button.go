@@ -1,9 +1,5 @@
 package widgets
 
-import (
-	"fmt"
-)
-
 // ButtonWidget 按钮组件
 type ButtonWidget struct {
 	*BaseWidget
@@ -23,5 +19,12 @@ func NewButton(label string) *ButtonWidget {
 // Render 渲染按钮组件为HTML
 func (w *ButtonWidget) Render() string {
 	id := w.GetID()
-	return fmt.Sprintf("<button class=\"st-button\" data-widget-id=\"%s\" id=\"%s\" data-event-type=\"click\">%s</button>", id, id, w.label)
+	return HTMLf("<button class=\"st-button\" data-widget-id=\"%s\" id=\"%s\" data-event-type=\"click\">%s</button>", id, id, w.label).String()
+}
+
+// Clone 复制按钮组件
+func (w *ButtonWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
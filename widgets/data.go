@@ -1,89 +1,37 @@
 package widgets
 
-import (
-	"fmt"
-	"html"
-	"reflect"
-)
-
-// TableWidget 表格组件
+// TableWidget 表格组件，显示全部行
 type TableWidget struct {
 	*BaseWidget
-	data interface{}
+	tabular
 }
 
 // NewTable 创建新的表格组件
+//
+// data可以是结构体切片（元素可以是结构体指针，列设置见TableColumn）、键为字符串的map切片（列按名称排序）、
+// 带表头的Rows、没有表头的二维切片或标量切片；单个map或结构体显示为键值两列，map按键排序。
+// 数据类型不受支持时组件显示错误。
 func NewTable(data interface{}) *TableWidget {
 	w := &TableWidget{
 		BaseWidget: NewBaseWidget("table"),
-		data:       data,
 	}
-
+	w.SetData(data)
 	return w
 }
 
 // Render 渲染表格组件为HTML
 func (w *TableWidget) Render() string {
-	// 简单实现，支持字符串切片
-	switch v := w.data.(type) {
-	case []string:
-		rows := ""
-		for _, item := range v {
-			rows += fmt.Sprintf("<tr><td>%s</td></tr>", html.EscapeString(item))
-		}
-		return fmt.Sprintf("<table class=\"st-table\" data-widget-id=\"%s\"><tbody>%s</tbody></table>", w.GetID(), rows)
-	default:
-		return fmt.Sprintf("<div class=\"st-table\" data-widget-id=\"%s\">%v</div>", w.GetID(), w.data)
+	if w.err != nil {
+		return HTMLf("<div class=\"st-table st-table-error\" data-widget-id=\"%s\">%s</div>", w.GetID(), w.err.Error()).String()
 	}
+	return w.renderTable(HTMLf(" class=\"st-table\" data-widget-id=\"%s\"", w.GetID()), w.visibleColumns(), w.data.rows).String()
 }
 
-// DataFrameWidget 数据框组件
-type DataFrameWidget struct {
-	*BaseWidget
-	data interface{}
-}
-
-// NewDataFrame 创建新的数据框组件
-func NewDataFrame(data interface{}) *DataFrameWidget {
-	w := &DataFrameWidget{
-		BaseWidget: NewBaseWidget("dataframe"),
-		data:       data,
-	}
-
-	return w
-}
-
-// Render 渲染数据框组件为HTML
-func (w *DataFrameWidget) Render() string {
-	// 简单实现，支持map[string]interface{}
-	switch v := w.data.(type) {
-	case map[string]interface{}:
-		rows := ""
-		for key, value := range v {
-			rows += fmt.Sprintf("<tr><td>%s</td><td>%v</td></tr>", html.EscapeString(key), value)
-		}
-		return fmt.Sprintf("<table class=\"st-dataframe\" data-widget-id=\"%s\"><tbody>%s</tbody></table>", w.GetID(), rows)
-	case map[string]string:
-		rows := ""
-		for key, value := range v {
-			rows += fmt.Sprintf("<tr><td>%s</td><td>%s</td></tr>", html.EscapeString(key), html.EscapeString(value))
-		}
-		return fmt.Sprintf("<table class=\"st-dataframe\" data-widget-id=\"%s\"><tbody>%s</tbody></table>", w.GetID(), rows)
-	default:
-		// 使用反射来处理其他类型
-		val := reflect.ValueOf(w.data)
-		if val.Kind() == reflect.Struct {
-			rows := ""
-			t := val.Type()
-			for i := 0; i < val.NumField(); i++ {
-				field := t.Field(i)
-				value := val.Field(i)
-				rows += fmt.Sprintf("<tr><td>%s</td><td>%v</td></tr>", html.EscapeString(field.Name), value.Interface())
-			}
-			return fmt.Sprintf("<table class=\"st-dataframe\" data-widget-id=\"%s\"><tbody>%s</tbody></table>", w.GetID(), rows)
-		}
-		return fmt.Sprintf("<div class=\"st-dataframe\" data-widget-id=\"%s\">%v</div>", w.GetID(), w.data)
-	}
+// Clone 复制表格组件
+func (w *TableWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // MetricWidget 指标组件
@@ -111,10 +59,17 @@ func (w *MetricWidget) SetDelta(delta string) {
 
 // Render 渲染指标组件为HTML
 func (w *MetricWidget) Render() string {
-	deltaHTML := ""
+	var deltaHTML SafeHTML
 	if w.delta != "" {
-		deltaHTML = fmt.Sprintf("<div class=\"st-metric-delta\">%s</div>", html.EscapeString(w.delta))
+		deltaHTML = HTMLf("<div class=\"st-metric-delta\">%s</div>", w.delta)
 	}
-	return fmt.Sprintf("<div class=\"st-metric\" data-widget-id=\"%s\"><div class=\"st-metric-label\">%s</div><div class=\"st-metric-value\">%v</div>%s</div>",
-		w.GetID(), html.EscapeString(w.label), w.value, deltaHTML)
+	return HTMLf("<div class=\"st-metric\" data-widget-id=\"%s\"><div class=\"st-metric-label\">%s</div><div class=\"st-metric-value\">%v</div>%s</div>",
+		w.GetID(), w.label, w.value, deltaHTML).String()
+}
+
+// Clone 复制指标组件
+func (w *MetricWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
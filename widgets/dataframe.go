@@ -0,0 +1,596 @@
+package widgets
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageSize 数据框默认每页显示的行数
+const defaultPageSize = 100
+
+// RowSelection 数据框的行选择方式
+type RowSelection int
+
+const (
+	// NoSelection 不能选择行
+	NoSelection RowSelection = iota
+	// SingleRow 点击行选中该行，再次点击取消选中
+	SingleRow
+	// MultiRow 每行显示复选框，可以选中多行
+	MultiRow
+)
+
+// DataFrameWidget 数据框组件，在可滚动的区域中分页显示表格
+//
+// 排序、搜索、按列筛选和翻页都在服务端执行，客户端只收到当前页的行。
+// 这些操作是视图事件，只重新渲染该组件；全局数据框的视图和选中的行按会话隔离。
+type DataFrameWidget struct {
+	*BaseWidget
+	tabular
+	pageSize   int
+	sortable   bool
+	searchable bool
+	filterable bool
+	selection  RowSelection
+
+	// 以下切片和map修改时整体替换，会话副本可以与模板共享
+	sortColumn string // 排序的列名，为空时按数据中的顺序
+	sortDesc   bool
+	search     string
+	filters    map[string]string // 列名 -> 筛选文本
+	page       int
+	view       []int // 筛选和排序后的行在数据中的位置，nil表示全部行按数据中的顺序
+	selected   []int // 选中的行在数据中的位置，升序
+
+	selectHandlers   []func(session ISession, records []interface{})
+	selectionChanged bool // 本次事件改变了选中的行，触发回调后清除
+}
+
+// NewDataFrame 创建新的数据框组件，支持的数据类型与NewTable相同
+//
+// 默认可以按列排序和搜索，每页显示100行，不能选择行。
+func NewDataFrame(data interface{}) *DataFrameWidget {
+	w := &DataFrameWidget{
+		BaseWidget: NewBaseWidget("dataframe"),
+		pageSize:   defaultPageSize,
+		sortable:   true,
+		searchable: true,
+	}
+	w.SetData(data)
+	return w
+}
+
+// SetData 设置数据，保留排序、搜索和筛选条件，超出新数据行数的选中行被取消
+func (w *DataFrameWidget) SetData(data interface{}) {
+	w.tabular.SetData(data)
+	n := w.rowCount()
+	if i := sort.SearchInts(w.selected, n); i < len(w.selected) {
+		w.selected = slices.Clone(w.selected[:i])
+	}
+	w.updateView()
+}
+
+// SetColumns 设置列的显示方式，用法与TableWidget.SetColumns相同
+func (w *DataFrameWidget) SetColumns(columns ...TableColumn) {
+	w.tabular.SetColumns(columns...)
+	w.updateView()
+}
+
+// SetPageSize 设置每页显示的行数，size不大于0时显示全部行
+func (w *DataFrameWidget) SetPageSize(size int) {
+	w.pageSize = size
+	w.clampPage()
+}
+
+// SetSortable 设置是否可以点击表头排序，关闭时取消当前的排序
+func (w *DataFrameWidget) SetSortable(sortable bool) {
+	w.sortable = sortable
+	if !sortable && w.sortColumn != "" {
+		w.sortColumn, w.sortDesc = "", false
+		w.updateView()
+	}
+}
+
+// SetSearchable 设置是否显示搜索框，关闭时清除搜索条件
+func (w *DataFrameWidget) SetSearchable(searchable bool) {
+	w.searchable = searchable
+	if !searchable && w.search != "" {
+		w.search = ""
+		w.updateView()
+	}
+}
+
+// SetFilterable 设置是否在表头下显示每列的筛选框，关闭时清除筛选条件
+func (w *DataFrameWidget) SetFilterable(filterable bool) {
+	w.filterable = filterable
+	if !filterable && len(w.filters) > 0 {
+		w.filters = nil
+		w.updateView()
+	}
+}
+
+// SetSelectionMode 设置行选择方式，改为不能选择时取消所有选中的行，改为单选时只保留第一行
+func (w *DataFrameWidget) SetSelectionMode(mode RowSelection) {
+	w.selection = mode
+	switch {
+	case mode == NoSelection:
+		w.selected = nil
+	case mode == SingleRow && len(w.selected) > 1:
+		w.selected = w.selected[:1:1]
+	}
+}
+
+// OnSelect 设置行选择回调函数，参数是选中行的原始元素，例如结构体切片的元素
+func (w *DataFrameWidget) OnSelect(handler func(session ISession, records []interface{})) {
+	w.selectHandlers = append(w.selectHandlers, handler)
+}
+
+// GetSelectedRows 获取选中的行在数据中的位置（从0开始，升序）
+func (w *DataFrameWidget) GetSelectedRows() []int {
+	return slices.Clone(w.selected)
+}
+
+// GetSelectedRecords 获取选中行的原始元素，顺序与GetSelectedRows相同
+//
+// 结构体切片、map切片、二维切片和标量切片返回切片的元素；单个map或结构体返回该行的键和值。
+func (w *DataFrameWidget) GetSelectedRecords() []interface{} {
+	records := make([]interface{}, len(w.selected))
+	for i, row := range w.selected {
+		records[i] = w.data.record(row)
+	}
+	return records
+}
+
+// IsViewEvent 排序、搜索、筛选和翻页只改变数据框自身的显示
+func (w *DataFrameWidget) IsViewEvent(event string) bool {
+	switch event {
+	case "sort", "search", "filter", "page":
+		return true
+	}
+	return false
+}
+
+// BindValue 处理客户端发送的视图和行选择事件
+func (w *DataFrameWidget) BindValue(session ISession, event string, value string) error {
+	switch event {
+	case "sort":
+		if !w.sortable || !w.hasColumn(value) {
+			return fmt.Errorf("dataframe %s: invalid sort column %q", w.GetID(), value)
+		}
+		// 依次切换为升序、降序和不排序
+		switch {
+		case value != w.sortColumn:
+			w.sortColumn, w.sortDesc = value, false
+		case !w.sortDesc:
+			w.sortDesc = true
+		default:
+			w.sortColumn, w.sortDesc = "", false
+		}
+		w.page = 0
+		w.updateView()
+
+	case "search":
+		if !w.searchable {
+			return fmt.Errorf("dataframe %s: search is disabled", w.GetID())
+		}
+		w.search = value
+		w.page = 0
+		w.updateView()
+
+	case "filter":
+		var filter struct {
+			Column string `json:"column"`
+			Value  string `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(value), &filter); err != nil {
+			return fmt.Errorf("dataframe %s: invalid filter: %w", w.GetID(), err)
+		}
+		if !w.filterable || !w.hasColumn(filter.Column) {
+			return fmt.Errorf("dataframe %s: invalid filter column %q", w.GetID(), filter.Column)
+		}
+		filters := maps.Clone(w.filters)
+		if filters == nil {
+			filters = make(map[string]string)
+		}
+		if filter.Value == "" {
+			delete(filters, filter.Column)
+		} else {
+			filters[filter.Column] = filter.Value
+		}
+		w.filters = filters
+		w.page = 0
+		w.updateView()
+
+	case "page":
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("dataframe %s: invalid page %q", w.GetID(), value)
+		}
+		w.page = page
+		w.clampPage()
+
+	case "select":
+		row, err := strconv.Atoi(value)
+		if w.selection == NoSelection || err != nil || row < 0 || row >= w.rowCount() {
+			return fmt.Errorf("dataframe %s: invalid row %q", w.GetID(), value)
+		}
+		i, found := slices.BinarySearch(w.selected, row)
+		switch {
+		case found:
+			w.selected = slices.Delete(slices.Clone(w.selected), i, i+1)
+		case w.selection == SingleRow:
+			w.selected = []int{row}
+		default:
+			w.selected = slices.Insert(slices.Clone(w.selected), i, row)
+		}
+		w.selectionChanged = true
+
+	case "select_page":
+		// 选中或取消选中当前页的所有行
+		if w.selection != MultiRow {
+			return fmt.Errorf("dataframe %s: multi-row selection is disabled", w.GetID())
+		}
+		selected := make(map[int]bool, len(w.selected))
+		for _, row := range w.selected {
+			selected[row] = true
+		}
+		start, end := w.pageRange()
+		for i := start; i < end; i++ {
+			if value == "true" {
+				selected[w.viewRow(i)] = true
+			} else {
+				delete(selected, w.viewRow(i))
+			}
+		}
+		w.selected = slices.Sorted(maps.Keys(selected))
+		w.selectionChanged = true
+	}
+	return nil
+}
+
+// TriggerCallbacks 选中的行变化时调用行选择回调，再触发组件自身的回调
+func (w *DataFrameWidget) TriggerCallbacks(session ISession, event string, value string) {
+	if w.selectionChanged {
+		w.selectionChanged = false
+		records := w.GetSelectedRecords()
+		for _, handler := range w.selectHandlers {
+			handler(session, records)
+		}
+	}
+	w.BaseWidget.TriggerCallbacks(session, event, value)
+}
+
+// rowCount 数据的总行数
+func (w *DataFrameWidget) rowCount() int {
+	if w.data == nil {
+		return 0
+	}
+	return len(w.data.rows)
+}
+
+// viewLen 筛选后的行数
+func (w *DataFrameWidget) viewLen() int {
+	if w.view == nil {
+		return w.rowCount()
+	}
+	return len(w.view)
+}
+
+// viewRow 获取筛选和排序后第i行在数据中的位置
+func (w *DataFrameWidget) viewRow(i int) int {
+	if w.view == nil {
+		return i
+	}
+	return w.view[i]
+}
+
+// pageCount 总页数，至少为1
+func (w *DataFrameWidget) pageCount() int {
+	if w.pageSize <= 0 {
+		return 1
+	}
+	return max((w.viewLen()+w.pageSize-1)/w.pageSize, 1)
+}
+
+// clampPage 把当前页限制在有效范围内
+func (w *DataFrameWidget) clampPage() {
+	w.page = min(max(w.page, 0), w.pageCount()-1)
+}
+
+// pageRange 当前页的行在筛选结果中的范围[start, end)
+func (w *DataFrameWidget) pageRange() (start, end int) {
+	if w.pageSize <= 0 {
+		return 0, w.viewLen()
+	}
+	start = min(w.page*w.pageSize, w.viewLen())
+	return start, min(start+w.pageSize, w.viewLen())
+}
+
+// hasColumn 检查列是否显示在表格中
+func (w *DataFrameWidget) hasColumn(name string) bool {
+	for _, column := range w.visibleColumns() {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelected 检查数据中的行是否被选中
+func (w *DataFrameWidget) isSelected(row int) bool {
+	_, found := slices.BinarySearch(w.selected, row)
+	return found
+}
+
+// cellFilter 单列的筛选条件
+type cellFilter struct {
+	column shownColumn
+	text   string // 小写的筛选文本
+}
+
+// updateView 按搜索、筛选和排序条件重新计算显示的行
+//
+// 搜索匹配任意显示列、筛选匹配对应列中显示的文本，都不区分大小写。
+func (w *DataFrameWidget) updateView() {
+	w.view = nil
+	defer w.clampPage()
+	if w.data == nil {
+		return
+	}
+
+	columns := w.visibleColumns()
+	sortIndex := -1
+	var filters []cellFilter
+	for _, column := range columns {
+		if column.Name == w.sortColumn {
+			sortIndex = column.index
+		}
+		if text := strings.ToLower(strings.TrimSpace(w.filters[column.Name])); text != "" {
+			filters = append(filters, cellFilter{column: column, text: text})
+		}
+	}
+	search := strings.ToLower(strings.TrimSpace(w.search))
+	if sortIndex < 0 && search == "" && len(filters) == 0 {
+		return
+	}
+
+	rows := w.data.rows
+	view := make([]int, 0, len(rows))
+	for i, row := range rows {
+		if matchRow(row, columns, filters, search) {
+			view = append(view, i)
+		}
+	}
+	if sortIndex >= 0 {
+		sort.SliceStable(view, func(a, b int) bool {
+			return lessCell(rows[view[a]][sortIndex], rows[view[b]][sortIndex], w.sortDesc)
+		})
+	}
+	w.view = view
+}
+
+// matchRow 检查行是否满足所有筛选条件，并且有一列包含搜索文本
+func matchRow(row []interface{}, columns []shownColumn, filters []cellFilter, search string) bool {
+	for _, filter := range filters {
+		if !strings.Contains(strings.ToLower(formatCell(row[filter.column.index], filter.column.Format)), filter.text) {
+			return false
+		}
+	}
+	if search == "" {
+		return true
+	}
+	for _, column := range columns {
+		if strings.Contains(strings.ToLower(formatCell(row[column.index], column.Format)), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// lessCell 比较排序列的两个值，nil总是排在最后
+func lessCell(a, b interface{}, desc bool) bool {
+	x, y := cellValue(a), cellValue(b)
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() && !y.IsValid()
+	}
+	c := compareCells(x, y)
+	if desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// cellValue 获取单元格的值，解开指针，nil返回无效值
+func cellValue(value interface{}) reflect.Value {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// compareCells 比较两个单元格：数值和时间按大小，其余按显示文本
+func compareCells(a, b reflect.Value) int {
+	if x, ok := cellNumber(a); ok {
+		if y, ok := cellNumber(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+	return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+// cellNumber 把数值单元格转换为float64
+func cellNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// Render 渲染数据框组件为HTML，只包含当前页的行
+func (w *DataFrameWidget) Render() string {
+	if w.err != nil {
+		return HTMLf("<div class=\"st-dataframe st-table-error\" data-widget-id=\"%s\">%s</div>", w.GetID(), w.err.Error()).String()
+	}
+
+	columns := w.visibleColumns()
+	var b strings.Builder
+	b.WriteString(HTMLf("<div class=\"st-dataframe\" data-widget-id=\"%s\" data-page=\"%d\">", w.GetID(), w.page).String())
+	if w.searchable {
+		b.WriteString(HTMLf("<div class=\"st-dataframe-toolbar\"><input type=\"search\" class=\"st-dataframe-search\" data-focus-key=\"search\" placeholder=\"搜索\" value=\"%s\"></div>",
+			w.search).String())
+	}
+
+	b.WriteString("<div class=\"st-dataframe-scroll\"><table class=\"st-dataframe-table\">")
+	if w.data.header {
+		w.writeHeader(&b, columns)
+	}
+
+	b.WriteString("<tbody>")
+	start, end := w.pageRange()
+	for i := start; i < end; i++ {
+		row := w.viewRow(i)
+		switch {
+		case w.selection == NoSelection:
+			b.WriteString("<tr>")
+		case w.isSelected(row):
+			b.WriteString(HTMLf("<tr class=\"st-row-selectable st-row-selected\" data-row=\"%d\">", row).String())
+		default:
+			b.WriteString(HTMLf("<tr class=\"st-row-selectable\" data-row=\"%d\">", row).String())
+		}
+		if w.selection == MultiRow {
+			b.WriteString(HTMLf("<td class=\"st-dataframe-select\"><input type=\"checkbox\"%s></td>", checkedAttr(w.isSelected(row))).String())
+		}
+		writeCells(&b, columns, w.data.rows[row])
+		b.WriteString("</tr>")
+	}
+	if start == end {
+		message := "无数据"
+		if w.rowCount() > 0 {
+			message = "没有匹配的行"
+		}
+		span := max(len(columns), 1)
+		if w.selection == MultiRow {
+			span++
+		}
+		b.WriteString(HTMLf("<tr><td class=\"st-table-empty\" colspan=\"%d\">%s</td></tr>", span, message).String())
+	}
+	b.WriteString("</tbody></table></div>")
+
+	w.writeFooter(&b)
+	b.WriteString("</div>")
+	return b.String()
+}
+
+// writeHeader 渲染表头，可以排序时表头可点击，可以筛选时下方显示每列的筛选框
+func (w *DataFrameWidget) writeHeader(b *strings.Builder, columns []shownColumn) {
+	b.WriteString("<thead><tr>")
+	if w.selection == MultiRow {
+		start, end := w.pageRange()
+		all := start < end
+		for i := start; i < end && all; i++ {
+			all = w.isSelected(w.viewRow(i))
+		}
+		b.WriteString(HTMLf("<th class=\"st-dataframe-select\"><input type=\"checkbox\" data-select-page=\"true\"%s></th>", checkedAttr(all)).String())
+	}
+	for _, column := range columns {
+		if !w.sortable {
+			b.WriteString(HTMLf("<th%s>%s</th>", widthStyle(column.Width), column.label()).String())
+			continue
+		}
+		var order SafeHTML
+		indicator := ""
+		if column.Name == w.sortColumn {
+			order, indicator = " aria-sort=\"ascending\"", "▲"
+			if w.sortDesc {
+				order, indicator = " aria-sort=\"descending\"", "▼"
+			}
+		}
+		b.WriteString(HTMLf("<th class=\"st-dataframe-sortable\" data-sort=\"%s\"%s%s>%s<span class=\"st-sort-indicator\">%s</span></th>",
+			column.Name, order, widthStyle(column.Width), column.label(), indicator).String())
+	}
+	b.WriteString("</tr>")
+
+	if w.filterable {
+		b.WriteString("<tr class=\"st-dataframe-filters\">")
+		if w.selection == MultiRow {
+			b.WriteString("<th></th>")
+		}
+		for _, column := range columns {
+			b.WriteString(HTMLf("<th><input type=\"text\" class=\"st-dataframe-filter\" data-filter=\"%s\" data-focus-key=\"filter:%s\" placeholder=\"筛选\" value=\"%s\"></th>",
+				column.Name, column.Name, w.filters[column.Name]).String())
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</thead>")
+}
+
+// writeFooter 渲染行数统计和翻页按钮
+func (w *DataFrameWidget) writeFooter(b *strings.Builder) {
+	b.WriteString("<div class=\"st-dataframe-footer\"><span class=\"st-dataframe-count\">")
+	if w.viewLen() != w.rowCount() {
+		b.WriteString(HTMLf("匹配 %d / %d 行", w.viewLen(), w.rowCount()).String())
+	} else {
+		b.WriteString(HTMLf("共 %d 行", w.rowCount()).String())
+	}
+	if len(w.selected) > 0 {
+		b.WriteString(HTMLf("，已选 %d 行", len(w.selected)).String())
+	}
+	b.WriteString("</span>")
+
+	if pages := w.pageCount(); pages > 1 {
+		first, last := w.page == 0, w.page == pages-1
+		b.WriteString("<span class=\"st-dataframe-pager\">")
+		b.WriteString(pageButton(0, "«", first))
+		b.WriteString(pageButton(w.page-1, "‹", first))
+		b.WriteString(HTMLf("<span>第 %d / %d 页</span>", w.page+1, pages).String())
+		b.WriteString(pageButton(w.page+1, "›", last))
+		b.WriteString(pageButton(pages-1, "»", last))
+		b.WriteString("</span>")
+	}
+	b.WriteString("</div>")
+}
+
+// pageButton 渲染翻页按钮
+func pageButton(page int, text string, disabled bool) string {
+	var attr SafeHTML
+	if disabled {
+		attr = " disabled"
+	}
+	return HTMLf("<button type=\"button\" class=\"st-dataframe-page\" data-page=\"%d\"%s>%s</button>", page, attr, text).String()
+}
+
+// checkedAttr 渲染复选框的checked属性
+func checkedAttr(checked bool) SafeHTML {
+	if checked {
+		return " checked"
+	}
+	return ""
+}
+
+// Clone 复制数据框组件，副本有独立的视图和选中的行
+func (w *DataFrameWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.selectionChanged = false
+	return &c
+}
@@ -0,0 +1,314 @@
+package widgets
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pageRows 获取当前页显示的行在数据中的位置
+func pageRows(w *DataFrameWidget) []int {
+	start, end := w.pageRange()
+	rows := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		rows = append(rows, w.viewRow(i))
+	}
+	return rows
+}
+
+// bind 发送事件并要求成功
+func bind(t *testing.T, w *DataFrameWidget, event, value string) {
+	t.Helper()
+	if err := w.BindValue(nil, event, value); err != nil {
+		t.Fatalf("%s %q: %v", event, value, err)
+	}
+}
+
+func TestDataFrameSort(t *testing.T) {
+	type item struct {
+		Name  string
+		Price *float64
+		Added time.Time
+		Qty   int
+		Note  string `st:"hidden"`
+	}
+	price := func(value float64) *float64 { return &value }
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	items := []*item{
+		{Name: "b", Price: price(2), Added: day(3), Qty: 10},
+		{Name: "a", Price: nil, Added: day(1), Qty: 9},
+		{Name: "c", Price: price(1), Added: day(2), Qty: 100},
+	}
+	frame := NewDataFrame(items)
+
+	tests := []struct {
+		column string
+		want   []int
+	}{
+		{"Qty", []int{1, 0, 2}},   // 按数值而不是文本排序
+		{"Qty", []int{2, 0, 1}},   // 再次点击降序
+		{"Qty", []int{0, 1, 2}},   // 第三次取消排序
+		{"Price", []int{2, 0, 1}}, // nil排在最后
+		{"Price", []int{0, 2, 1}}, // 降序时nil仍在最后
+		{"Added", []int{1, 2, 0}},
+		{"Name", []int{1, 0, 2}},
+	}
+	for _, tt := range tests {
+		bind(t, frame, "sort", tt.column)
+		if got := pageRows(frame); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort %s: rows %v, want %v", tt.column, got, tt.want)
+		}
+	}
+	if !strings.Contains(frame.Render(), `data-sort="Name" aria-sort="ascending"`) {
+		t.Errorf("sort state not rendered: %s", frame.Render())
+	}
+
+	// map切片中缺少的值排在最后
+	rows := []map[string]interface{}{
+		{"k": 3, "v": "x"},
+		{"v": "y"},
+		{"k": 1, "v": "z"},
+	}
+	maps := NewDataFrame(rows)
+	bind(t, maps, "sort", "k")
+	if got := pageRows(maps); !reflect.DeepEqual(got, []int{2, 0, 1}) {
+		t.Errorf("map sort rows %v", got)
+	}
+
+	// 隐藏的列、不存在的列和关闭排序后都不能排序
+	for _, column := range []string{"Note", "Missing", `"><script>`} {
+		if err := frame.BindValue(nil, "sort", column); err == nil {
+			t.Errorf("sort %q accepted", column)
+		}
+	}
+	frame.SetSortable(false)
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("disabling sort kept the order %v", got)
+	}
+	if err := frame.BindValue(nil, "sort", "Name"); err == nil {
+		t.Error("sort accepted while disabled")
+	}
+}
+
+func TestDataFrameSearchAndFilter(t *testing.T) {
+	type item struct {
+		Name  string
+		City  string
+		Price float64 `st:"format=%.2f"`
+		Note  string  `st:"hidden"`
+	}
+	items := []item{
+		{Name: "Apple", City: "北京", Price: 1.5, Note: "secret"},
+		{Name: "Pear", City: "上海", Price: 2},
+		{Name: "apricot", City: "上海", Price: 10},
+		{Name: "Plum", City: "", Price: 0},
+	}
+	frame := NewDataFrame(items)
+	frame.SetFilterable(true)
+	frame.SetPageSize(2)
+	bind(t, frame, "page", "1")
+
+	// 搜索不区分大小写，匹配显示的文本，重置到第一页
+	bind(t, frame, "search", " AP ")
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{0, 2}) || frame.page != 0 {
+		t.Errorf("search rows %v, page %d", got, frame.page)
+	}
+	bind(t, frame, "search", "1.50")
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("search formatted value: rows %v", got)
+	}
+	bind(t, frame, "search", "secret")
+	if got := pageRows(frame); len(got) != 0 || !strings.Contains(frame.Render(), "没有匹配的行") {
+		t.Errorf("search matched a hidden column: %v", got)
+	}
+	bind(t, frame, "search", "")
+
+	// 筛选按列匹配，多个条件同时满足，空值删除条件
+	bind(t, frame, "filter", `{"column":"City","value":"上海"}`)
+	bind(t, frame, "filter", `{"column":"Name","value":"A"}`)
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("filter rows %v", got)
+	}
+	bind(t, frame, "search", "ricot")
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("search with filters: rows %v", got)
+	}
+	bind(t, frame, "search", "")
+	bind(t, frame, "filter", `{"column":"City","value":""}`)
+	if got := frame.viewLen(); got != 3 {
+		t.Errorf("removing a filter: %d rows, want 3", got)
+	}
+	if output := frame.Render(); !strings.Contains(output, "匹配 3 / 4 行") {
+		t.Errorf("count not rendered: %s", output)
+	}
+
+	// 无效的筛选条件被拒绝，条件保持不变
+	for _, value := range []string{`{"column":"Note","value":"x"}`, `{"column":"Missing","value":"x"}`, `not json`} {
+		if err := frame.BindValue(nil, "filter", value); err == nil {
+			t.Errorf("filter %s accepted", value)
+		}
+	}
+	if got := frame.viewLen(); got != 3 {
+		t.Errorf("rejected filter changed the view: %d rows", got)
+	}
+
+	// 关闭后清除条件并拒绝事件
+	frame.SetFilterable(false)
+	frame.SetSearchable(false)
+	if frame.viewLen() != 4 {
+		t.Errorf("disabling filters kept %d rows", frame.viewLen())
+	}
+	if err := frame.BindValue(nil, "filter", `{"column":"Name","value":"p"}`); err == nil {
+		t.Error("filter accepted while disabled")
+	}
+	if err := frame.BindValue(nil, "search", "p"); err == nil {
+		t.Error("search accepted while disabled")
+	}
+}
+
+func TestDataFramePagination(t *testing.T) {
+	values := make([]int, 25)
+	for i := range values {
+		values[i] = i
+	}
+	frame := NewDataFrame(values)
+	frame.SetPageSize(10)
+
+	tests := []struct {
+		page  string
+		want  int // 期望的当前页
+		first int // 当前页的第一行
+		count int // 当前页的行数
+	}{
+		{"1", 1, 10, 10},
+		{"2", 2, 20, 5},
+		{"99", 2, 20, 5},
+		{"-1", 0, 0, 10},
+	}
+	for _, tt := range tests {
+		bind(t, frame, "page", tt.page)
+		rows := pageRows(frame)
+		if frame.page != tt.want || len(rows) != tt.count || rows[0] != tt.first {
+			t.Errorf("page %s: page %d, rows %v", tt.page, frame.page, rows)
+		}
+	}
+	if err := frame.BindValue(nil, "page", "x"); err == nil {
+		t.Error("invalid page accepted")
+	}
+
+	bind(t, frame, "page", "2")
+	output := frame.Render()
+	if !strings.Contains(output, "第 3 / 3 页") || !strings.Contains(output, `data-page="3" disabled`) || strings.Contains(output, "<td class=\"st-cell-number\">19</td>") {
+		t.Errorf("last page rendered %s", output)
+	}
+
+	// 筛选结果变少时回到有效的页
+	bind(t, frame, "search", "2")
+	if frame.page != 0 || frame.pageCount() != 1 {
+		t.Errorf("search kept page %d of %d", frame.page, frame.pageCount())
+	}
+	bind(t, frame, "search", "")
+	frame.SetPageSize(0)
+	if len(pageRows(frame)) != 25 || frame.pageCount() != 1 {
+		t.Errorf("page size 0 shows %d rows on %d pages", len(pageRows(frame)), frame.pageCount())
+	}
+}
+
+func TestDataFrameSelection(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	items := []*item{{"a", 3}, {"b", 1}, {"c", 2}, {"d", 4}}
+	frame := NewDataFrame(items)
+	frame.SetPageSize(2)
+	frame.SetSelectionMode(MultiRow)
+
+	var selected [][]interface{}
+	frame.OnSelect(func(session ISession, records []interface{}) {
+		selected = append(selected, records)
+	})
+
+	// 排序后选择的仍是数据中的行，回调收到原始元素
+	bind(t, frame, "sort", "Qty")
+	if got := pageRows(frame); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("sorted page %v", got)
+	}
+	bind(t, frame, "select", "2")
+	frame.TriggerCallbacks(nil, "select", "2")
+	bind(t, frame, "select", "0")
+	frame.TriggerCallbacks(nil, "select", "0")
+	if !reflect.DeepEqual(frame.GetSelectedRows(), []int{0, 2}) {
+		t.Errorf("selected rows %v", frame.GetSelectedRows())
+	}
+	if len(selected) != 2 || len(selected[1]) != 2 || selected[1][0] != items[0] || selected[1][1] != items[2] {
+		t.Errorf("callback records %v", selected)
+	}
+
+	// 再次选择取消选中；全选只影响当前页
+	bind(t, frame, "select", "2")
+	bind(t, frame, "select_page", "true")
+	if !reflect.DeepEqual(frame.GetSelectedRows(), []int{0, 1, 2}) {
+		t.Errorf("select page: rows %v", frame.GetSelectedRows())
+	}
+	output := frame.Render()
+	if !strings.Contains(output, `data-select-page="true" checked`) || !strings.Contains(output, "已选 3 行") {
+		t.Errorf("selection not rendered: %s", output)
+	}
+	bind(t, frame, "select_page", "false")
+	if !reflect.DeepEqual(frame.GetSelectedRows(), []int{0}) {
+		t.Errorf("unselect page: rows %v", frame.GetSelectedRows())
+	}
+
+	for _, value := range []string{"-1", "4", "x"} {
+		if err := frame.BindValue(nil, "select", value); err == nil {
+			t.Errorf("select %q accepted", value)
+		}
+	}
+
+	// 会话副本的视图和选中的行独立
+	clone := frame.Clone().(*DataFrameWidget)
+	bind(t, clone, "select", "3")
+	bind(t, clone, "sort", "Qty")
+	if !reflect.DeepEqual(frame.GetSelectedRows(), []int{0}) || frame.sortDesc {
+		t.Errorf("clone changed the original: rows %v, desc %v", frame.GetSelectedRows(), frame.sortDesc)
+	}
+
+	// 单选替换选中的行，数据变短时取消超出的行
+	clone.SetSelectionMode(SingleRow)
+	if !reflect.DeepEqual(clone.GetSelectedRows(), []int{0}) {
+		t.Errorf("single row kept %v", clone.GetSelectedRows())
+	}
+	bind(t, clone, "select", "3")
+	if err := clone.BindValue(nil, "select_page", "true"); err == nil {
+		t.Error("select page accepted in single row mode")
+	}
+	clone.SetData(items[:2])
+	if len(clone.GetSelectedRows()) != 0 {
+		t.Errorf("rows beyond the data kept: %v", clone.GetSelectedRows())
+	}
+
+	// map切片和标量切片返回对应的元素
+	maps := NewDataFrame([]map[string]int{{"a": 1}, {"a": 2}})
+	maps.SetSelectionMode(SingleRow)
+	bind(t, maps, "select", "1")
+	if records := maps.GetSelectedRecords(); len(records) != 1 || records[0].(map[string]int)["a"] != 2 {
+		t.Errorf("map records %v", records)
+	}
+	scalars := NewDataFrame([]string{"x", "y"})
+	scalars.SetSelectionMode(SingleRow)
+	bind(t, scalars, "select", "0")
+	if records := scalars.GetSelectedRecords(); !reflect.DeepEqual(records, []interface{}{"x"}) {
+		t.Errorf("scalar records %v", records)
+	}
+
+	frame.SetSelectionMode(NoSelection)
+	if len(frame.GetSelectedRows()) != 0 {
+		t.Error("disabling selection kept the rows")
+	}
+	if err := frame.BindValue(nil, "select", strconv.Itoa(0)); err == nil {
+		t.Error("select accepted while disabled")
+	}
+}
@@ -0,0 +1,161 @@
+package widgets
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// downloadTypes 系统MIME表中可能缺失的常用下载类型
+var downloadTypes = map[string]string{
+	".csv":  "text/csv; charset=utf-8",
+	".tsv":  "text/tab-separated-values; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".json": "application/json",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".xls":  "application/vnd.ms-excel",
+	".zip":  "application/zip",
+	".pdf":  "application/pdf",
+}
+
+// IDownloader 下载组件接口
+//
+// 组件被点击后，服务为当前会话生成一次性下载地址并通过SetDownloadURL交给组件渲染，
+// 客户端随即访问该地址，服务在会话锁内清除地址并通过Download取得下载内容，释放锁后设置响应头并调用Write把内容流式写入响应。
+type IDownloader interface {
+	Download() Download
+	SetDownloadURL(url string)
+}
+
+// Download 一次下载的文件名、MIME类型和内容，Write在会话锁外调用，不能访问组件的可变状态
+type Download struct {
+	FileName    string
+	ContentType string
+	Write       func(session ISession, w io.Writer) error
+}
+
+// DownloadButtonWidget 下载按钮组件
+//
+// 内容在用户点击后、浏览器请求下载地址时才生成，直接写入HTTP响应而不在内存中缓冲。
+type DownloadButtonWidget struct {
+	*BaseWidget
+	label       string
+	fileName    string
+	contentType string
+	generate    func(session ISession, w io.Writer) error
+	url         string // 等待客户端访问的一次性下载地址
+}
+
+// NewDownloadButton 创建下载固定内容的下载按钮组件
+func NewDownloadButton(label, fileName string, data []byte) *DownloadButtonWidget {
+	w := newDownloadButton(label, fileName)
+	w.SetData(data)
+	return w
+}
+
+// NewDownloadButtonReader 创建下载按钮组件，每次下载时调用open获取内容
+//
+// open返回的读取器实现io.Closer时在下载结束后关闭。
+func NewDownloadButtonReader(label, fileName string, open func(session ISession) (io.Reader, error)) *DownloadButtonWidget {
+	w := newDownloadButton(label, fileName)
+	w.generate = func(session ISession, out io.Writer) error {
+		reader, err := open(session)
+		if err != nil {
+			return err
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		_, err = io.Copy(out, reader)
+		return err
+	}
+	return w
+}
+
+// NewDownloadButtonFunc 创建下载按钮组件，每次下载时调用generate把内容写入w
+//
+// 适合导出较大的报表：generate边生成边写入，内容直接发送给浏览器。
+func NewDownloadButtonFunc(label, fileName string, generate func(session ISession, w io.Writer) error) *DownloadButtonWidget {
+	w := newDownloadButton(label, fileName)
+	w.SetGenerateFunc(generate)
+	return w
+}
+
+// newDownloadButton 创建下载按钮组件，内容类型由文件扩展名推断
+func newDownloadButton(label, fileName string) *DownloadButtonWidget {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	contentType := downloadTypes[ext]
+	if contentType == "" {
+		contentType = mime.TypeByExtension(ext)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &DownloadButtonWidget{
+		BaseWidget:  NewBaseWidget("download_button"),
+		label:       label,
+		fileName:    fileName,
+		contentType: contentType,
+	}
+}
+
+// SetData 设置下载的固定内容
+func (w *DownloadButtonWidget) SetData(data []byte) {
+	w.generate = func(session ISession, out io.Writer) error {
+		_, err := out.Write(data)
+		return err
+	}
+}
+
+// SetGenerateFunc 设置每次下载时生成内容的函数
+func (w *DownloadButtonWidget) SetGenerateFunc(generate func(session ISession, w io.Writer) error) {
+	w.generate = generate
+}
+
+// SetContentType 设置下载内容的MIME类型，覆盖由扩展名推断的类型
+func (w *DownloadButtonWidget) SetContentType(contentType string) {
+	w.contentType = contentType
+}
+
+// Download 获取当前的文件名、MIME类型和生成内容的函数
+func (w *DownloadButtonWidget) Download() Download {
+	generate := w.generate
+	if generate == nil {
+		id := w.GetID()
+		generate = func(session ISession, out io.Writer) error {
+			return fmt.Errorf("download button %s: no content", id)
+		}
+	}
+	return Download{
+		FileName:    w.fileName,
+		ContentType: w.contentType,
+		Write:       generate,
+	}
+}
+
+// SetDownloadURL 设置等待客户端访问的下载地址，空字符串表示没有待下载的内容
+func (w *DownloadButtonWidget) SetDownloadURL(url string) {
+	w.url = url
+}
+
+// Render 渲染下载按钮组件为HTML
+func (w *DownloadButtonWidget) Render() string {
+	id := w.GetID()
+	var urlAttr SafeHTML
+	if w.url != "" {
+		urlAttr = HTMLf(" data-download-url=\"%s\"", w.url)
+	}
+	return HTMLf("<button class=\"st-button st-download-button\" data-widget-id=\"%s\" id=\"%s\" data-event-type=\"click\"%s>%s</button>",
+		id, id, urlAttr, w.label).String()
+}
+
+// Clone 复制下载按钮组件
+func (w *DownloadButtonWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.url = ""
+	return &c
+}
@@ -0,0 +1,234 @@
+package widgets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FormValues 表单提交时各字段的原始值，组件ID -> 客户端发送的值
+type FormValues map[string]string
+
+// Value 获取指定字段的原始值
+func (v FormValues) Value(field Widget) (string, bool) {
+	value, exists := v[field.GetID()]
+	return value, exists
+}
+
+// formField 客户端提交的单个字段
+type formField struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	Value string `json:"value"`
+}
+
+// formSubmission 客户端提交的表单数据
+type formSubmission struct {
+	Submitter string      `json:"submitter"`
+	Fields    []formField `json:"fields"`
+}
+
+// FormWidget 表单容器组件
+//
+// 表单中的输入组件只在客户端记录输入，点击表单提交按钮时所有字段的值作为一个submit事件一次发送：
+// 先依次绑定到各字段，再调用提交回调。
+type FormWidget struct {
+	*BaseWidget
+	border         bool
+	clearOnSubmit  bool
+	children       []Widget
+	submitted      FormValues
+	submitter      string
+	err            string // 最近一次提交的校验错误
+	submitHandlers []func(session ISession, values FormValues) error
+	beforeSubmit   func(form *FormWidget, session ISession, values FormValues) error // 字段绑定后、回调前对当前实例执行的处理
+}
+
+// NewForm 创建新的表单组件
+func NewForm(border bool) *FormWidget {
+	return &FormWidget{
+		BaseWidget: NewBaseWidget("form"),
+		border:     border,
+		children:   make([]Widget, 0),
+	}
+}
+
+// AddChild 添加子组件
+func (w *FormWidget) AddChild(child Widget) {
+	w.children = append(w.children, child)
+	attachChild(w, child)
+}
+
+// Children 获取子组件
+func (w *FormWidget) Children() []Widget {
+	return w.children
+}
+
+// RemoveChild 移除子组件
+func (w *FormWidget) RemoveChild(componentID string) bool {
+	var removed bool
+	w.children, removed = removeChild(w.children, componentID)
+	return removed
+}
+
+// ReplaceChild 替换子组件
+func (w *FormWidget) ReplaceChild(componentID string, widget Widget) bool {
+	return replaceChild(w.children, componentID, widget)
+}
+
+// SetClearOnSubmit 设置提交成功后是否将字段恢复为创建时的值，字段需要实现IResettable
+func (w *FormWidget) SetClearOnSubmit(clear bool) {
+	w.clearOnSubmit = clear
+}
+
+// OnSubmit 设置提交回调函数，所有字段绑定成功后按添加顺序调用
+//
+// 回调返回错误时停止调用后续回调，错误显示在表单中，字段保持提交的值。
+func (w *FormWidget) OnSubmit(callback func(session ISession, values FormValues) error) {
+	w.submitHandlers = append(w.submitHandlers, callback)
+}
+
+// GetError 获取最近一次提交的错误，没有错误时返回空字符串
+func (w *FormWidget) GetError() string {
+	return w.err
+}
+
+// Render 渲染表单组件为HTML
+func (w *FormWidget) Render() string {
+	class := "st-form"
+	if w.border {
+		class += " st-form-with-border"
+	}
+
+	var errorHTML SafeHTML
+	if w.err != "" {
+		errorHTML = HTMLf("<div class=\"st-form-error\">%s</div>", w.err)
+	}
+
+	return HTMLf("<div class=\"%s\" data-widget-id=\"%s\">%s%s</div>", class, w.GetID(), renderChildren(w.children), errorHTML).String()
+}
+
+// Clone 复制表单组件
+func (w *FormWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.children = cloneChildren(w.children)
+	c.submitted = nil
+	return &c
+}
+
+// BindValue 将提交的值依次绑定到各字段
+//
+// 所有字段都会尝试绑定，任一字段失败时返回错误，回调不会被触发，
+// 绑定成功的字段保留提交的值，失败的字段自行显示错误信息。
+func (w *FormWidget) BindValue(session ISession, event string, value string) error {
+	if event != "submit" {
+		return nil
+	}
+
+	var submission formSubmission
+	if err := json.Unmarshal([]byte(value), &submission); err != nil {
+		return fmt.Errorf("form %s: invalid submission: %w", w.GetID(), err)
+	}
+
+	index := NewIndex(w.children)
+	values := make(FormValues, len(submission.Fields))
+	var errs []error
+	for _, field := range submission.Fields {
+		widget, exists := index.Lookup(field.ID)
+		if !exists {
+			continue
+		}
+		values[field.ID] = field.Value
+
+		binder, ok := widget.(IValueBinder)
+		if !ok {
+			continue
+		}
+		if err := binder.BindValue(session, field.Event, field.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		w.err = ""
+		w.submitted = nil
+		return fmt.Errorf("form %s: %w", w.GetID(), errors.Join(errs...))
+	}
+
+	w.submitted = values
+	w.submitter = submission.Submitter
+	return nil
+}
+
+// TriggerCallbacks 提交时调用提交回调和提交按钮的回调，再触发表单自身的回调
+func (w *FormWidget) TriggerCallbacks(session ISession, event string, value string) {
+	if event == "submit" && w.submitted != nil {
+		values := w.submitted
+		w.submitted = nil
+		w.err = ""
+
+		if w.beforeSubmit != nil {
+			if err := w.beforeSubmit(w, session, values); err != nil {
+				w.err = err.Error()
+			}
+		}
+
+		for _, handler := range w.submitHandlers {
+			if w.err != "" {
+				break
+			}
+			if err := handler(session, values); err != nil {
+				w.err = err.Error()
+			}
+		}
+
+		if submitter, ok := FindWidget(w.children, w.submitter).(*FormSubmitButtonWidget); ok && w.err == "" {
+			submitter.TriggerCallbacks(session, "click", "")
+		}
+
+		if w.err == "" && w.clearOnSubmit {
+			w.reset()
+		}
+	}
+
+	w.BaseWidget.TriggerCallbacks(session, event, value)
+}
+
+// reset 将表单中的输入组件原地恢复为初始值，应用持有的组件引用随之变化
+func (w *FormWidget) reset() {
+	Walk(w.children, func(widget Widget, parent Widget) bool {
+		if resettable, ok := widget.(IResettable); ok {
+			resettable.Reset()
+		}
+		return true
+	})
+}
+
+// FormSubmitButtonWidget 表单提交按钮组件，点击时提交所在的表单
+type FormSubmitButtonWidget struct {
+	*BaseWidget
+	label string
+}
+
+// NewFormSubmitButton 创建新的表单提交按钮组件，需要添加到FormWidget中
+func NewFormSubmitButton(label string) *FormSubmitButtonWidget {
+	return &FormSubmitButtonWidget{
+		BaseWidget: NewBaseWidget("form_submit_button"),
+		label:      label,
+	}
+}
+
+// Render 渲染表单提交按钮组件为HTML
+func (w *FormSubmitButtonWidget) Render() string {
+	id := w.GetID()
+	return HTMLf("<button class=\"st-button st-form-submit\" data-widget-id=\"%s\" id=\"%s\" data-event-type=\"submit\">%s</button>",
+		id, id, w.label).String()
+}
+
+// Clone 复制表单提交按钮组件
+func (w *FormSubmitButtonWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
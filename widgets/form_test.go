@@ -0,0 +1,60 @@
+package widgets
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// submitForm 按客户端的格式提交表单字段，fields为组件ID -> 值
+func submitForm(t *testing.T, form *FormWidget, fields map[string]string) {
+	t.Helper()
+	submission := formSubmission{}
+	for id, value := range fields {
+		submission.Fields = append(submission.Fields, formField{ID: id, Event: "input", Value: value})
+	}
+	data, err := json.Marshal(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := form.BindValue(nil, "submit", string(data)); err != nil {
+		t.Fatalf("bind submission: %v", err)
+	}
+	form.TriggerCallbacks(nil, "submit", string(data))
+}
+
+// TestFormClearOnSubmit 提交成功后字段原地恢复初始值，通过原来的引用读取
+func TestFormClearOnSubmit(t *testing.T) {
+	form := NewForm(true)
+	form.SetClearOnSubmit(true)
+	name := NewTextInput("姓名", "默认")
+	count := NewNumberInput("数量", 1)
+	form.AddChild(name)
+	form.AddChild(count)
+	form.AddChild(NewFormSubmitButton("提交"))
+
+	var submitted []string
+	form.OnSubmit(func(session ISession, values FormValues) error {
+		// 回调中读取的是本次提交的值
+		submitted = append(submitted, name.GetValue())
+		if count.GetValue() != 5 {
+			t.Errorf("count in callback = %v, want 5", count.GetValue())
+		}
+		return nil
+	})
+
+	for i, value := range []string{"张三", "李四"} {
+		submitForm(t, form, map[string]string{name.GetID(): value, count.GetID(): "5"})
+		if form.GetError() != "" {
+			t.Fatalf("submit %d: unexpected error %s", i, form.GetError())
+		}
+		if name.GetValue() != "默认" || count.GetValue() != 1 {
+			t.Errorf("submit %d: fields not reset: %q %v", i, name.GetValue(), count.GetValue())
+		}
+		if FindWidget(form.Children(), name.GetID()) != name {
+			t.Errorf("submit %d: field replaced instead of reset", i)
+		}
+	}
+	if len(submitted) != 2 || submitted[0] != "张三" || submitted[1] != "李四" {
+		t.Errorf("submitted values = %v", submitted)
+	}
+}
@@ -0,0 +1,195 @@
+package widgets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // 注册GIF解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ImageWidget 图片组件
+//
+// 设置显示宽度后，原图宽度超过显示宽度两倍时自动生成缩略图，页面加载缩略图，点击打开原图。
+type ImageWidget struct {
+	*BaseWidget
+	media     *MediaFile
+	thumbnail *MediaFile
+	decoded   image.Image // 由image.Image创建时保留，生成缩略图时无需重新解码
+	original  int         // 原图宽度，无法解码（例如SVG）时为0
+	width     int
+	caption   string
+}
+
+// NewImage 创建新的图片组件，source可以是image.Image、[]byte、文件路径、io.ReadSeeker或*MediaFile
+//
+// image.Image以PNG格式提供，其他来源保持原格式。内容不是图片时返回错误。
+func NewImage(source interface{}) (*ImageWidget, error) {
+	w := &ImageWidget{
+		BaseWidget: NewBaseWidget("image"),
+	}
+
+	if img, ok := source.(image.Image); ok {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		w.media = NewMediaBytes(buf.Bytes(), "image/png")
+		w.decoded = img
+		w.original = img.Bounds().Dx()
+		return w, nil
+	}
+
+	media, err := newMedia(source)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(media.ContentType(), "image/") {
+		return nil, fmt.Errorf("image: unsupported content type %s", media.ContentType())
+	}
+	w.media = media
+
+	// 只读取图片头部获取尺寸，需要缩略图时才完整解码
+	if r, err := media.Open(); err == nil {
+		if config, _, err := image.DecodeConfig(r); err == nil {
+			w.original = config.Width
+		}
+		if closer, ok := r.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	return w, nil
+}
+
+// SetWidth 设置显示宽度（像素），0表示原始宽度
+//
+// 原图宽度超过显示宽度两倍时生成两倍显示宽度的缩略图，兼顾高分辨率屏幕。缩略图生成失败时显示原图。
+func (w *ImageWidget) SetWidth(width int) {
+	w.width = width
+	w.thumbnail = nil
+	if width <= 0 || w.original <= 2*width {
+		return
+	}
+
+	img := w.decoded
+	if img == nil {
+		r, err := w.media.Open()
+		if err != nil {
+			return
+		}
+		img, _, err = image.Decode(r)
+		if closer, ok := r.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	thumbnail := scaleImage(img, 2*width)
+	var buf bytes.Buffer
+	contentType := "image/png"
+	var err error
+	if w.media.ContentType() == "image/jpeg" {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err == nil {
+		w.thumbnail = NewMediaBytes(buf.Bytes(), contentType)
+	}
+}
+
+// SetCaption 设置图片说明，同时作为图片的替代文本
+func (w *ImageWidget) SetCaption(caption string) {
+	w.caption = caption
+}
+
+// MediaFiles 获取引用的媒体内容
+func (w *ImageWidget) MediaFiles() []*MediaFile {
+	if w.thumbnail != nil {
+		return []*MediaFile{w.media, w.thumbnail}
+	}
+	return []*MediaFile{w.media}
+}
+
+// Render 渲染图片组件为HTML
+func (w *ImageWidget) Render() string {
+	src := w.media
+	if w.thumbnail != nil {
+		src = w.thumbnail
+	}
+
+	var widthAttr SafeHTML
+	if w.width > 0 {
+		widthAttr = HTMLf(" width=\"%d\"", w.width)
+	}
+	img := HTMLf("<img src=\"%s\" alt=\"%s\" loading=\"lazy\"%s>", src.URL(), w.caption, widthAttr)
+	if w.thumbnail != nil {
+		img = HTMLf("<a href=\"%s\" target=\"_blank\" rel=\"noopener\">%s</a>", w.media.URL(), img)
+	}
+
+	var captionHTML SafeHTML
+	if w.caption != "" {
+		captionHTML = HTMLf("<figcaption class=\"st-image-caption\">%s</figcaption>", w.caption)
+	}
+
+	return HTMLf("<figure class=\"st-image\" data-widget-id=\"%s\">%s%s</figure>", w.GetID(), img, captionHTML).String()
+}
+
+// Clone 复制图片组件，副本与原组件共享媒体内容
+func (w *ImageWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// scaleImage 按区域平均把图片缩小到指定宽度，保持宽高比
+func scaleImage(src image.Image, width int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	height := srcH * width / srcW
+	if height < 1 {
+		height = 1
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, (y+1)*srcH/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, (x+1)*srcW/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var sum [4]uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += uint64(rgba.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+
+			count := uint64((y1 - y0) * (x1 - x0))
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+	return dst
+}
@@ -2,8 +2,10 @@ package widgets
 
 import (
 	"fmt"
-	"html"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // TextInputWidget 文本输入组件
@@ -11,6 +13,7 @@ type TextInputWidget struct {
 	*BaseWidget
 	label       string
 	value       string
+	initial     string // 创建时的值，Reset时恢复
 	placeholder string
 }
 
@@ -20,6 +23,7 @@ func NewTextInput(label string, value string) *TextInputWidget {
 		BaseWidget: NewBaseWidget("text_input"),
 		label:      label,
 		value:      value,
+		initial:    value,
 	}
 
 	return w
@@ -27,12 +31,19 @@ func NewTextInput(label string, value string) *TextInputWidget {
 
 // Render 渲染文本输入组件为HTML
 func (w *TextInputWidget) Render() string {
-	placeholderAttr := ""
+	var placeholderAttr SafeHTML
 	if w.placeholder != "" {
-		placeholderAttr = fmt.Sprintf(" placeholder=\"%s\"", html.EscapeString(w.placeholder))
+		placeholderAttr = HTMLf(" placeholder=\"%s\"", w.placeholder)
 	}
-	return fmt.Sprintf("<div class=\"st-text-input-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"text\" class=\"st-text-input\" data-widget-id=\"%s\" data-event-type=\"input\" value=\"%s\"%s></div>",
-		w.GetID(), html.EscapeString(w.label), w.GetID(), html.EscapeString(w.value), placeholderAttr)
+	return HTMLf("<div class=\"st-text-input-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"text\" class=\"st-text-input\" data-widget-id=\"%s\" data-event-type=\"input\" value=\"%s\"%s></div>",
+		w.GetID(), w.label, w.GetID(), w.value, placeholderAttr).String()
+}
+
+// Clone 复制文本输入组件
+func (w *TextInputWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // SetValue 设置文本输入值
@@ -41,6 +52,20 @@ func (w *TextInputWidget) SetValue(session ISession, value string) {
 	w.TriggerCallbacks(session, "input", value)
 }
 
+// BindValue 保存客户端输入的文本
+func (w *TextInputWidget) BindValue(session ISession, event string, value string) error {
+	if event != "input" {
+		return nil
+	}
+	w.value = value
+	return nil
+}
+
+// Reset 恢复创建时的值
+func (w *TextInputWidget) Reset() {
+	w.value = w.initial
+}
+
 // GetValue 获取文本输入值
 func (w *TextInputWidget) GetValue() string {
 	return w.value
@@ -54,9 +79,12 @@ func (w *TextInputWidget) SetPlaceholder(placeholder string) {
 // NumberInputWidget 数字输入组件
 type NumberInputWidget struct {
 	*BaseWidget
-	label string
-	value float64
-	step  float64
+	label   string
+	value   float64
+	initial float64 // 创建时的值，Reset时恢复
+	step    float64
+	text    string // 客户端输入的原始文本，没有错误且为空时按value显示
+	err     string // 最近一次输入的解析错误
 }
 
 // NewNumberInput 创建新的数字输入组件
@@ -65,6 +93,7 @@ func NewNumberInput(label string, value float64) *NumberInputWidget {
 		BaseWidget: NewBaseWidget("number_input"),
 		label:      label,
 		value:      value,
+		initial:    value,
 		step:       1,
 	}
 
@@ -73,16 +102,76 @@ func NewNumberInput(label string, value float64) *NumberInputWidget {
 
 // Render 渲染数字输入组件为HTML
 func (w *NumberInputWidget) Render() string {
-	return fmt.Sprintf("<div class=\"st-number-input-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"number\" class=\"st-number-input\" data-widget-id=\"%s\" data-event-type=\"input\" value=\"%g\" step=\"%g\"></div>",
-		w.GetID(), html.EscapeString(w.label), w.GetID(), w.value, w.step)
+	var errorHTML SafeHTML
+	if w.err != "" {
+		errorHTML = HTMLf("<div class=\"st-input-error\">%s</div>", w.err)
+	}
+	text := w.text
+	if text == "" && w.err == "" {
+		text = strconv.FormatFloat(w.value, 'g', -1, 64)
+	}
+	return HTMLf("<div class=\"st-number-input-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"number\" class=\"st-number-input\" data-widget-id=\"%s\" data-event-type=\"input\" value=\"%s\" step=\"%g\">%s</div>",
+		w.GetID(), w.label, w.GetID(), text, w.step, errorHTML).String()
+}
+
+// Clone 复制数字输入组件
+func (w *NumberInputWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // SetValue 设置数字输入值
 func (w *NumberInputWidget) SetValue(session ISession, value float64) {
 	w.value = value
+	w.text = ""
+	w.err = ""
 	w.TriggerCallbacks(session, "input", strconv.FormatFloat(value, 'g', -1, 64))
 }
 
+// BindValue 将客户端输入解析为浮点数并保存
+//
+// 输入为空时保持原值，清除之前的错误。不是数字或超出float64范围（包括NaN和Inf）时保持原值，
+// 组件显示用户输入的原始文本并在下方显示错误。
+func (w *NumberInputWidget) BindValue(session ISession, event string, value string) error {
+	if event != "input" {
+		return nil
+	}
+
+	raw := value
+	value = strings.TrimSpace(value)
+	if value == "" {
+		// 用户清空输入框或输入尚未完成（如单独的"-"），结构体表单中的可选字段也依赖此行为
+		w.text = ""
+		w.err = ""
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		w.text = raw
+		w.err = fmt.Sprintf("无效的数字: %s", value)
+		return fmt.Errorf("number input %s: invalid number %q", w.GetID(), value)
+	}
+
+	w.value = number
+	w.text = value
+	w.err = ""
+	return nil
+}
+
+// Reset 恢复创建时的值并清除错误
+func (w *NumberInputWidget) Reset() {
+	w.value = w.initial
+	w.text = ""
+	w.err = ""
+}
+
+// GetError 获取最近一次输入的解析错误，没有错误时返回空字符串
+func (w *NumberInputWidget) GetError() string {
+	return w.err
+}
+
 // GetValue 获取数字输入值
 func (w *NumberInputWidget) GetValue() float64 {
 	return w.value
@@ -92,3 +181,97 @@ func (w *NumberInputWidget) GetValue() float64 {
 func (w *NumberInputWidget) SetStep(step float64) {
 	w.step = step
 }
+
+// DateInputWidget 日期输入组件
+type DateInputWidget struct {
+	*BaseWidget
+	label   string
+	value   time.Time
+	initial time.Time // 创建时的值，Reset时恢复
+	err     string    // 最近一次输入的解析错误
+}
+
+// dateLayout 日期输入组件与客户端交换的日期格式
+const dateLayout = "2006-01-02"
+
+// NewDateInput 创建新的日期输入组件，value为零值时不显示日期
+func NewDateInput(label string, value time.Time) *DateInputWidget {
+	return &DateInputWidget{
+		BaseWidget: NewBaseWidget("date_input"),
+		label:      label,
+		value:      value,
+		initial:    value,
+	}
+}
+
+// Render 渲染日期输入组件为HTML
+func (w *DateInputWidget) Render() string {
+	value := ""
+	if !w.value.IsZero() {
+		value = w.value.Format(dateLayout)
+	}
+	var errorHTML SafeHTML
+	if w.err != "" {
+		errorHTML = HTMLf("<div class=\"st-input-error\">%s</div>", w.err)
+	}
+	return HTMLf("<div class=\"st-date-input-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"date\" class=\"st-date-input\" data-widget-id=\"%s\" data-event-type=\"change\" value=\"%s\">%s</div>",
+		w.GetID(), w.label, w.GetID(), value, errorHTML).String()
+}
+
+// Clone 复制日期输入组件
+func (w *DateInputWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetValue 设置日期
+func (w *DateInputWidget) SetValue(session ISession, value time.Time) {
+	w.value = value
+	w.err = ""
+	text := ""
+	if !value.IsZero() {
+		text = value.Format(dateLayout)
+	}
+	w.TriggerCallbacks(session, "change", text)
+}
+
+// BindValue 将客户端发送的日期解析后保存，空值表示清除日期
+func (w *DateInputWidget) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		w.value = time.Time{}
+		w.err = ""
+		return nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		w.err = fmt.Sprintf("无效的日期: %s", value)
+		return fmt.Errorf("date input %s: invalid date %q", w.GetID(), value)
+	}
+
+	w.value = date
+	w.err = ""
+	return nil
+}
+
+// Reset 恢复创建时的值并清除错误
+func (w *DateInputWidget) Reset() {
+	w.value = w.initial
+	w.err = ""
+}
+
+// GetError 获取最近一次输入的解析错误，没有错误时返回空字符串
+func (w *DateInputWidget) GetError() string {
+	return w.err
+}
+
+// GetValue 获取日期，未选择时返回零值
+func (w *DateInputWidget) GetValue() time.Time {
+	return w.value
+}
@@ -0,0 +1,82 @@
+package widgets
+
+import (
+	"html"
+	"strings"
+	"testing"
+)
+
+// TestNumberInputBindValue 无效输入保持原值，组件显示原始文本和错误；空输入保持原值；有效输入清除错误
+func TestNumberInputBindValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    float64
+		invalid bool
+	}{
+		{"integer", "5", 5, false},
+		{"decimal with spaces", " 2.5 ", 2.5, false},
+		{"exponent", "1e3", 1000, false},
+		{"negative", "-0.25", -0.25, false},
+		{"non-numeric", "abc", 7, true},
+		{"trailing text", "12kg", 7, true},
+		{"overflow", "1e400", 7, true},
+		{"negative overflow", "-1e400", 7, true},
+		{"nan", "NaN", 7, true},
+		{"inf", "Inf", 7, true},
+		{"negative inf", "-Infinity", 7, true},
+		{"empty", "", 7, false},
+		{"blank", "   ", 7, false},
+		{"hostile", `"><script>alert(1)</script>`, 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewNumberInput("数量", 7)
+			err := w.BindValue(nil, "input", tt.input)
+			if tt.invalid != (err != nil) {
+				t.Fatalf("error = %v, want invalid %v", err, tt.invalid)
+			}
+			if w.GetValue() != tt.want {
+				t.Errorf("value = %v, want %v", w.GetValue(), tt.want)
+			}
+			output := w.Render()
+			if !tt.invalid {
+				if w.GetError() != "" || strings.Contains(output, "st-input-error") {
+					t.Errorf("valid input shows an error: %s", output)
+				}
+				return
+			}
+			if w.GetError() == "" || !strings.Contains(output, "st-input-error") {
+				t.Errorf("invalid input shows no error: %s", output)
+			}
+			if want := `value="` + html.EscapeString(tt.input) + `"`; !strings.Contains(output, want) {
+				t.Errorf("output does not show the raw text %s: %s", want, output)
+			}
+			assertEscaped(t, output, tt.input)
+		})
+	}
+
+	// 修正输入后清除错误并显示新值
+	w := NewNumberInput("数量", 7)
+	w.BindValue(nil, "input", "abc")
+	if err := w.BindValue(nil, "input", "8"); err != nil || w.GetValue() != 8 || w.GetError() != "" {
+		t.Fatalf("corrected input: value %v, error %q, %v", w.GetValue(), w.GetError(), err)
+	}
+	if output := w.Render(); !strings.Contains(output, `value="8"`) || strings.Contains(output, "abc") {
+		t.Errorf("corrected input renders %s", output)
+	}
+
+	// 清空输入框保持原值并清除之前的错误
+	w.BindValue(nil, "input", "abc")
+	if err := w.BindValue(nil, "input", ""); err != nil || w.GetValue() != 8 || w.GetError() != "" {
+		t.Fatalf("cleared input: value %v, error %q, %v", w.GetValue(), w.GetError(), err)
+	}
+	if output := w.Render(); !strings.Contains(output, `value="8"`) {
+		t.Errorf("cleared input renders %s", output)
+	}
+
+	// 其他事件不改变值
+	if err := w.BindValue(nil, "click", "abc"); err != nil || w.GetValue() != 8 || w.GetError() != "" {
+		t.Errorf("click event changed the input: %v %q %v", w.GetValue(), w.GetError(), err)
+	}
+}
@@ -0,0 +1,110 @@
+package widgets
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+)
+
+// ErrDuplicateKey 同一作用域中存在Key相同的组件
+var ErrDuplicateKey = errors.New("duplicate widget key")
+
+// keyScoped 参与Key作用域解析的组件，由BaseWidget实现
+type keyScoped interface {
+	// keyScope 组件为子组件提供的作用域路径
+	keyScope() string
+
+	// setParentScope 设置父组件的作用域路径，设置了Key的组件同时更新ID
+	setParentScope(scope string)
+}
+
+// KeyID 根据父组件作用域路径和Key生成稳定的组件ID
+//
+// 相同的路径和Key在不同会话、不同进程中总是得到相同的ID，
+// 因此服务重启前发出的事件仍能找到同一个组件。
+func KeyID(parentScope string, key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(joinScope(parentScope, key)))
+	return fmt.Sprintf("key_%x", h.Sum64())
+}
+
+// joinScope 将Key追加到作用域路径，Key中的"/"等字符会被转义
+func joinScope(parentScope string, key string) string {
+	return parentScope + "/" + url.PathEscape(key)
+}
+
+// AssignKeys 为组件树中设置了Key的组件生成ID并检查重复
+//
+// 作用域路径由祖先组件中设置了Key的组件依次组成，顶层组件的作用域为空。
+// 存在重复Key时返回包装ErrDuplicateKey的错误，此时组件树中会有ID相同的组件。
+func AssignKeys(roots []Widget) error {
+	seen := make(map[string]Widget)
+	var err error
+	for _, root := range roots {
+		if e := resolveKeys("", root, seen); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// CheckKeys 检查组件树中设置了Key的组件是否与existing中的组件重复
+func CheckKeys(existing []Widget, widget Widget) error {
+	seen := make(map[string]Widget)
+	Walk(existing, func(w Widget, parent Widget) bool {
+		if w.GetKey() != "" {
+			seen[w.GetID()] = w
+		}
+		return true
+	})
+
+	var err error
+	Walk([]Widget{widget}, func(w Widget, parent Widget) bool {
+		if w.GetKey() == "" {
+			return true
+		}
+		if other, exists := seen[w.GetID()]; exists && other != w {
+			err = fmt.Errorf("%w: %q", ErrDuplicateKey, w.GetKey())
+			return false
+		}
+		seen[w.GetID()] = w
+		return true
+	})
+	return err
+}
+
+// resolveKeys 按父组件作用域递归解析组件子树的ID，seen为nil时不检查重复
+func resolveKeys(parentScope string, widget Widget, seen map[string]Widget) error {
+	scope := parentScope
+	if scoped, ok := widget.(keyScoped); ok {
+		scoped.setParentScope(parentScope)
+		scope = scoped.keyScope()
+	}
+
+	var err error
+	if key := widget.GetKey(); key != "" && seen != nil {
+		if other, exists := seen[widget.GetID()]; exists && other != widget {
+			err = fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+		}
+		seen[widget.GetID()] = widget
+	}
+
+	if p, ok := widget.(IParent); ok {
+		for _, child := range p.Children() {
+			if e := resolveKeys(scope, child, seen); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// attachChild 子组件加入容器时按容器的作用域解析其ID
+func attachChild(parent Widget, child Widget) {
+	scope := ""
+	if scoped, ok := parent.(keyScoped); ok {
+		scope = scoped.keyScope()
+	}
+	resolveKeys(scope, child, nil)
+}
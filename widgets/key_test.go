@@ -0,0 +1,114 @@
+package widgets
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestKeyIDStable Key生成的ID只由作用域路径和Key决定，服务重启后保持不变
+func TestKeyIDStable(t *testing.T) {
+	// 固定值：ID变化会使重启前页面发出的事件找不到组件
+	if id := KeyID("", "name"); id != "key_eeffd0c98519521d" {
+		t.Errorf("KeyID(\"\", \"name\") = %s", id)
+	}
+	if id := KeyID("/settings", "name"); id != "key_47df70a32aaa5da7" {
+		t.Errorf("KeyID(\"/settings\", \"name\") = %s", id)
+	}
+
+	// 模拟两次启动：构造顺序和之前创建的组件数量不同，ID相同
+	build := func(extra int) (*ContainerWidget, *TextInputWidget) {
+		for i := 0; i < extra; i++ {
+			NewText("其他组件")
+		}
+		input := NewTextInput("名称", "")
+		input.SetKey("name")
+		container := NewContainer(false)
+		container.SetKey("settings")
+		container.AddChild(input)
+		return container, input
+	}
+	first, firstInput := build(0)
+	second, secondInput := build(3)
+	if first.GetID() != second.GetID() || firstInput.GetID() != secondInput.GetID() {
+		t.Errorf("ids differ between builds: %s/%s and %s/%s", first.GetID(), firstInput.GetID(), second.GetID(), secondInput.GetID())
+	}
+	if firstInput.GetID() != KeyID("/settings", "name") {
+		t.Errorf("nested id = %s, want the id in the settings scope", firstInput.GetID())
+	}
+
+	// 会话副本与模板ID相同
+	if clone := first.Clone(); clone.GetID() != first.GetID() || FindWidget([]Widget{clone}, firstInput.GetID()) == nil {
+		t.Error("clone changed the keyed ids")
+	}
+}
+
+// TestKeyScopes 作用域路径由设置了Key的祖先组成，未设置Key的容器不改变作用域
+func TestKeyScopes(t *testing.T) {
+	input := NewTextInput("名称", "")
+	input.SetKey("name")
+	inner := NewContainer(false) // 没有Key
+	inner.AddChild(input)
+	outer := NewExpander("设置", true)
+	outer.SetKey("settings")
+	outer.AddChild(inner)
+	root := NewContainer(false)
+	root.SetKey("page")
+	root.AddChild(outer)
+
+	if err := AssignKeys([]Widget{root}); err != nil {
+		t.Fatal(err)
+	}
+	if want := KeyID("/page/settings", "name"); input.GetID() != want {
+		t.Errorf("nested id = %s, want %s", input.GetID(), want)
+	}
+	if want := KeyID("/page", "settings"); outer.GetID() != want {
+		t.Errorf("expander id = %s, want %s", outer.GetID(), want)
+	}
+
+	// Key中的"/"被转义，不会与嵌套的作用域混淆
+	slash := NewTextInput("名称", "")
+	slash.SetKey("settings/name")
+	if slash.GetID() == KeyID("/settings", "name") {
+		t.Error("key with a slash collides with a nested key")
+	}
+}
+
+// TestDuplicateKeys 同一作用域中的重复Key返回ErrDuplicateKey，不同作用域中可以重复
+func TestDuplicateKeys(t *testing.T) {
+	keyed := func(key string) *TextInputWidget {
+		input := NewTextInput(key, "")
+		input.SetKey(key)
+		return input
+	}
+
+	siblings := NewContainer(false)
+	siblings.AddChild(keyed("name"))
+	siblings.AddChild(keyed("name"))
+	if err := AssignKeys([]Widget{siblings}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("siblings: error %v, want ErrDuplicateKey", err)
+	}
+	if err := AssignKeys([]Widget{keyed("top"), keyed("top")}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("top level: error %v, want ErrDuplicateKey", err)
+	}
+
+	left := NewContainer(false)
+	left.SetKey("left")
+	left.AddChild(keyed("name"))
+	right := NewContainer(false)
+	right.SetKey("right")
+	right.AddChild(keyed("name"))
+	if err := AssignKeys([]Widget{left, right}); err != nil {
+		t.Errorf("different scopes: %v", err)
+	}
+
+	existing := []Widget{left, keyed("title")}
+	if err := CheckKeys(existing, keyed("title")); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("CheckKeys: error %v, want ErrDuplicateKey", err)
+	}
+	if err := CheckKeys(existing, keyed("name")); err != nil {
+		t.Errorf("CheckKeys across scopes: %v", err)
+	}
+	if err := CheckKeys(existing, existing[1]); err != nil {
+		t.Errorf("CheckKeys with the same widget: %v", err)
+	}
+}
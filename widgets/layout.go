@@ -1,9 +1,5 @@
 package widgets
 
-import (
-	"fmt"
-)
-
 // ContainerWidget 容器组件
 type ContainerWidget struct {
 	*BaseWidget
@@ -24,6 +20,24 @@ func NewContainer(border bool) *ContainerWidget {
 // AddChild 添加子组件
 func (w *ContainerWidget) AddChild(child Widget) {
 	w.children = append(w.children, child)
+	attachChild(w, child)
+}
+
+// Children 获取子组件
+func (w *ContainerWidget) Children() []Widget {
+	return w.children
+}
+
+// RemoveChild 移除子组件
+func (w *ContainerWidget) RemoveChild(componentID string) bool {
+	var removed bool
+	w.children, removed = removeChild(w.children, componentID)
+	return removed
+}
+
+// ReplaceChild 替换子组件
+func (w *ContainerWidget) ReplaceChild(componentID string, widget Widget) bool {
+	return replaceChild(w.children, componentID, widget)
 }
 
 // Render 渲染容器组件为HTML
@@ -33,12 +47,15 @@ func (w *ContainerWidget) Render() string {
 		borderClass = " st-container-with-border"
 	}
 
-	childrenHTML := ""
-	for _, child := range w.children {
-		childrenHTML += child.Render()
-	}
+	return HTMLf("<div class=\"st-container%s\" data-widget-id=\"%s\">%s</div>", borderClass, w.GetID(), renderChildren(w.children)).String()
+}
 
-	return fmt.Sprintf("<div class=\"st-container%s\" data-widget-id=\"%s\">%s</div>", borderClass, w.GetID(), childrenHTML)
+// Clone 复制容器组件
+func (w *ContainerWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.children = cloneChildren(w.children)
+	return &c
 }
 
 // Column 列组件
@@ -60,16 +77,37 @@ func NewColumn(ratio int) *Column {
 // AddChild 添加子组件
 func (c *Column) AddChild(child Widget) {
 	c.children = append(c.children, child)
+	attachChild(c, child)
+}
+
+// Children 获取子组件
+func (c *Column) Children() []Widget {
+	return c.children
+}
+
+// RemoveChild 移除子组件
+func (c *Column) RemoveChild(componentID string) bool {
+	var removed bool
+	c.children, removed = removeChild(c.children, componentID)
+	return removed
+}
+
+// ReplaceChild 替换子组件
+func (c *Column) ReplaceChild(componentID string, widget Widget) bool {
+	return replaceChild(c.children, componentID, widget)
 }
 
 // Render 渲染列组件为HTML
 func (c *Column) Render() string {
-	childrenHTML := ""
-	for _, child := range c.children {
-		childrenHTML += child.Render()
-	}
+	return HTMLf("<div class=\"st-column\" style=\"flex: %d\" data-widget-id=\"%s\">%s</div>", c.ratio, c.GetID(), renderChildren(c.children)).String()
+}
 
-	return fmt.Sprintf("<div class=\"st-column\" style=\"flex: %d\" data-widget-id=\"%s\">%s</div>", c.ratio, c.GetID(), childrenHTML)
+// Clone 复制列组件
+func (c *Column) Clone() Widget {
+	cloned := *c
+	cloned.BaseWidget = c.BaseWidget.CloneBase()
+	cloned.children = cloneChildren(c.children)
+	return &cloned
 }
 
 // ColumnsWidget 列布局组件
@@ -98,6 +136,15 @@ func NewColumns(ratios ...int) *ColumnsWidget {
 	}
 }
 
+// Children 获取列组件
+func (w *ColumnsWidget) Children() []Widget {
+	children := make([]Widget, len(w.columns))
+	for i, column := range w.columns {
+		children[i] = column
+	}
+	return children
+}
+
 // GetColumns 获取列组件数组
 func (w *ColumnsWidget) GetColumns() []*Column {
 	return w.columns
@@ -105,12 +152,18 @@ func (w *ColumnsWidget) GetColumns() []*Column {
 
 // Render 渲染列布局组件为HTML
 func (w *ColumnsWidget) Render() string {
-	columnsHTML := ""
-	for _, column := range w.columns {
-		columnsHTML += column.Render()
-	}
+	return HTMLf("<div class=\"st-columns\" data-widget-id=\"%s\">%s</div>", w.GetID(), renderChildren(w.Children())).String()
+}
 
-	return fmt.Sprintf("<div class=\"st-columns\" data-widget-id=\"%s\">%s</div>", w.GetID(), columnsHTML)
+// Clone 复制列布局组件
+func (w *ColumnsWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.columns = make([]*Column, len(w.columns))
+	for i, column := range w.columns {
+		c.columns[i] = column.Clone().(*Column)
+	}
+	return &c
 }
 
 // SidebarWidget 侧边栏组件
@@ -134,6 +187,24 @@ func NewSidebar(expanded bool) *SidebarWidget {
 // AddChild 添加子组件
 func (w *SidebarWidget) AddChild(child Widget) {
 	w.children = append(w.children, child)
+	attachChild(w, child)
+}
+
+// Children 获取子组件
+func (w *SidebarWidget) Children() []Widget {
+	return w.children
+}
+
+// RemoveChild 移除子组件
+func (w *SidebarWidget) RemoveChild(componentID string) bool {
+	var removed bool
+	w.children, removed = removeChild(w.children, componentID)
+	return removed
+}
+
+// ReplaceChild 替换子组件
+func (w *SidebarWidget) ReplaceChild(componentID string, widget Widget) bool {
+	return replaceChild(w.children, componentID, widget)
 }
 
 // Render 渲染侧边栏组件为HTML
@@ -143,12 +214,15 @@ func (w *SidebarWidget) Render() string {
 		expandedClass = " st-sidebar-expanded"
 	}
 
-	childrenHTML := ""
-	for _, child := range w.children {
-		childrenHTML += child.Render()
-	}
+	return HTMLf("<div class=\"st-sidebar%s\" data-widget-id=\"%s\">%s</div>", expandedClass, w.GetID(), renderChildren(w.children)).String()
+}
 
-	return fmt.Sprintf("<div class=\"st-sidebar%s\" data-widget-id=\"%s\">%s</div>", expandedClass, w.GetID(), childrenHTML)
+// Clone 复制侧边栏组件
+func (w *SidebarWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.children = cloneChildren(w.children)
+	return &c
 }
 
 // ExpanderWidget 可展开组件
@@ -174,6 +248,24 @@ func NewExpander(label string, expanded bool) *ExpanderWidget {
 // AddChild 添加子组件
 func (w *ExpanderWidget) AddChild(child Widget) {
 	w.children = append(w.children, child)
+	attachChild(w, child)
+}
+
+// Children 获取子组件
+func (w *ExpanderWidget) Children() []Widget {
+	return w.children
+}
+
+// RemoveChild 移除子组件
+func (w *ExpanderWidget) RemoveChild(componentID string) bool {
+	var removed bool
+	w.children, removed = removeChild(w.children, componentID)
+	return removed
+}
+
+// ReplaceChild 替换子组件
+func (w *ExpanderWidget) ReplaceChild(componentID string, widget Widget) bool {
+	return replaceChild(w.children, componentID, widget)
 }
 
 // Render 渲染可展开组件为HTML
@@ -183,11 +275,14 @@ func (w *ExpanderWidget) Render() string {
 		expandedClass = " st-expander-expanded"
 	}
 
-	childrenHTML := ""
-	for _, child := range w.children {
-		childrenHTML += child.Render()
-	}
+	return HTMLf("<div class=\"st-expander%s\" data-widget-id=\"%s\"><div class=\"st-expander-header\">%s</div><div class=\"st-expander-content\">%s</div></div>",
+		expandedClass, w.GetID(), w.label, renderChildren(w.children)).String()
+}
 
-	return fmt.Sprintf("<div class=\"st-expander%s\" data-widget-id=\"%s\"><div class=\"st-expander-header\">%s</div><div class=\"st-expander-content\">%s</div></div>",
-		expandedClass, w.GetID(), w.label, childrenHTML)
+// Clone 复制可展开组件
+func (w *ExpanderWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.children = cloneChildren(w.children)
+	return &c
 }
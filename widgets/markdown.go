@@ -0,0 +1,674 @@
+package widgets
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownWidget Markdown组件
+//
+// 支持CommonMark语法以及表格、删除线、任务列表、:emoji:短代码和:red[彩色文本]。
+// 输出的HTML只包含固定的标签和属性，Markdown中直接书写的HTML只接受markdownTags中不带属性的标签，
+// 其余一律作为文本转义，因此可以直接显示用户提交的内容。
+type MarkdownWidget struct {
+	*BaseWidget
+	text string
+}
+
+// NewMarkdown 创建新的Markdown组件
+func NewMarkdown(text string) *MarkdownWidget {
+	return &MarkdownWidget{
+		BaseWidget: NewBaseWidget("markdown"),
+		text:       text,
+	}
+}
+
+// SetText 设置Markdown文本
+func (w *MarkdownWidget) SetText(text string) {
+	w.text = text
+}
+
+// Render 渲染Markdown组件为HTML
+func (w *MarkdownWidget) Render() string {
+	return HTMLf("<div class=\"st-markdown\" data-widget-id=\"%s\">%s</div>", w.GetID(), RenderMarkdown(w.text)).String()
+}
+
+// Clone 复制Markdown组件
+func (w *MarkdownWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// CaptionWidget 说明文字组件，以较小的灰色字体显示Markdown文本
+type CaptionWidget struct {
+	*BaseWidget
+	text string
+}
+
+// NewCaption 创建新的说明文字组件
+func NewCaption(text string) *CaptionWidget {
+	return &CaptionWidget{
+		BaseWidget: NewBaseWidget("caption"),
+		text:       text,
+	}
+}
+
+// SetText 设置说明文字
+func (w *CaptionWidget) SetText(text string) {
+	w.text = text
+}
+
+// Render 渲染说明文字组件为HTML
+func (w *CaptionWidget) Render() string {
+	return HTMLf("<div class=\"st-caption st-markdown\" data-widget-id=\"%s\">%s</div>", w.GetID(), RenderMarkdown(w.text)).String()
+}
+
+// Clone 复制说明文字组件
+func (w *CaptionWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// RenderMarkdown 把Markdown文本渲染为经过过滤的HTML
+func RenderMarkdown(text string) SafeHTML {
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\x00", "�").Replace(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = expandIndent(line)
+	}
+
+	p := &mdParser{refs: make(map[string]mdRef)}
+	nodes := p.parseBlocks(lines)
+
+	var b strings.Builder
+	p.render(&b, nodes, false)
+	return SafeHTML(b.String())
+}
+
+// mdKind Markdown块的类型
+type mdKind int
+
+const (
+	mdParagraph mdKind = iota
+	mdHeading
+	mdCode
+	mdRule
+	mdQuote
+	mdList
+	mdItem
+	mdTable
+)
+
+// mdNode Markdown块
+type mdNode struct {
+	kind        mdKind
+	text        string     // 段落和标题的行内文本，代码块的内容
+	level       int        // 标题级别
+	lang        string     // 代码块的语言
+	ordered     bool       // 是否有序列表
+	start       int        // 有序列表的起始序号
+	tight       bool       // 紧凑列表，列表项中的段落不包裹<p>
+	task        int        // 任务列表项：0不是任务，1未完成，2已完成
+	align       []string   // 表格各列的对齐方式
+	rows        [][]string // 表格单元格，第一行为表头
+	blankBefore bool       // 与前一个块之间有空行，用于判断列表是否紧凑
+	children    []*mdNode
+}
+
+// mdRef 链接引用定义
+type mdRef struct {
+	url   string
+	title string
+}
+
+// mdParser 块级解析器，先解析全部块并收集链接引用定义，再渲染行内内容
+type mdParser struct {
+	refs map[string]mdRef
+}
+
+var (
+	mdHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdFenceRe   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	mdRuleRe    = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdQuoteRe   = regexp.MustCompile(`^ {0,3}> ?`)
+	mdSetextRe  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdDelimRe   = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdTaskRe    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	mdRefDefRe  = regexp.MustCompile(`^ {0,3}\[((?:[^\]\\]|\\.)+)\]:[ \t]*(<[^>]*>|\S+)(?:[ \t]+("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\((?:[^)\\]|\\.)*\)))?[ \t]*$`)
+	mdLangRe    = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+)
+
+// parseBlocks 解析块级结构
+func (p *mdParser) parseBlocks(lines []string) []*mdNode {
+	var nodes []*mdNode
+	blank := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlankLine(line) {
+			blank = true
+			i++
+			continue
+		}
+
+		var node *mdNode
+		switch {
+		case indentOf(line) >= 4:
+			node, i = p.parseIndentedCode(lines, i)
+		case isFenceStart(line):
+			node, i = p.parseFence(lines, i)
+		case mdHeadingRe.MatchString(line):
+			m := mdHeadingRe.FindStringSubmatch(line)
+			node, i = &mdNode{kind: mdHeading, level: len(m[1]), text: m[2]}, i+1
+		case mdRuleRe.MatchString(line):
+			node, i = &mdNode{kind: mdRule}, i+1
+		case mdQuoteRe.MatchString(line):
+			node, i = p.parseQuote(lines, i)
+		case isListStart(line):
+			node, i = p.parseList(lines, i)
+		case isTableStart(lines, i):
+			node, i = p.parseTable(lines, i)
+		default:
+			node, i = p.parseParagraph(lines, i)
+		}
+
+		if node != nil {
+			node.blankBefore = blank && len(nodes) > 0
+			nodes = append(nodes, node)
+		}
+		blank = false
+	}
+	return nodes
+}
+
+// parseIndentedCode 解析缩进代码块
+func (p *mdParser) parseIndentedCode(lines []string, i int) (*mdNode, int) {
+	var body []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if !isBlankLine(line) && indentOf(line) < 4 {
+			break
+		}
+		body = append(body, stripIndent(line, 4))
+	}
+	for len(body) > 0 && isBlankLine(body[len(body)-1]) {
+		body = body[:len(body)-1]
+	}
+	return &mdNode{kind: mdCode, text: strings.Join(body, "\n") + "\n"}, i
+}
+
+// parseFence 解析围栏代码块，没有结束围栏时持续到末尾
+func (p *mdParser) parseFence(lines []string, i int) (*mdNode, int) {
+	m := mdFenceRe.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+
+	node := &mdNode{kind: mdCode}
+	if fields := strings.Fields(unescapeMarkdown(m[3])); len(fields) > 0 && mdLangRe.MatchString(fields[0]) {
+		node.lang = fields[0]
+	}
+
+	var body []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if trimmed := strings.TrimRight(strings.TrimLeft(line, " "), " \t"); indentOf(line) < 4 &&
+			len(trimmed) >= len(fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		body = append(body, stripIndent(line, indent))
+	}
+	if len(body) > 0 {
+		node.text = strings.Join(body, "\n") + "\n"
+	}
+	return node, i
+}
+
+// parseQuote 解析引用块，包括省略了>的延续行
+func (p *mdParser) parseQuote(lines []string, i int) (*mdNode, int) {
+	var inner []string
+	for i < len(lines) {
+		line := lines[i]
+		if loc := mdQuoteRe.FindStringIndex(line); loc != nil {
+			inner = append(inner, line[loc[1]:])
+		} else if !isBlankLine(line) && !isBlankLine(inner[len(inner)-1]) && !interruptsParagraph(line) {
+			inner = append(inner, strings.TrimLeft(line, " "))
+		} else {
+			break
+		}
+		i++
+	}
+	return &mdNode{kind: mdQuote, children: p.parseBlocks(inner)}, i
+}
+
+// listMarker 列表项标记
+type listMarker struct {
+	ordered bool
+	char    byte   // 无序列表的符号，或有序列表序号后的.或)
+	start   int    // 有序列表项的序号
+	indent  int    // 列表项内容的缩进
+	content string // 标记所在行的内容
+	empty   bool   // 标记所在行没有内容
+}
+
+// parseListMarker 解析列表项标记
+func parseListMarker(line string) (listMarker, bool) {
+	var m listMarker
+	indent := indentOf(line)
+	if indent > 3 {
+		return m, false
+	}
+
+	rest := line[indent:]
+	n := 0
+	if rest != "" && strings.IndexByte("-+*", rest[0]) >= 0 {
+		m.char = rest[0]
+		n = 1
+	} else {
+		for n < len(rest) && n < 9 && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+		if n == 0 || n >= len(rest) || (rest[n] != '.' && rest[n] != ')') {
+			return m, false
+		}
+		m.ordered = true
+		m.char = rest[n]
+		m.start, _ = strconv.Atoi(rest[:n])
+		n++
+	}
+
+	after := rest[n:]
+	if isBlankLine(after) {
+		m.empty = true
+		m.indent = indent + n + 1
+		return m, true
+	}
+	if after[0] != ' ' && after[0] != '\t' {
+		return m, false
+	}
+	spaces := 0
+	for spaces < len(after) && (after[spaces] == ' ' || after[spaces] == '\t') {
+		spaces++
+	}
+	if spaces > 4 {
+		// 标记后超过4个空格时内容是缩进代码块
+		spaces = 1
+	}
+	m.indent = indent + n + spaces
+	m.content = after[spaces:]
+	return m, true
+}
+
+// isListStart 判断是否为列表项的开始
+func isListStart(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok
+}
+
+// parseList 解析列表，标记类型不同的列表项开始新的列表
+func (p *mdParser) parseList(lines []string, i int) (*mdNode, int) {
+	first, _ := parseListMarker(lines[i])
+	list := &mdNode{kind: mdList, ordered: first.ordered, start: first.start, tight: true}
+
+	for i < len(lines) {
+		m, ok := parseListMarker(lines[i])
+		if !ok || m.ordered != first.ordered || m.char != first.char || mdRuleRe.MatchString(lines[i]) {
+			break
+		}
+
+		item := []string{m.content}
+	collect:
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlankLine(line):
+				// 内容为空的列表项最多以一个空行开始
+				if m.empty && len(item) == 1 {
+					break collect
+				}
+				item = append(item, "")
+			case indentOf(line) >= m.indent:
+				item = append(item, line[m.indent:])
+			case !isBlankLine(item[len(item)-1]) && !interruptsParagraph(line) && !isListStart(line):
+				item = append(item, strings.TrimLeft(line, " "))
+			default:
+				break collect
+			}
+		}
+
+		trailing := false
+		for len(item) > 1 && isBlankLine(item[len(item)-1]) {
+			item = item[:len(item)-1]
+			trailing = true
+		}
+		if next, ok := parseListMarker(lineAt(lines, i)); trailing && ok && next.ordered == first.ordered && next.char == first.char {
+			list.tight = false
+		}
+
+		node := p.parseItem(item)
+		for _, child := range node.children {
+			if child.blankBefore {
+				list.tight = false
+			}
+		}
+		list.children = append(list.children, node)
+	}
+	return list, i
+}
+
+// parseItem 解析列表项的内容
+func (p *mdParser) parseItem(lines []string) *mdNode {
+	node := &mdNode{kind: mdItem}
+	if m := mdTaskRe.FindStringSubmatch(lines[0]); m != nil {
+		node.task = 1
+		if m[1] != " " {
+			node.task = 2
+		}
+		lines[0] = lines[0][len(m[0]):]
+	}
+	node.children = p.parseBlocks(lines)
+	return node
+}
+
+// isTableStart 判断是否为表格的开始：表头行之后是列数相同的分隔行
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || indentOf(lines[i]) >= 4 {
+		return false
+	}
+	header, delim := lines[i], lines[i+1]
+	if !strings.Contains(header, "|") || !strings.Contains(delim, "|") || !mdDelimRe.MatchString(delim) {
+		return false
+	}
+	return len(splitTableRow(header)) == len(splitTableRow(delim))
+}
+
+// parseTable 解析表格，表格在空行或其他块开始时结束
+func (p *mdParser) parseTable(lines []string, i int) (*mdNode, int) {
+	header := splitTableRow(lines[i])
+	node := &mdNode{kind: mdTable, rows: [][]string{header}}
+	for _, cell := range splitTableRow(lines[i+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			node.align = append(node.align, "center")
+		case left:
+			node.align = append(node.align, "left")
+		case right:
+			node.align = append(node.align, "right")
+		default:
+			node.align = append(node.align, "")
+		}
+	}
+
+	for i += 2; i < len(lines); i++ {
+		line := lines[i]
+		if isBlankLine(line) || interruptsParagraph(line) {
+			break
+		}
+		row := splitTableRow(line)
+		if len(row) > len(header) {
+			row = row[:len(header)]
+		}
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		node.rows = append(node.rows, row)
+	}
+	return node, i
+}
+
+// splitTableRow 按未转义的|拆分表格行
+func splitTableRow(line string) []string {
+	s := strings.TrimSpace(line)
+	s = strings.TrimPrefix(s, "|")
+	if strings.HasSuffix(s, "|") && !strings.HasSuffix(s, "\\|") {
+		s = s[:len(s)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for j := 0; j < len(s); j++ {
+		switch {
+		case s[j] == '\\' && j+1 < len(s) && s[j+1] == '|':
+			cell.WriteByte('|')
+			j++
+		case s[j] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(s[j])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseParagraph 解析段落，下一行是=或-时为Setext标题
+func (p *mdParser) parseParagraph(lines []string, i int) (*mdNode, int) {
+	para := []string{strings.TrimLeft(lines[i], " ")}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if isBlankLine(line) {
+			break
+		}
+		if m := mdSetextRe.FindStringSubmatch(line); m != nil {
+			if para = p.extractRefs(para); len(para) > 0 {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				return &mdNode{kind: mdHeading, level: level, text: strings.Join(para, "\n")}, i + 1
+			}
+		}
+		if interruptsParagraph(line) || isTableStart(lines, i) {
+			break
+		}
+		para = append(para, strings.TrimLeft(line, " "))
+	}
+
+	if para = p.extractRefs(para); len(para) == 0 {
+		return nil, i
+	}
+	return &mdNode{kind: mdParagraph, text: strings.Join(para, "\n")}, i
+}
+
+// extractRefs 移除段落开头的链接引用定义，同名定义以第一个为准
+func (p *mdParser) extractRefs(para []string) []string {
+	for len(para) > 0 {
+		m := mdRefDefRe.FindStringSubmatch(para[0])
+		if m == nil {
+			break
+		}
+		label := normalizeRef(m[1])
+		if _, exists := p.refs[label]; !exists && label != "" {
+			url := strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">")
+			title := ""
+			if len(m[3]) >= 2 {
+				title = m[3][1 : len(m[3])-1]
+			}
+			p.refs[label] = mdRef{url: unescapeMarkdown(url), title: unescapeMarkdown(title)}
+		}
+		para = para[1:]
+	}
+	return para
+}
+
+// render 渲染块，tight为true时段落不包裹<p>
+func (p *mdParser) render(b *strings.Builder, nodes []*mdNode, tight bool) {
+	for i, node := range nodes {
+		switch node.kind {
+		case mdParagraph:
+			if tight {
+				b.WriteString(p.inline(node.text))
+				if i < len(nodes)-1 {
+					b.WriteString("\n")
+				}
+			} else {
+				fmt.Fprintf(b, "<p>%s</p>\n", p.inline(node.text))
+			}
+		case mdHeading:
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", node.level, p.inline(node.text), node.level)
+		case mdCode:
+			langAttr := ""
+			if node.lang != "" {
+				langAttr = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(node.lang))
+			}
+			fmt.Fprintf(b, "<pre><code%s>%s</code></pre>\n", langAttr, html.EscapeString(node.text))
+		case mdRule:
+			b.WriteString("<hr>\n")
+		case mdQuote:
+			b.WriteString("<blockquote>\n")
+			p.render(b, node.children, false)
+			b.WriteString("</blockquote>\n")
+		case mdList:
+			p.renderList(b, node)
+		case mdTable:
+			p.renderTable(b, node)
+		}
+	}
+}
+
+// renderList 渲染列表
+func (p *mdParser) renderList(b *strings.Builder, list *mdNode) {
+	tag := "ul"
+	if list.ordered {
+		tag = "ol"
+	}
+	if list.ordered && list.start != 1 {
+		fmt.Fprintf(b, "<ol start=\"%d\">\n", list.start)
+	} else {
+		fmt.Fprintf(b, "<%s>\n", tag)
+	}
+
+	for _, item := range list.children {
+		switch item.task {
+		case 1:
+			b.WriteString("<li class=\"st-md-task\"><input type=\"checkbox\" disabled> ")
+		case 2:
+			b.WriteString("<li class=\"st-md-task\"><input type=\"checkbox\" disabled checked> ")
+		default:
+			b.WriteString("<li>")
+		}
+		p.render(b, item.children, list.tight)
+		b.WriteString("</li>\n")
+	}
+	fmt.Fprintf(b, "</%s>\n", tag)
+}
+
+// renderTable 渲染表格
+func (p *mdParser) renderTable(b *strings.Builder, table *mdNode) {
+	b.WriteString("<table>\n")
+	for r, row := range table.rows {
+		cellTag := "td"
+		if r == 0 {
+			cellTag = "th"
+			b.WriteString("<thead>\n")
+		} else if r == 1 {
+			b.WriteString("<tbody>\n")
+		}
+
+		b.WriteString("<tr>")
+		for c, cell := range row {
+			alignAttr := ""
+			if table.align[c] != "" {
+				alignAttr = fmt.Sprintf(" style=\"text-align: %s\"", table.align[c])
+			}
+			fmt.Fprintf(b, "<%s%s>%s</%s>", cellTag, alignAttr, p.inline(cell), cellTag)
+		}
+		b.WriteString("</tr>\n")
+
+		if r == 0 {
+			b.WriteString("</thead>\n")
+		}
+	}
+	if len(table.rows) > 1 {
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// inline 渲染行内内容
+func (p *mdParser) inline(text string) string {
+	return renderInline(text, p.refs, false)
+}
+
+// interruptsParagraph 判断一行是否开始新的块从而结束段落
+//
+// 与CommonMark一致，空列表项和不从1开始的有序列表不能打断段落。
+func interruptsParagraph(line string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	if mdHeadingRe.MatchString(line) || mdRuleRe.MatchString(line) || mdQuoteRe.MatchString(line) || isFenceStart(line) {
+		return true
+	}
+	m, ok := parseListMarker(line)
+	return ok && !m.empty && (!m.ordered || m.start == 1)
+}
+
+// isFenceStart 判断是否为围栏代码块的开始，反引号围栏的信息字符串中不能有反引号
+func isFenceStart(line string) bool {
+	m := mdFenceRe.FindStringSubmatch(line)
+	return m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`"))
+}
+
+// isBlankLine 判断是否为空行
+func isBlankLine(line string) bool {
+	return strings.Trim(line, " \t") == ""
+}
+
+// indentOf 获取行首空格数
+func indentOf(line string) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+// stripIndent 移除最多n个行首空格
+func stripIndent(line string, n int) string {
+	if indent := indentOf(line); indent < n {
+		n = indent
+	}
+	return line[n:]
+}
+
+// expandIndent 把行首的制表符展开为空格，制表位宽度为4
+func expandIndent(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			b.WriteByte(' ')
+			col++
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		default:
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+// lineAt 获取第i行，超出范围时返回空字符串
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// normalizeRef 规范化链接引用的标签：忽略大小写，合并空白
+func normalizeRef(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,89 @@
+package widgets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownCommonMark(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"emphasis", "*em* _em_ **strong** __strong__", "<p><em>em</em> <em>em</em> <strong>strong</strong> <strong>strong</strong></p>\n"},
+		{"nested emphasis", "***both***", "<p><em><strong>both</strong></em></p>\n"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"strikethrough", "~~old~~", "<p><del>old</del></p>\n"},
+		{"code span", "`a <b>`", "<p><code>a &lt;b&gt;</code></p>\n"},
+		{"code span with backtick", "``a ` b``", "<p><code>a ` b</code></p>\n"},
+		{"code span is literal", "`*not em*`", "<p><code>*not em*</code></p>\n"},
+		{"bullet list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"ordered list start", "3. a\n4. b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"list type change", "- a\n\n1. b", "<ul>\n<li>a</li>\n</ul>\n<ol>\n<li>b</li>\n</ol>\n"},
+		{"nested list", "- a\n  - b\n- c", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n"},
+		{"task list", "- [ ] todo\n- [x] done", "<ul>\n<li class=\"st-md-task\"><input type=\"checkbox\" disabled> todo</li>\n<li class=\"st-md-task\"><input type=\"checkbox\" disabled checked> done</li>\n</ul>\n"},
+		{"quote with nested blocks", "> a\n> - b\n>\n> > c", "<blockquote>\n<p>a</p>\n<ul>\n<li>b</li>\n</ul>\n<blockquote>\n<p>c</p>\n</blockquote>\n</blockquote>\n"},
+		{"fenced code", "```go\nx := <y>\n```", "<pre><code class=\"language-go\">x := &lt;y&gt;\n</code></pre>\n"},
+		{"indented code", "    code", "<pre><code>code\n</code></pre>\n"},
+		{"atx headings", "# H1\n## H2 ##", "<h1>H1</h1>\n<h2>H2</h2>\n"},
+		{"setext heading", "Title\n===", "<h1>Title</h1>\n"},
+		{"thematic break", "***", "<hr>\n"},
+		{"hard line break", "a  \nb", "<p>a<br>\nb</p>\n"},
+		{"link with query", "[ok](https://example.com/?a=1&b=2)", "<p><a href=\"https://example.com/?a=1&amp;b=2\" target=\"_blank\" rel=\"noopener noreferrer\">ok</a></p>\n"},
+		{"allowed raw tag", "<b>bold</b>", "<p><b>bold</b></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RenderMarkdown(tt.in)); got != tt.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownXSS(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"javascript link", "[x](javascript:alert(1))", "<p>x</p>\n"},
+		{"mixed case scheme", "[x](JaVaScRiPt:alert(1))", "<p>x</p>\n"},
+		{"decimal entity scheme", "[x](&#106;avascript:alert(1))", "<p>x</p>\n"},
+		{"hex entity scheme", "[x](java&#x73;cript:alert(1))", "<p>x</p>\n"},
+		{"vbscript link", "[x](vbscript:msgbox(1))", "<p>x</p>\n"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>\n"},
+		{"data image", "![x](data:image/svg+xml;base64,PHN2Zz4=)", "<p>x</p>\n"},
+		{"javascript reference", "[x]\n\n[x]: javascript:alert(1)", "<p>x</p>\n"},
+		{"javascript autolink", "<javascript:alert(1)>", "<p>&lt;javascript:alert(1)&gt;</p>\n"},
+		{"script tag", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"img onerror", "<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"allowed tag with attribute", "<b onclick=alert(1)>x</b>", "<p>&lt;b onclick=alert(1)&gt;x&lt;/b&gt;</p>\n"},
+		{"title breakout", `[x](https://a.com "t\" onmouseover=\"alert(1)")`, "<p><a href=\"https://a.com\" title=\"t&#34; onmouseover=&#34;alert(1)\" target=\"_blank\" rel=\"noopener noreferrer\">x</a></p>\n"},
+		{"title closes tag", `[x](https://a.com "\"><script>alert(1)</script>")`, "<p><a href=\"https://a.com\" title=\"&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;\" target=\"_blank\" rel=\"noopener noreferrer\">x</a></p>\n"},
+		{"alt breakout", `![a" onerror="alert(1)](https://a.com/i.png)`, "<p><img src=\"https://a.com/i.png\" alt=\"a&#34; onerror=&#34;alert(1)\" loading=\"lazy\"></p>\n"},
+		{"alt with tags", "![<script>](https://a.com/i.png)", "<p><img src=\"https://a.com/i.png\" alt=\"&lt;script&gt;\" loading=\"lazy\"></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(RenderMarkdown(tt.in))
+			if got != tt.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+			lower := strings.ToLower(got)
+			for _, forbidden := range []string{"<script", "href=\"javascript", "href=\"data:", "src=\"data:", "<img src=x"} {
+				if strings.Contains(lower, forbidden) {
+					t.Errorf("RenderMarkdown(%q) contains %q: %s", tt.in, forbidden, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownWidgetEscapesText(t *testing.T) {
+	html := NewMarkdown("<script>alert(1)</script>").Render()
+	if strings.Contains(html, "<script>") {
+		t.Errorf("markdown widget rendered raw script: %s", html)
+	}
+	html = NewCaption("[x](javascript:alert(1))").Render()
+	if strings.Contains(strings.ToLower(html), "javascript") {
+		t.Errorf("caption rendered javascript link: %s", html)
+	}
+}
@@ -0,0 +1,689 @@
+package widgets
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// markdownTags 允许在Markdown中直接书写的HTML标签，只接受不带属性的形式，其余HTML作为文本转义
+var markdownTags = map[string]bool{
+	"b": true, "i": true, "em": true, "strong": true, "u": true, "s": true, "del": true, "ins": true,
+	"mark": true, "sub": true, "sup": true, "small": true, "kbd": true, "code": true, "br": true,
+}
+
+// markdownSchemes 链接允许的URL协议，没有协议的相对地址总是允许，图片只允许http和https
+var markdownSchemes = map[string]bool{
+	"http": true, "https": true, "mailto": true, "tel": true,
+}
+
+// markdownColors 彩色文本的颜色及对应的样式类，:red[文本]设置文字颜色，:red-background[文本]设置背景色
+var markdownColors = map[string]string{
+	"blue": "blue", "green": "green", "orange": "orange", "red": "red", "violet": "violet",
+	"gray": "gray", "grey": "gray", "rainbow": "rainbow", "primary": "primary",
+	"blue-background": "blue-background", "green-background": "green-background",
+	"orange-background": "orange-background", "red-background": "red-background",
+	"violet-background": "violet-background", "gray-background": "gray-background",
+	"grey-background": "gray-background", "primary-background": "primary-background",
+}
+
+// markdownEmoji 支持的emoji短代码
+var markdownEmoji = map[string]string{
+	"smile": "😄", "smiley": "😃", "grinning": "😀", "laughing": "😆", "joy": "😂", "rofl": "🤣",
+	"wink": "😉", "blush": "😊", "heart_eyes": "😍", "thinking": "🤔", "sunglasses": "😎",
+	"slightly_smiling_face": "🙂", "upside_down_face": "🙃", "neutral_face": "😐", "confused": "😕",
+	"sweat_smile": "😅", "cry": "😢", "sob": "😭", "angry": "😠", "rage": "😡", "scream": "😱",
+	"hugs": "🤗", "star_struck": "🤩", "partying_face": "🥳", "sleeping": "😴", "nerd_face": "🤓",
+	"+1": "👍", "thumbsup": "👍", "-1": "👎", "thumbsdown": "👎", "clap": "👏", "wave": "👋",
+	"pray": "🙏", "muscle": "💪", "ok_hand": "👌", "raised_hands": "🙌", "eyes": "👀", "brain": "🧠",
+	"point_right": "👉", "point_left": "👈", "point_up": "☝️", "point_down": "👇",
+	"heart": "❤️", "broken_heart": "💔", "orange_heart": "🧡", "yellow_heart": "💛",
+	"green_heart": "💚", "blue_heart": "💙", "purple_heart": "💜",
+	"sparkles": "✨", "star": "⭐", "star2": "🌟", "fire": "🔥", "boom": "💥", "zap": "⚡",
+	"100": "💯", "tada": "🎉", "confetti_ball": "🎊", "balloon": "🎈", "gift": "🎁", "rocket": "🚀",
+	"trophy": "🏆", "medal_sports": "🏅", "bulb": "💡", "memo": "📝", "pencil2": "✏️", "book": "📖",
+	"books": "📚", "bookmark": "🔖", "calendar": "📆", "date": "📅", "clipboard": "📋", "pushpin": "📌",
+	"paperclip": "📎", "link": "🔗", "lock": "🔒", "unlock": "🔓", "key": "🔑", "bell": "🔔", "mag": "🔍",
+	"chart_with_upwards_trend": "📈", "chart_with_downwards_trend": "📉", "bar_chart": "📊",
+	"moneybag": "💰", "dollar": "💵", "euro": "💶", "yen": "💴", "credit_card": "💳",
+	"hourglass": "⌛", "hourglass_flowing_sand": "⏳", "alarm_clock": "⏰", "stopwatch": "⏱️",
+	"computer": "💻", "keyboard": "⌨️", "iphone": "📱", "email": "📧", "envelope": "✉️",
+	"inbox_tray": "📥", "outbox_tray": "📤", "package": "📦", "file_folder": "📁",
+	"open_file_folder": "📂", "wrench": "🔧", "hammer": "🔨", "gear": "⚙️", "hammer_and_wrench": "🛠️",
+	"bug": "🐛", "test_tube": "🧪", "microscope": "🔬", "white_check_mark": "✅",
+	"heavy_check_mark": "✔️", "x": "❌", "warning": "⚠️", "no_entry": "⛔", "stop_sign": "🛑",
+	"question": "❓", "exclamation": "❗", "information_source": "ℹ️", "construction": "🚧",
+	"recycle": "♻️", "arrow_up": "⬆️", "arrow_down": "⬇️", "arrow_left": "⬅️", "arrow_right": "➡️",
+	"arrows_counterclockwise": "🔄", "sunny": "☀️", "cloud": "☁️", "umbrella": "☔", "snowflake": "❄️",
+	"rainbow": "🌈", "earth_asia": "🌏", "earth_americas": "🌎", "globe_with_meridians": "🌐",
+	"tulip": "🌷", "rose": "🌹", "sunflower": "🌻", "cherry_blossom": "🌸", "seedling": "🌱",
+	"evergreen_tree": "🌲", "deciduous_tree": "🌳", "four_leaf_clover": "🍀",
+	"coffee": "☕", "tea": "🍵", "beer": "🍺", "pizza": "🍕", "cake": "🍰", "apple": "🍎",
+	"dog": "🐶", "cat": "🐱", "panda_face": "🐼", "penguin": "🐧", "snake": "🐍", "whale": "🐳",
+	"crab": "🦀", "rabbit": "🐰", "gopher": "🐹",
+}
+
+var (
+	mdEntityRe   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	mdRawTagRe   = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9]*)[ \t]*(/?)>`)
+	mdAutoURLRe  = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	mdAutoMailRe = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+	mdColorRe    = regexp.MustCompile(`^:([a-z]+(?:-background)?)\[`)
+	mdEmojiRe    = regexp.MustCompile(`^:([a-z0-9_+-]+):`)
+)
+
+// mdInline 行内解析的中间结果：已渲染的HTML，或等待配对的强调分隔符
+type mdInline struct {
+	html     string
+	delim    byte // 分隔符字符*、_或~，0表示HTML
+	count    int  // 剩余未配对的分隔符数量
+	length   int  // 分隔符序列的原始长度
+	canOpen  bool
+	canClose bool
+}
+
+// inlineParser 行内解析器
+type inlineParser struct {
+	src     string
+	refs    map[string]mdRef
+	noLinks bool // 链接文本中不能再有链接
+	items   []mdInline
+	buf     []byte   // 尚未加入items的已转义文本
+	tags    []string // 尚未闭合的HTML标签
+}
+
+// renderInline 渲染行内内容
+func renderInline(src string, refs map[string]mdRef, noLinks bool) string {
+	p := &inlineParser{
+		src:     strings.Trim(src, " \t\n"),
+		refs:    refs,
+		noLinks: noLinks,
+	}
+	p.parse()
+	p.flush()
+	p.emphasis()
+
+	out := renderItems(p.items)
+	// 闭合未闭合的标签，保证输出不影响组件外的内容
+	for i := len(p.tags) - 1; i >= 0; i-- {
+		out += "</" + p.tags[i] + ">"
+	}
+	return out
+}
+
+// parse 逐个字符解析，强调分隔符留待emphasis配对
+func (p *inlineParser) parse() {
+	src := p.src
+	for pos := 0; pos < len(src); {
+		next, ok := 0, false
+		switch c := src[pos]; c {
+		case '\\':
+			next, ok = p.escape(pos), true
+		case '`':
+			next, ok = p.codeSpan(pos), true
+		case '*', '_', '~':
+			next, ok = p.delimiter(pos), true
+		case '!':
+			if pos+1 < len(src) && src[pos+1] == '[' {
+				next, ok = p.link(pos+1, true)
+			}
+		case '[':
+			next, ok = p.link(pos, false)
+		case '<':
+			if next, ok = p.autolink(pos); !ok {
+				next, ok = p.rawTag(pos)
+			}
+		case '&':
+			next, ok = p.entity(pos), true
+		case ':':
+			if next, ok = p.colored(pos); !ok {
+				next, ok = p.emoji(pos)
+			}
+		case '\n':
+			p.lineBreak()
+			next, ok = pos+1, true
+		case 'h', 'w':
+			if pos == 0 || strings.IndexByte(" \n(", src[pos-1]) >= 0 {
+				next, ok = p.bareLink(pos)
+			}
+		}
+
+		if !ok {
+			// 普通文本一直读到下一个可能有特殊含义的字符
+			next = pos + 1
+			for next < len(src) && strings.IndexByte("\\`*_~![<&:\n", src[next]) < 0 &&
+				!((src[next] == 'h' || src[next] == 'w') && strings.IndexByte(" (", src[next-1]) >= 0) {
+				next++
+			}
+			p.text(src[pos:next])
+		}
+		pos = next
+	}
+}
+
+// text 添加文本
+func (p *inlineParser) text(s string) {
+	p.buf = append(p.buf, html.EscapeString(s)...)
+}
+
+// html 添加已渲染的HTML
+func (p *inlineParser) html(s string) {
+	p.flush()
+	p.items = append(p.items, mdInline{html: s})
+}
+
+// flush 把缓冲的文本加入items
+func (p *inlineParser) flush() {
+	if len(p.buf) > 0 {
+		p.items = append(p.items, mdInline{html: string(p.buf)})
+		p.buf = nil
+	}
+}
+
+// lineBreak 处理换行，行尾有两个以上空格时为硬换行
+func (p *inlineParser) lineBreak() {
+	trimmed := bytes.TrimRight(p.buf, " ")
+	if len(p.buf)-len(trimmed) >= 2 {
+		p.buf = append(trimmed, "<br>\n"...)
+	} else {
+		p.buf = append(trimmed, '\n')
+	}
+}
+
+// escape 处理反斜杠转义，行尾的反斜杠为硬换行
+func (p *inlineParser) escape(pos int) int {
+	if pos+1 < len(p.src) {
+		next := p.src[pos+1]
+		if next == '\n' {
+			p.buf = append(bytes.TrimRight(p.buf, " "), "<br>\n"...)
+			return pos + 2
+		}
+		if isASCIIPunct(next) {
+			p.text(string(next))
+			return pos + 2
+		}
+	}
+	p.text("\\")
+	return pos + 1
+}
+
+// codeSpan 处理行内代码，没有等长的结束反引号时反引号作为文本
+func (p *inlineParser) codeSpan(pos int) int {
+	n, end := codeSpanEnd(p.src, pos)
+	if end < 0 {
+		p.text(p.src[pos : pos+n])
+		return pos + n
+	}
+
+	code := strings.ReplaceAll(p.src[pos+n:end-n], "\n", " ")
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+		code = code[1 : len(code)-1]
+	}
+	p.html("<code>" + html.EscapeString(code) + "</code>")
+	return end
+}
+
+// codeSpanEnd 获取pos处反引号序列的长度和行内代码的结束位置，没有结束反引号时结束位置为-1
+func codeSpanEnd(src string, pos int) (int, int) {
+	n := 0
+	for pos+n < len(src) && src[pos+n] == '`' {
+		n++
+	}
+	for j := pos + n; j < len(src); {
+		if src[j] != '`' {
+			j++
+			continue
+		}
+		k := j
+		for k < len(src) && src[k] == '`' {
+			k++
+		}
+		if k-j == n {
+			return n, k
+		}
+		j = k
+	}
+	return n, -1
+}
+
+// delimiter 记录强调分隔符，按CommonMark的左右侧规则判断能否开始或结束强调
+func (p *inlineParser) delimiter(pos int) int {
+	src := p.src
+	c := src[pos]
+	end := pos
+	for end < len(src) && src[end] == c {
+		end++
+	}
+	n := end - pos
+	if c == '~' && n > 2 {
+		p.text(src[pos:end])
+		return end
+	}
+
+	before, after := ' ', ' '
+	if pos > 0 {
+		before, _ = utf8.DecodeLastRuneInString(src[:pos])
+	}
+	if end < len(src) {
+		after, _ = utf8.DecodeRuneInString(src[end:])
+	}
+	left := !unicode.IsSpace(after) && (!isPunctRune(after) || unicode.IsSpace(before) || isPunctRune(before))
+	right := !unicode.IsSpace(before) && (!isPunctRune(before) || unicode.IsSpace(after) || isPunctRune(after))
+
+	item := mdInline{delim: c, count: n, length: n, canOpen: left, canClose: right}
+	if c == '_' {
+		// 单词内部的下划线不表示强调
+		item.canOpen = left && (!right || isPunctRune(before))
+		item.canClose = right && (!left || isPunctRune(after))
+	}
+	p.flush()
+	p.items = append(p.items, item)
+	return end
+}
+
+// emphasis 从左到右为每个结束分隔符寻找最近的开始分隔符，把两者之间的内容包裹为强调
+func (p *inlineParser) emphasis() {
+	items := p.items
+	for c := 0; c < len(items); c++ {
+		for items[c].delim != 0 && items[c].canClose && items[c].count > 0 {
+			closer := items[c]
+			o := -1
+			for j := c - 1; j >= 0; j-- {
+				opener := items[j]
+				if opener.delim != closer.delim || !opener.canOpen || opener.count == 0 {
+					continue
+				}
+				if closer.delim == '~' {
+					if opener.count != closer.count {
+						continue
+					}
+				} else if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 &&
+					!(opener.length%3 == 0 && closer.length%3 == 0) {
+					continue
+				}
+				o = j
+				break
+			}
+			if o < 0 {
+				break
+			}
+
+			use, tag := 1, "em"
+			switch {
+			case closer.delim == '~':
+				use, tag = closer.count, "del"
+			case items[o].count >= 2 && closer.count >= 2:
+				use, tag = 2, "strong"
+			}
+			inner := renderItems(items[o+1 : c])
+			items[o].count -= use
+			items[c].count -= use
+
+			merged := make([]mdInline, 0, len(items)-(c-o-1)+1)
+			merged = append(merged, items[:o+1]...)
+			merged = append(merged, mdInline{html: "<" + tag + ">" + inner + "</" + tag + ">"})
+			merged = append(merged, items[c:]...)
+			items = merged
+			c = o + 2
+		}
+	}
+	p.items = items
+}
+
+// renderItems 拼接行内解析结果，未配对的分隔符作为文本
+func renderItems(items []mdInline) string {
+	var b strings.Builder
+	for _, item := range items {
+		if item.delim != 0 {
+			b.WriteString(strings.Repeat(string(item.delim), item.count))
+		} else {
+			b.WriteString(item.html)
+		}
+	}
+	return b.String()
+}
+
+// link 处理链接和图片：[文本](地址 "标题")、[文本][引用]、[引用]
+func (p *inlineParser) link(pos int, image bool) (int, bool) {
+	src := p.src
+	closeAt := matchBracket(src, pos)
+	if closeAt < 0 {
+		return 0, false
+	}
+	label := src[pos+1 : closeAt]
+
+	dest, title, next := "", "", -1
+	if closeAt+1 < len(src) && src[closeAt+1] == '(' {
+		dest, title, next = parseLinkTail(src, closeAt+2)
+	}
+	if next < 0 {
+		ref, end := label, closeAt+1
+		if closeAt+1 < len(src) && src[closeAt+1] == '[' {
+			if k := strings.IndexByte(src[closeAt+2:], ']'); k >= 0 {
+				if k > 0 {
+					ref = src[closeAt+2 : closeAt+2+k]
+				}
+				end = closeAt + 3 + k
+			}
+		}
+		def, exists := p.refs[normalizeRef(ref)]
+		if !exists {
+			return 0, false
+		}
+		dest, title, next = def.url, def.title, end
+	}
+
+	titleAttr := ""
+	if title != "" {
+		titleAttr = fmt.Sprintf(" title=\"%s\"", html.EscapeString(title))
+	}
+
+	if image {
+		alt := html.EscapeString(unescapeMarkdown(label))
+		if imageURL, _, ok := safeURL(dest, true); ok {
+			p.html(fmt.Sprintf("<img src=\"%s\" alt=\"%s\"%s loading=\"lazy\">", html.EscapeString(imageURL), alt, titleAttr))
+		} else {
+			p.html(alt)
+		}
+		return next, true
+	}
+
+	if p.noLinks {
+		return 0, false
+	}
+	text := renderInline(label, p.refs, true)
+	href, external, ok := safeURL(dest, false)
+	if !ok {
+		p.html(text)
+		return next, true
+	}
+	p.html(fmt.Sprintf("<a href=\"%s\"%s%s>%s</a>", html.EscapeString(href), titleAttr, externalAttrs(external), text))
+	return next, true
+}
+
+// matchBracket 获取与pos处[配对的]的位置，跳过转义字符和行内代码，没有时返回-1
+func matchBracket(src string, pos int) int {
+	depth := 0
+	for j := pos; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '`':
+			n, end := codeSpanEnd(src, j)
+			if end > 0 {
+				j = end - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseLinkTail 解析链接括号中的地址和标题，pos为(之后的位置，格式不正确时返回-1
+func parseLinkTail(src string, pos int) (string, string, int) {
+	j := skipSpace(src, pos)
+	dest := ""
+	if j < len(src) && src[j] == '<' {
+		k := strings.IndexAny(src[j+1:], ">\n")
+		if k < 0 || src[j+1+k] != '>' {
+			return "", "", -1
+		}
+		dest = src[j+1 : j+1+k]
+		j += k + 2
+	} else {
+		start, depth := j, 0
+		for j < len(src) {
+			c := src[j]
+			if c == '\\' && j+1 < len(src) && isASCIIPunct(src[j+1]) {
+				j += 2
+				continue
+			}
+			if c <= ' ' {
+				break
+			}
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+			j++
+		}
+		dest = src[start:j]
+	}
+
+	title := ""
+	if k := skipSpace(src, j); k > j && k < len(src) && strings.IndexByte("\"'(", src[k]) >= 0 {
+		closeCh := src[k]
+		if closeCh == '(' {
+			closeCh = ')'
+		}
+		m := k + 1
+		for m < len(src) && src[m] != closeCh {
+			if src[m] == '\\' {
+				m++
+			}
+			m++
+		}
+		if m >= len(src) {
+			return "", "", -1
+		}
+		title = src[k+1 : m]
+		j = m + 1
+	}
+
+	j = skipSpace(src, j)
+	if j >= len(src) || src[j] != ')' {
+		return "", "", -1
+	}
+	return unescapeMarkdown(dest), unescapeMarkdown(title), j + 1
+}
+
+// autolink 处理尖括号中的网址和邮箱地址
+func (p *inlineParser) autolink(pos int) (int, bool) {
+	if p.noLinks {
+		return 0, false
+	}
+	rest := p.src[pos:]
+	if m := mdAutoURLRe.FindStringSubmatch(rest); m != nil {
+		href, external, ok := safeURL(m[1], false)
+		if !ok {
+			return 0, false
+		}
+		p.html(fmt.Sprintf("<a href=\"%s\"%s>%s</a>", html.EscapeString(href), externalAttrs(external), html.EscapeString(m[1])))
+		return pos + len(m[0]), true
+	}
+	if m := mdAutoMailRe.FindStringSubmatch(rest); m != nil {
+		p.html(fmt.Sprintf("<a href=\"mailto:%s\">%s</a>", html.EscapeString(m[1]), html.EscapeString(m[1])))
+		return pos + len(m[0]), true
+	}
+	return 0, false
+}
+
+// rawTag 处理允许的HTML标签，结束标签必须与最近未闭合的开始标签对应
+func (p *inlineParser) rawTag(pos int) (int, bool) {
+	m := mdRawTagRe.FindStringSubmatch(p.src[pos:])
+	if m == nil {
+		return 0, false
+	}
+	name := strings.ToLower(m[2])
+	if !markdownTags[name] {
+		return 0, false
+	}
+
+	switch {
+	case name == "br":
+		p.html("<br>")
+	case m[1] == "" && m[3] == "":
+		p.tags = append(p.tags, name)
+		p.html("<" + name + ">")
+	case m[1] == "/" && len(p.tags) > 0 && p.tags[len(p.tags)-1] == name:
+		p.tags = p.tags[:len(p.tags)-1]
+		p.html("</" + name + ">")
+	default:
+		return 0, false
+	}
+	return pos + len(m[0]), true
+}
+
+// entity 处理HTML实体，解码后重新转义
+func (p *inlineParser) entity(pos int) int {
+	if m := mdEntityRe.FindString(p.src[pos:]); m != "" {
+		if decoded := html.UnescapeString(m); decoded != m {
+			p.text(decoded)
+			return pos + len(m)
+		}
+	}
+	p.text("&")
+	return pos + 1
+}
+
+// colored 处理彩色文本:color[文本]
+func (p *inlineParser) colored(pos int) (int, bool) {
+	m := mdColorRe.FindStringSubmatch(p.src[pos:])
+	if m == nil {
+		return 0, false
+	}
+	class, exists := markdownColors[m[1]]
+	if !exists {
+		return 0, false
+	}
+	open := pos + len(m[0]) - 1
+	closeAt := matchBracket(p.src, open)
+	if closeAt < 0 {
+		return 0, false
+	}
+	inner := renderInline(p.src[open+1:closeAt], p.refs, p.noLinks)
+	p.html(fmt.Sprintf("<span class=\"st-md-%s\">%s</span>", class, inner))
+	return closeAt + 1, true
+}
+
+// emoji 处理emoji短代码
+func (p *inlineParser) emoji(pos int) (int, bool) {
+	m := mdEmojiRe.FindStringSubmatch(p.src[pos:])
+	if m == nil {
+		return 0, false
+	}
+	emoji, exists := markdownEmoji[m[1]]
+	if !exists {
+		return 0, false
+	}
+	p.text(emoji)
+	return pos + len(m[0]), true
+}
+
+// bareLink 把文本中以http://、https://或www.开头的网址转换为链接
+func (p *inlineParser) bareLink(pos int) (int, bool) {
+	if p.noLinks {
+		return 0, false
+	}
+	rest := p.src[pos:]
+	prefix := ""
+	for _, candidate := range []string{"https://", "http://", "www."} {
+		if strings.HasPrefix(rest, candidate) {
+			prefix = candidate
+			break
+		}
+	}
+	if prefix == "" {
+		return 0, false
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '<' })
+	if end < 0 {
+		end = len(rest)
+	}
+	link := rest[:end]
+	// 去除末尾的标点和不成对的右括号
+	for len(link) > len(prefix) {
+		last := link[len(link)-1]
+		if strings.IndexByte("?!.,:*_~'\";", last) >= 0 ||
+			(last == ')' && strings.Count(link, "(") < strings.Count(link, ")")) {
+			link = link[:len(link)-1]
+			continue
+		}
+		break
+	}
+	if len(link) == len(prefix) {
+		return 0, false
+	}
+
+	href := link
+	if prefix == "www." {
+		href = "http://" + link
+	}
+	p.html(fmt.Sprintf("<a href=\"%s\"%s>%s</a>", html.EscapeString(href), externalAttrs(true), html.EscapeString(link)))
+	return pos + len(link), true
+}
+
+// safeURL 检查链接地址的协议，external表示http或https地址，在新窗口中打开
+func safeURL(u string, image bool) (href string, external bool, ok bool) {
+	u = strings.TrimSpace(u)
+	// 含控制字符的地址可能被浏览器忽略控制字符后解析为其他协议，例如"java\tscript:"
+	if strings.IndexFunc(u, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		return "", false, false
+	}
+	if i := strings.IndexAny(u, ":/?#"); i > 0 && u[i] == ':' {
+		scheme := strings.ToLower(u[:i])
+		web := scheme == "http" || scheme == "https"
+		if image {
+			return u, web, web
+		}
+		return u, web, markdownSchemes[scheme]
+	}
+	return strings.ReplaceAll(u, " ", "%20"), false, true
+}
+
+// externalAttrs 外部链接在新窗口中打开
+func externalAttrs(external bool) string {
+	if external {
+		return " target=\"_blank\" rel=\"noopener noreferrer\""
+	}
+	return ""
+}
+
+// unescapeMarkdown 处理反斜杠转义和HTML实体，得到链接地址等属性的原始值
+func unescapeMarkdown(s string) string {
+	if !strings.ContainsAny(s, "\\&") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+// skipSpace 跳过空白字符
+func skipSpace(s string, pos int) int {
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t' || s[pos] == '\n') {
+		pos++
+	}
+	return pos
+}
+
+// isASCIIPunct 判断是否为可以用反斜杠转义的ASCII标点
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// isPunctRune 判断是否为标点或符号，用于强调分隔符的左右侧规则
+func isPunctRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
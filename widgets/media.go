@@ -0,0 +1,343 @@
+package widgets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mediaTypes 系统MIME表中可能缺失的常用媒体类型
+var mediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".m4a":  "audio/mp4",
+	".flac": "audio/flac",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".ogv":  "video/ogg",
+	".mov":  "video/quicktime",
+}
+
+// MediaFile 组件引用的媒体内容，按内容哈希寻址
+//
+// 媒体内容不内联到组件HTML中，组件只渲染media/{哈希}地址，由服务的/media/路由提供，
+// 因此重新渲染页面时不会重复发送媒体内容。
+type MediaFile struct {
+	hash        string
+	contentType string
+	size        int64
+	modTime     time.Time
+	open        func() (io.ReadSeeker, error)
+}
+
+// NewMediaBytes 创建内存中的媒体内容，contentType为空时根据内容推断
+func NewMediaBytes(data []byte, contentType string) *MediaFile {
+	sum := sha256.Sum256(data)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return &MediaFile{
+		hash:        hex.EncodeToString(sum[:]),
+		contentType: contentType,
+		size:        int64(len(data)),
+		modTime:     time.Now(),
+		open: func() (io.ReadSeeker, error) {
+			return bytes.NewReader(data), nil
+		},
+	}
+}
+
+// NewMediaFile 创建磁盘文件的媒体内容，每次请求时重新打开文件
+//
+// 哈希在创建时根据文件内容计算，之后文件不应再被修改。
+func NewMediaFile(path string) (*MediaFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("media: %s is a directory", path)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	contentType := mediaTypes[strings.ToLower(filepath.Ext(path))]
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(path))
+	}
+	if contentType == "" {
+		head := make([]byte, 512)
+		n, _ := file.ReadAt(head, 0)
+		contentType = http.DetectContentType(head[:n])
+	}
+
+	return &MediaFile{
+		hash:        hex.EncodeToString(hash.Sum(nil)),
+		contentType: contentType,
+		size:        info.Size(),
+		modTime:     info.ModTime(),
+		open: func() (io.ReadSeeker, error) {
+			return os.Open(path)
+		},
+	}, nil
+}
+
+// NewMediaReader 创建来自io.ReadSeeker的媒体内容，contentType为空时根据内容推断
+//
+// 多个请求并发读取时共享r，读取时加锁并定位到各自的偏移，r在组件的生命周期内不应被关闭。
+func NewMediaReader(r io.ReadSeeker, contentType string) (*MediaFile, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	head := &prefixWriter{limit: 512}
+	size, err := io.Copy(io.MultiWriter(hash, head), r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(head.data)
+	}
+
+	shared := &sharedReaderAt{r: r}
+	return &MediaFile{
+		hash:        hex.EncodeToString(hash.Sum(nil)),
+		contentType: contentType,
+		size:        size,
+		modTime:     time.Now(),
+		open: func() (io.ReadSeeker, error) {
+			return io.NewSectionReader(shared, 0, size), nil
+		},
+	}, nil
+}
+
+// Hash 获取内容哈希
+func (m *MediaFile) Hash() string {
+	return m.hash
+}
+
+// URL 获取媒体地址，相对于页面地址，挂载在子路径时同样有效
+func (m *MediaFile) URL() string {
+	return "media/" + m.hash
+}
+
+// ContentType 获取MIME类型
+func (m *MediaFile) ContentType() string {
+	return m.contentType
+}
+
+// Size 获取内容大小（字节）
+func (m *MediaFile) Size() int64 {
+	return m.size
+}
+
+// ModTime 获取内容的修改时间
+func (m *MediaFile) ModTime() time.Time {
+	return m.modTime
+}
+
+// Open 打开媒体内容，返回值实现io.Closer时调用方负责关闭
+func (m *MediaFile) Open() (io.ReadSeeker, error) {
+	return m.open()
+}
+
+// IMedia 引用媒体内容的组件接口
+//
+// 服务在渲染时记录每个会话引用的媒体，/media/路由只提供该会话最近一次渲染引用的媒体，
+// 会话过期后其引用被释放，不再被任何会话引用的媒体随即被移除。
+type IMedia interface {
+	MediaFiles() []*MediaFile
+}
+
+// prefixWriter 记录写入内容的前limit个字节，用于推断内容类型
+type prefixWriter struct {
+	limit int
+	data  []byte
+}
+
+// Write 写入内容
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	if remaining := w.limit - len(w.data); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		w.data = append(w.data, p[:remaining]...)
+	}
+	return len(p), nil
+}
+
+// sharedReaderAt 把io.ReadSeeker包装为可并发使用的io.ReaderAt
+type sharedReaderAt struct {
+	mutex sync.Mutex
+	r     io.ReadSeeker
+}
+
+// ReadAt 加锁后定位到off读取
+func (s *sharedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// newMedia 根据组件接受的数据源创建媒体内容：[]byte、文件路径或io.ReadSeeker
+func newMedia(source interface{}) (*MediaFile, error) {
+	switch v := source.(type) {
+	case []byte:
+		return NewMediaBytes(v, ""), nil
+	case string:
+		return NewMediaFile(v)
+	case io.ReadSeeker:
+		return NewMediaReader(v, "")
+	case *MediaFile:
+		return v, nil
+	}
+	return nil, fmt.Errorf("media: unsupported source type %T", source)
+}
+
+// playerOptions 音频和视频组件的播放选项
+type playerOptions struct {
+	loop     bool
+	autoplay bool
+}
+
+// SetLoop 设置是否循环播放
+func (p *playerOptions) SetLoop(loop bool) {
+	p.loop = loop
+}
+
+// SetAutoplay 设置是否自动播放，视频自动播放时静音，否则浏览器通常会阻止播放
+func (p *playerOptions) SetAutoplay(autoplay bool) {
+	p.autoplay = autoplay
+}
+
+// attrs 渲染播放选项属性，muted表示自动播放时静音
+func (p *playerOptions) attrs(muted bool) SafeHTML {
+	var attrs SafeHTML
+	if p.loop {
+		attrs += " loop"
+	}
+	if p.autoplay {
+		attrs += " autoplay"
+		if muted {
+			attrs += " muted"
+		}
+	}
+	return attrs
+}
+
+// AudioWidget 音频组件
+type AudioWidget struct {
+	*BaseWidget
+	playerOptions
+	media *MediaFile
+}
+
+// NewAudio 创建新的音频组件，source可以是[]byte、文件路径、io.ReadSeeker或*MediaFile
+func NewAudio(source interface{}) (*AudioWidget, error) {
+	media, err := newMedia(source)
+	if err != nil {
+		return nil, err
+	}
+	return &AudioWidget{
+		BaseWidget: NewBaseWidget("audio"),
+		media:      media,
+	}, nil
+}
+
+// MediaFiles 获取引用的媒体内容
+func (w *AudioWidget) MediaFiles() []*MediaFile {
+	return []*MediaFile{w.media}
+}
+
+// Render 渲染音频组件为HTML
+func (w *AudioWidget) Render() string {
+	return HTMLf("<div class=\"st-audio\" data-widget-id=\"%s\"><audio controls preload=\"metadata\" src=\"%s\"%s></audio></div>",
+		w.GetID(), w.media.URL(), w.attrs(false)).String()
+}
+
+// Clone 复制音频组件，副本与原组件共享媒体内容
+func (w *AudioWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// VideoWidget 视频组件
+type VideoWidget struct {
+	*BaseWidget
+	playerOptions
+	media *MediaFile
+	width int
+}
+
+// NewVideo 创建新的视频组件，source可以是[]byte、文件路径、io.ReadSeeker或*MediaFile
+func NewVideo(source interface{}) (*VideoWidget, error) {
+	media, err := newMedia(source)
+	if err != nil {
+		return nil, err
+	}
+	return &VideoWidget{
+		BaseWidget: NewBaseWidget("video"),
+		media:      media,
+	}, nil
+}
+
+// SetWidth 设置显示宽度（像素），0表示占满容器宽度
+func (w *VideoWidget) SetWidth(width int) {
+	w.width = width
+}
+
+// MediaFiles 获取引用的媒体内容
+func (w *VideoWidget) MediaFiles() []*MediaFile {
+	return []*MediaFile{w.media}
+}
+
+// Render 渲染视频组件为HTML
+func (w *VideoWidget) Render() string {
+	var style SafeHTML
+	if w.width > 0 {
+		style = HTMLf(" style=\"max-width: %dpx\"", w.width)
+	}
+	return HTMLf("<div class=\"st-video\" data-widget-id=\"%s\"><video controls preload=\"metadata\" src=\"%s\"%s%s></video></div>",
+		w.GetID(), w.media.URL(), w.attrs(true), style).String()
+}
+
+// Clone 复制视频组件，副本与原组件共享媒体内容
+func (w *VideoWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
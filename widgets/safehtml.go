@@ -0,0 +1,88 @@
+package widgets
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// SafeHTML 可信的HTML片段，插入页面时原样输出
+//
+// 组件渲染的结果和RenderMarkdown的输出是SafeHTML，其他任何值插入HTML时都会被转义。
+// 把字符串转换为SafeHTML表示开发者确认其中没有不可信的内容，这是显示原始HTML的唯一方式，
+// 例如NewHTML(widgets.SafeHTML("<b>粗体</b>"))、NewWrite(widgets.SafeHTML(...))。
+type SafeHTML string
+
+// String 获取HTML文本
+func (h SafeHTML) String() string {
+	return string(h)
+}
+
+// HTMLf 按格式拼接HTML，format是可信的模板，参数中除SafeHTML外全部转义后插入
+//
+// 内置组件都通过HTMLf渲染，标签、输入值等参数无需手动转义，任何格式动词（%s、%v、%d等）都先格式化再转义；
+// 嵌入子组件或其他已生成的HTML时参数使用SafeHTML。
+func HTMLf(format string, args ...interface{}) SafeHTML {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		if safe, ok := arg.(SafeHTML); ok {
+			escaped[i] = string(safe)
+		} else {
+			escaped[i] = escapedArg{arg}
+		}
+	}
+	return SafeHTML(fmt.Sprintf(format, escaped...))
+}
+
+// escapedArg 按原格式动词格式化后转义的参数
+type escapedArg struct {
+	value interface{}
+}
+
+// Format 实现fmt.Formatter
+func (a escapedArg) Format(f fmt.State, verb rune) {
+	io.WriteString(f, html.EscapeString(fmt.Sprintf(fmt.FormatString(f, verb), a.value)))
+}
+
+// renderChildren 依次渲染子组件
+func renderChildren(children []Widget) SafeHTML {
+	var b strings.Builder
+	for _, child := range children {
+		b.WriteString(child.Render())
+	}
+	return SafeHTML(b.String())
+}
+
+// HTMLWidget 原始HTML组件，内容不经转义直接输出
+//
+// 内容必须是开发者生成的可信HTML，用户提交的富文本应使用Markdown组件。
+type HTMLWidget struct {
+	*BaseWidget
+	content SafeHTML
+}
+
+// NewHTML 创建新的原始HTML组件
+func NewHTML(content SafeHTML) *HTMLWidget {
+	return &HTMLWidget{
+		BaseWidget: NewBaseWidget("html"),
+		content:    content,
+	}
+}
+
+// SetContent 设置HTML内容
+func (w *HTMLWidget) SetContent(content SafeHTML) {
+	w.content = content
+}
+
+// Render 渲染原始HTML组件
+func (w *HTMLWidget) Render() string {
+	return HTMLf("<div class=\"st-html\" data-widget-id=\"%s\">%s</div>", w.GetID(), w.content).String()
+}
+
+// Clone 复制原始HTML组件
+func (w *HTMLWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
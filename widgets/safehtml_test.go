@@ -0,0 +1,185 @@
+package widgets
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"testing"
+)
+
+// hostileValues 试图注入脚本的输入
+var hostileValues = []string{
+	`<script>alert(1)</script>`,
+	`"><img src=x onerror=alert(1)>`,
+	`javascript:alert(1)`,
+}
+
+// assertEscaped 检查渲染结果只包含转义后的输入，没有注入的标签、属性和脚本URL
+func assertEscaped(t *testing.T, output string, value string) {
+	t.Helper()
+	if strings.ContainsAny(value, "<>\"'&") {
+		if strings.Contains(output, value) {
+			t.Errorf("output contains unescaped %q: %s", value, output)
+		}
+		if !strings.Contains(output, html.EscapeString(value)) {
+			t.Errorf("output does not contain escaped %q: %s", value, output)
+		}
+	}
+	for _, injected := range []string{"<script", "<img", `href="javascript:`, `src="javascript:`, `action="javascript:`} {
+		if strings.Contains(output, injected) {
+			t.Errorf("output contains %q: %s", injected, output)
+		}
+	}
+}
+
+// hostileStringer String方法返回HTML的值
+type hostileStringer struct{}
+
+func (hostileStringer) String() string { return `<script>alert(1)</script>` }
+
+func TestHTMLfEscapesArguments(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		arg    interface{}
+		want   SafeHTML
+	}{
+		{"string", "<p>%s</p>", `<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"attribute breakout", `<a title="%s">`, `"><img src=x onerror=alert(1)>`, `<a title="&#34;&gt;&lt;img src=x onerror=alert(1)&gt;">`},
+		{"single quote", `<a title='%s'>`, `'x'`, `<a title='&#39;x&#39;'>`},
+		{"value verb", "<p>%v</p>", `a&b`, "<p>a&amp;b</p>"},
+		{"quoted verb", "<p>%q</p>", `"<b>"`, `<p>&#34;\&#34;&lt;b&gt;\&#34;&#34;</p>`},
+		{"width flag", "<p>%6s</p>", `<b>`, "<p>   &lt;b&gt;</p>"},
+		{"stringer", "<p>%s</p>", hostileStringer{}, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"error", "<p>%v</p>", errors.New("<b>failed</b>"), "<p>&lt;b&gt;failed&lt;/b&gt;</p>"},
+		{"integer", "<p>%d</p>", 42, "<p>42</p>"},
+		{"safe html", "<p>%s</p>", SafeHTML("<b>bold</b>"), "<p><b>bold</b></p>"},
+		{"percent", "<p>100%%%s</p>", "<", "<p>100%&lt;</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLf(tt.format, tt.arg); got != tt.want {
+				t.Errorf("HTMLf(%q, %#v) = %q, want %q", tt.format, tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLfMixedArguments(t *testing.T) {
+	got := HTMLf("<div title=\"%s\">%s%s</div>", `"x"`, SafeHTML("<hr>"), "<hr>")
+	want := SafeHTML(`<div title="&#34;x&#34;"><hr>&lt;hr&gt;</div>`)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteRender(t *testing.T) {
+	for _, value := range hostileValues {
+		assertEscaped(t, NewWrite(value).Render(), value)
+		assertEscaped(t, NewWrite([]string{value}).Render(), value)
+		assertEscaped(t, NewWrite(map[string]string{value: value}).Render(), value)
+		assertEscaped(t, NewWrite(errors.New(value)).Render(), value)
+	}
+	if out := NewWrite(SafeHTML("<b>bold</b>")).Render(); !strings.Contains(out, "<b>bold</b>") {
+		t.Errorf("SafeHTML was not passed through: %s", out)
+	}
+}
+
+func TestMetricRender(t *testing.T) {
+	for _, value := range hostileValues {
+		metric := NewMetric(value, value)
+		metric.SetDelta(value)
+		out := metric.Render()
+		assertEscaped(t, out, value)
+		if !strings.Contains(out, "st-metric-delta") {
+			t.Errorf("delta not rendered: %s", out)
+		}
+	}
+	if out := NewMetric("收入", SafeHTML("<b>1</b>")).Render(); !strings.Contains(out, "<b>1</b>") {
+		t.Errorf("SafeHTML value was not passed through: %s", out)
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	type record struct {
+		Name  string
+		Score float64
+	}
+	for _, value := range hostileValues {
+		inputs := []interface{}{
+			[]string{value},             // 标量切片
+			[][]interface{}{{value, 1}}, // 二维切片
+			Rows{Headers: []string{value}, Data: [][]string{{value}}},
+			[]map[string]interface{}{{value: value}}, // map切片，键为列名
+			map[string]string{value: value},          // 单个map显示为键值两列
+			[]record{{Name: value, Score: 1.5}},
+			record{Name: value},
+			[]*string{&value, nil},
+			[]interface{}{hostileStringer{}, errors.New(value)},
+		}
+		for _, data := range inputs {
+			table := NewTable(data)
+			table.SetColumns(TableColumn{Name: "Name", Label: value})
+			assertEscaped(t, table.Render(), value)
+		}
+	}
+
+	// 不支持的数据类型显示错误
+	if out := NewTable(make(chan int)).Render(); !strings.Contains(out, "st-table-error") {
+		t.Errorf("unsupported data did not render an error: %s", out)
+	}
+}
+
+func TestDataFrameRender(t *testing.T) {
+	type record struct {
+		Name string
+		City string
+	}
+	for _, value := range hostileValues {
+		frame := NewDataFrame([]record{{Name: value, City: value}, {Name: "b", City: "c"}})
+		frame.SetColumns(TableColumn{Name: "Name", Label: value})
+		frame.SetSortable(true)
+		frame.SetSearchable(true)
+		frame.SetFilterable(true)
+		frame.SetSelectionMode(MultiRow)
+		if err := frame.BindValue(nil, "search", value); err != nil {
+			t.Fatal(err)
+		}
+		assertEscaped(t, frame.Render(), value)
+
+		if err := frame.BindValue(nil, "search", ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := frame.BindValue(nil, "sort", "Name"); err != nil {
+			t.Fatal(err)
+		}
+		assertEscaped(t, frame.Render(), value)
+	}
+}
+
+func TestExpanderRender(t *testing.T) {
+	for _, value := range hostileValues {
+		expander := NewExpander(value, true)
+		expander.AddChild(NewText(value))
+		assertEscaped(t, expander.Render(), value)
+	}
+
+	expander := NewExpander("详情", false)
+	expander.AddChild(NewHTML(SafeHTML("<b>bold</b>")))
+	if out := expander.Render(); !strings.Contains(out, "<b>bold</b>") {
+		t.Errorf("child HTML was not passed through: %s", out)
+	}
+}
+
+func TestButtonRender(t *testing.T) {
+	for _, value := range hostileValues {
+		assertEscaped(t, NewButton(value).Render(), value)
+	}
+}
+
+func TestHTMLWidgetPassesThrough(t *testing.T) {
+	content := SafeHTML(`<a href="https://example.com" onclick="track()">link</a>`)
+	if out := NewHTML(content).Render(); !strings.Contains(out, string(content)) {
+		t.Errorf("SafeHTML content was changed: %s", out)
+	}
+}
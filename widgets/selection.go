@@ -0,0 +1,529 @@
+package widgets
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CheckboxWidget 复选框组件
+type CheckboxWidget struct {
+	*BaseWidget
+	label   string
+	value   bool
+	initial bool // 创建时的值，Reset时恢复
+}
+
+// NewCheckbox 创建新的复选框组件
+func NewCheckbox(label string, value bool) *CheckboxWidget {
+	return newCheckbox("checkbox", label, value)
+}
+
+// newCheckbox 创建指定类型的复选框组件
+func newCheckbox(widgetType string, label string, value bool) *CheckboxWidget {
+	return &CheckboxWidget{
+		BaseWidget: NewBaseWidget(widgetType),
+		label:      label,
+		value:      value,
+		initial:    value,
+	}
+}
+
+// Render 渲染复选框组件为HTML
+func (w *CheckboxWidget) Render() string {
+	return w.render("st-checkbox")
+}
+
+// render 按指定样式渲染复选框
+func (w *CheckboxWidget) render(class string) string {
+	var checked SafeHTML
+	if w.value {
+		checked = " checked"
+	}
+	return HTMLf("<div class=\"%s-container\" data-widget-id=\"%s\"><label class=\"%s\"><input type=\"checkbox\" data-widget-id=\"%s\" data-event-type=\"change\"%s><span>%s</span></label></div>",
+		class, w.GetID(), class, w.GetID(), checked, w.label).String()
+}
+
+// Clone 复制复选框组件
+func (w *CheckboxWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetValue 设置选中状态
+func (w *CheckboxWidget) SetValue(session ISession, value bool) {
+	w.value = value
+	w.TriggerCallbacks(session, "change", strconv.FormatBool(value))
+}
+
+// BindValue 保存客户端发送的选中状态
+func (w *CheckboxWidget) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	checked, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid value %q", w.GetType(), w.GetID(), value)
+	}
+	w.value = checked
+	return nil
+}
+
+// Reset 恢复创建时的选中状态
+func (w *CheckboxWidget) Reset() {
+	w.value = w.initial
+}
+
+// GetValue 获取选中状态
+func (w *CheckboxWidget) GetValue() bool {
+	return w.value
+}
+
+// ToggleWidget 开关组件，行为与复选框相同，显示为开关样式
+type ToggleWidget struct {
+	*CheckboxWidget
+}
+
+// NewToggle 创建新的开关组件
+func NewToggle(label string, value bool) *ToggleWidget {
+	return &ToggleWidget{
+		CheckboxWidget: newCheckbox("toggle", label, value),
+	}
+}
+
+// Render 渲染开关组件为HTML
+func (w *ToggleWidget) Render() string {
+	return w.render("st-toggle")
+}
+
+// Clone 复制开关组件
+func (w *ToggleWidget) Clone() Widget {
+	return &ToggleWidget{
+		CheckboxWidget: w.CheckboxWidget.Clone().(*CheckboxWidget),
+	}
+}
+
+// optionList 选项列表，供选择类组件共用
+type optionList[T any] struct {
+	options []T
+	format  func(option T) string
+}
+
+// newOptionList 创建选项列表，默认使用fmt.Sprint显示选项
+func newOptionList[T any](options []T) optionList[T] {
+	return optionList[T]{
+		options: options,
+		format: func(option T) string {
+			return fmt.Sprint(option)
+		},
+	}
+}
+
+// SetFormatFunc 设置选项的显示文本格式化函数
+func (o *optionList[T]) SetFormatFunc(format func(option T) string) {
+	if format != nil {
+		o.format = format
+	}
+}
+
+// GetOptions 获取选项列表
+func (o *optionList[T]) GetOptions() []T {
+	return o.options
+}
+
+// optionLabel 获取选项的显示文本
+func (o *optionList[T]) optionLabel(index int) string {
+	return o.format(o.options[index])
+}
+
+// validIndex 检查选项索引是否有效
+func (o *optionList[T]) validIndex(index int) bool {
+	return index >= 0 && index < len(o.options)
+}
+
+// indexOf 查找与option相等的选项的索引，不存在时返回-1
+func (o *optionList[T]) indexOf(option T) int {
+	for i := range o.options {
+		if reflect.DeepEqual(o.options[i], option) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseIndex 解析客户端发送的选项索引
+func (o *optionList[T]) parseIndex(value string) (int, error) {
+	index, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || !o.validIndex(index) {
+		return 0, fmt.Errorf("invalid option index %q", value)
+	}
+	return index, nil
+}
+
+// RadioWidget 单选组件
+type RadioWidget[T any] struct {
+	*BaseWidget
+	optionList[T]
+	label      string
+	index      int
+	initial    int // 创建时选中项的索引，Reset时恢复
+	horizontal bool
+}
+
+// NewRadio 创建新的单选组件，index为默认选中项的索引，-1表示不选中
+func NewRadio[T any](label string, options []T, index int) *RadioWidget[T] {
+	w := &RadioWidget[T]{
+		BaseWidget: NewBaseWidget("radio"),
+		optionList: newOptionList(options),
+		label:      label,
+		index:      -1,
+	}
+	if w.validIndex(index) {
+		w.index = index
+	}
+	w.initial = w.index
+	return w
+}
+
+// Render 渲染单选组件为HTML
+func (w *RadioWidget[T]) Render() string {
+	class := "st-radio-options"
+	if w.horizontal {
+		class += " st-radio-horizontal"
+	}
+
+	var b strings.Builder
+	for i := range w.options {
+		var checked SafeHTML
+		if i == w.index {
+			checked = " checked"
+		}
+		b.WriteString(HTMLf("<label><input type=\"radio\" name=\"%s\" value=\"%d\"%s><span>%s</span></label>",
+			w.GetID(), i, checked, w.optionLabel(i)).String())
+	}
+
+	return HTMLf("<div class=\"st-radio-container\" data-widget-id=\"%s\"><label>%s</label><div class=\"%s\" data-widget-id=\"%s\" data-event-type=\"change\">%s</div></div>",
+		w.GetID(), w.label, class, w.GetID(), SafeHTML(b.String())).String()
+}
+
+// Clone 复制单选组件
+func (w *RadioWidget[T]) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetHorizontal 设置选项是否水平排列
+func (w *RadioWidget[T]) SetHorizontal(horizontal bool) {
+	w.horizontal = horizontal
+}
+
+// SetIndex 设置选中项的索引，-1表示不选中
+func (w *RadioWidget[T]) SetIndex(session ISession, index int) {
+	if !w.validIndex(index) {
+		index = -1
+	}
+	w.index = index
+	w.TriggerCallbacks(session, "change", strconv.Itoa(index))
+}
+
+// SetOptions 替换选项列表，选中的选项仍在新列表中时保持选中，否则不选中，不触发回调
+func (w *RadioWidget[T]) SetOptions(options []T) {
+	value, ok := w.GetValue()
+	w.options = options
+	w.index = -1
+	if ok {
+		w.index = w.indexOf(value)
+	}
+}
+
+// BindValue 保存客户端选中的选项索引
+func (w *RadioWidget[T]) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	index, err := w.parseIndex(value)
+	if err != nil {
+		return fmt.Errorf("radio %s: %w", w.GetID(), err)
+	}
+	w.index = index
+	return nil
+}
+
+// Reset 恢复创建时的选中项，选项已经替换时按索引恢复
+func (w *RadioWidget[T]) Reset() {
+	w.index = -1
+	if w.validIndex(w.initial) {
+		w.index = w.initial
+	}
+}
+
+// GetIndex 获取选中项的索引，未选中时返回-1
+func (w *RadioWidget[T]) GetIndex() int {
+	return w.index
+}
+
+// GetValue 获取选中的选项，未选中时返回T的零值和false
+func (w *RadioWidget[T]) GetValue() (T, bool) {
+	var zero T
+	if !w.validIndex(w.index) {
+		return zero, false
+	}
+	return w.options[w.index], true
+}
+
+// SelectboxWidget 下拉选择组件
+type SelectboxWidget[T any] struct {
+	*BaseWidget
+	optionList[T]
+	label       string
+	index       int
+	initial     int // 创建时选中项的索引，Reset时恢复
+	placeholder string
+}
+
+// NewSelectbox 创建新的下拉选择组件，index为默认选中项的索引，-1表示不选中
+func NewSelectbox[T any](label string, options []T, index int) *SelectboxWidget[T] {
+	w := &SelectboxWidget[T]{
+		BaseWidget:  NewBaseWidget("selectbox"),
+		optionList:  newOptionList(options),
+		label:       label,
+		index:       -1,
+		placeholder: "请选择",
+	}
+	if w.validIndex(index) {
+		w.index = index
+	}
+	w.initial = w.index
+	return w
+}
+
+// Render 渲染下拉选择组件为HTML
+func (w *SelectboxWidget[T]) Render() string {
+	var b strings.Builder
+	if w.index < 0 {
+		b.WriteString(HTMLf("<option value=\"\" disabled selected>%s</option>", w.placeholder).String())
+	}
+	for i := range w.options {
+		var selected SafeHTML
+		if i == w.index {
+			selected = " selected"
+		}
+		b.WriteString(HTMLf("<option value=\"%d\"%s>%s</option>", i, selected, w.optionLabel(i)).String())
+	}
+
+	return HTMLf("<div class=\"st-selectbox-container\" data-widget-id=\"%s\"><label>%s</label><select class=\"st-selectbox\" data-widget-id=\"%s\" data-event-type=\"change\">%s</select></div>",
+		w.GetID(), w.label, w.GetID(), SafeHTML(b.String())).String()
+}
+
+// Clone 复制下拉选择组件
+func (w *SelectboxWidget[T]) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetPlaceholder 设置未选中时显示的提示文本
+func (w *SelectboxWidget[T]) SetPlaceholder(placeholder string) {
+	w.placeholder = placeholder
+}
+
+// SetIndex 设置选中项的索引，-1表示不选中
+func (w *SelectboxWidget[T]) SetIndex(session ISession, index int) {
+	if !w.validIndex(index) {
+		index = -1
+	}
+	w.index = index
+	w.TriggerCallbacks(session, "change", strconv.Itoa(index))
+}
+
+// SetOptions 替换选项列表，选中的选项仍在新列表中时保持选中，否则不选中，不触发回调
+func (w *SelectboxWidget[T]) SetOptions(options []T) {
+	value, ok := w.GetValue()
+	w.options = options
+	w.index = -1
+	if ok {
+		w.index = w.indexOf(value)
+	}
+}
+
+// BindValue 保存客户端选中的选项索引
+func (w *SelectboxWidget[T]) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	index, err := w.parseIndex(value)
+	if err != nil {
+		return fmt.Errorf("selectbox %s: %w", w.GetID(), err)
+	}
+	w.index = index
+	return nil
+}
+
+// Reset 恢复创建时的选中项，选项已经替换时按索引恢复
+func (w *SelectboxWidget[T]) Reset() {
+	w.index = -1
+	if w.validIndex(w.initial) {
+		w.index = w.initial
+	}
+}
+
+// GetIndex 获取选中项的索引，未选中时返回-1
+func (w *SelectboxWidget[T]) GetIndex() int {
+	return w.index
+}
+
+// GetValue 获取选中的选项，未选中时返回T的零值和false
+func (w *SelectboxWidget[T]) GetValue() (T, bool) {
+	var zero T
+	if !w.validIndex(w.index) {
+		return zero, false
+	}
+	return w.options[w.index], true
+}
+
+// MultiselectWidget 多选组件
+type MultiselectWidget[T any] struct {
+	*BaseWidget
+	optionList[T]
+	label    string
+	selected []int
+	initial  []int // 创建时选中项的索引，Reset时恢复
+}
+
+// NewMultiselect 创建新的多选组件，indices为默认选中项的索引
+func NewMultiselect[T any](label string, options []T, indices ...int) *MultiselectWidget[T] {
+	w := &MultiselectWidget[T]{
+		BaseWidget: NewBaseWidget("multiselect"),
+		optionList: newOptionList(options),
+		label:      label,
+	}
+	w.selected = w.normalize(indices)
+	w.initial = w.selected
+	return w
+}
+
+// normalize 去除无效和重复的索引，并按选项顺序排列
+func (w *MultiselectWidget[T]) normalize(indices []int) []int {
+	chosen := make([]bool, len(w.options))
+	for _, index := range indices {
+		if w.validIndex(index) {
+			chosen[index] = true
+		}
+	}
+
+	selected := make([]int, 0, len(indices))
+	for i, ok := range chosen {
+		if ok {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// isSelected 检查选项是否被选中
+func (w *MultiselectWidget[T]) isSelected(index int) bool {
+	for _, i := range w.selected {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// Render 渲染多选组件为HTML
+func (w *MultiselectWidget[T]) Render() string {
+	var b strings.Builder
+	for i := range w.options {
+		var checked SafeHTML
+		if w.isSelected(i) {
+			checked = " checked"
+		}
+		b.WriteString(HTMLf("<label><input type=\"checkbox\" value=\"%d\"%s><span>%s</span></label>",
+			i, checked, w.optionLabel(i)).String())
+	}
+
+	return HTMLf("<div class=\"st-multiselect-container\" data-widget-id=\"%s\"><label>%s</label><div class=\"st-multiselect-options\" data-widget-id=\"%s\" data-event-type=\"change\">%s</div></div>",
+		w.GetID(), w.label, w.GetID(), SafeHTML(b.String())).String()
+}
+
+// Clone 复制多选组件
+func (w *MultiselectWidget[T]) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.selected = append([]int(nil), w.selected...)
+	return &c
+}
+
+// SetIndices 设置选中项的索引
+func (w *MultiselectWidget[T]) SetIndices(session ISession, indices ...int) {
+	w.selected = w.normalize(indices)
+	w.TriggerCallbacks(session, "change", joinIndices(w.selected))
+}
+
+// SetOptions 替换选项列表，只保留仍在新列表中的选中项，不触发回调
+func (w *MultiselectWidget[T]) SetOptions(options []T) {
+	values := w.GetValue()
+	w.options = options
+	indices := make([]int, 0, len(values))
+	for _, value := range values {
+		if index := w.indexOf(value); index >= 0 {
+			indices = append(indices, index)
+		}
+	}
+	w.selected = w.normalize(indices)
+}
+
+// BindValue 保存客户端选中的选项索引，value为逗号分隔的索引列表
+func (w *MultiselectWidget[T]) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	indices := make([]int, 0)
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		index, err := w.parseIndex(part)
+		if err != nil {
+			return fmt.Errorf("multiselect %s: %w", w.GetID(), err)
+		}
+		indices = append(indices, index)
+	}
+	w.selected = w.normalize(indices)
+	return nil
+}
+
+// Reset 恢复创建时的选中项
+func (w *MultiselectWidget[T]) Reset() {
+	w.selected = w.normalize(w.initial)
+}
+
+// GetIndices 获取选中项的索引
+func (w *MultiselectWidget[T]) GetIndices() []int {
+	return append([]int(nil), w.selected...)
+}
+
+// GetValue 获取选中的选项
+func (w *MultiselectWidget[T]) GetValue() []T {
+	values := make([]T, len(w.selected))
+	for i, index := range w.selected {
+		values[i] = w.options[index]
+	}
+	return values
+}
+
+// joinIndices 将索引列表格式化为逗号分隔的字符串
+func joinIndices(indices []int) string {
+	parts := make([]string, len(indices))
+	for i, index := range indices {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,482 @@
+package widgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSliderLabels 滑块位置数不超过此值时，格式化后的刻度文本随页面下发，拖动时客户端即时显示当前值
+const maxSliderLabels = 500
+
+// Number 滑块支持的数值类型
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// IScale 滑块刻度接口，在值和滑块位置之间转换
+//
+// 滑块位置是从Min到Max、按Step对齐的浮点数，客户端只处理位置。
+type IScale[T any] interface {
+	// Bounds 获取位置的最小值、最大值和步长
+	Bounds() (min float64, max float64, step float64)
+
+	// Position 将值转换为位置
+	Position(value T) float64
+
+	// Value 将位置转换为值，位置按步长对齐并限制在范围内
+	Value(position float64) T
+
+	// Format 格式化值用于显示
+	Format(value T) string
+}
+
+// snapPosition 将位置按步长对齐并限制在范围内
+func snapPosition(position, min, max, step float64) float64 {
+	if math.IsNaN(position) || position <= min {
+		return min
+	}
+	if position >= max {
+		return max
+	}
+	if step > 0 {
+		position = min + math.Round((position-min)/step)*step
+		// 消除按步长对齐产生的浮点误差，如0.1+0.2
+		position, _ = strconv.ParseFloat(strconv.FormatFloat(position, 'f', stepDecimals(step)+stepDecimals(min), 64), 64)
+	}
+	return math.Min(position, max)
+}
+
+// NumberScale 数值刻度，支持整数和浮点数
+type NumberScale[T Number] struct {
+	min, max, step T
+}
+
+// NewNumberScale 创建数值刻度，step不大于0时整数使用1、浮点数使用0.01
+func NewNumberScale[T Number](min, max, step T) *NumberScale[T] {
+	if max < min {
+		min, max = max, min
+	}
+	if step <= 0 {
+		step = defaultNumberStep[T]()
+	}
+	return &NumberScale[T]{min: min, max: max, step: step}
+}
+
+// defaultNumberStep 获取数值类型的默认步长
+func defaultNumberStep[T Number]() T {
+	if isInteger[T]() {
+		return 1
+	}
+	return T(1) / T(100)
+}
+
+// isInteger 检查数值类型是否为整数类型
+func isInteger[T Number]() bool {
+	one := T(1)
+	return one/2 == 0
+}
+
+// Bounds 获取位置的最小值、最大值和步长
+func (s *NumberScale[T]) Bounds() (float64, float64, float64) {
+	return float64(s.min), float64(s.max), float64(s.step)
+}
+
+// Position 将值转换为位置
+func (s *NumberScale[T]) Position(value T) float64 {
+	return float64(value)
+}
+
+// Value 将位置转换为值
+func (s *NumberScale[T]) Value(position float64) T {
+	position = snapPosition(position, float64(s.min), float64(s.max), float64(s.step))
+	if isInteger[T]() {
+		return T(math.Round(position))
+	}
+	return T(position)
+}
+
+// Format 格式化数值，浮点数按步长的小数位数显示
+func (s *NumberScale[T]) Format(value T) string {
+	if isInteger[T]() {
+		return fmt.Sprint(value)
+	}
+	return strconv.FormatFloat(float64(value), 'f', stepDecimals(float64(s.step)), 64)
+}
+
+// stepDecimals 获取步长的小数位数
+func stepDecimals(step float64) int {
+	text := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		return len(text) - i - 1
+	}
+	return 0
+}
+
+// TimeScale 时间刻度，位置为Unix秒数
+type TimeScale struct {
+	min, max time.Time
+	step     time.Duration
+}
+
+// NewTimeScale 创建时间刻度，step不大于0时使用一天
+func NewTimeScale(min, max time.Time, step time.Duration) *TimeScale {
+	if max.Before(min) {
+		min, max = max, min
+	}
+	if step <= 0 {
+		step = 24 * time.Hour
+	}
+	return &TimeScale{min: min, max: max, step: step}
+}
+
+// Bounds 获取位置的最小值、最大值和步长
+func (s *TimeScale) Bounds() (float64, float64, float64) {
+	return s.Position(s.min), s.Position(s.max), s.step.Seconds()
+}
+
+// Position 将时间转换为Unix秒数
+func (s *TimeScale) Position(value time.Time) float64 {
+	return float64(value.Unix()) + float64(value.Nanosecond())/1e9
+}
+
+// Value 将Unix秒数转换为时间，使用最小值的时区
+func (s *TimeScale) Value(position float64) time.Time {
+	min, max, step := s.Bounds()
+	position = snapPosition(position, min, max, step)
+	offset := time.Duration(math.Round((position - min) * float64(time.Second)))
+	return s.min.Add(offset)
+}
+
+// Format 格式化时间，步长不小于一天时只显示日期
+func (s *TimeScale) Format(value time.Time) string {
+	if s.step >= 24*time.Hour && s.step%(24*time.Hour) == 0 {
+		return value.Format("2006-01-02")
+	}
+	if s.step%time.Minute == 0 {
+		return value.Format("2006-01-02 15:04")
+	}
+	return value.Format("2006-01-02 15:04:05")
+}
+
+// OptionScale 离散选项刻度，位置为选项索引
+type OptionScale[T any] struct {
+	optionList[T]
+}
+
+// NewOptionScale 创建离散选项刻度
+func NewOptionScale[T any](options []T) *OptionScale[T] {
+	return &OptionScale[T]{optionList: newOptionList(options)}
+}
+
+// Bounds 获取位置的最小值、最大值和步长
+func (s *OptionScale[T]) Bounds() (float64, float64, float64) {
+	return 0, math.Max(float64(len(s.options)-1), 0), 1
+}
+
+// Position 将选项转换为索引，选项不在列表中时返回0
+//
+// 选项类型不可比较时无法查找，此时应使用NewOptionSlider等按索引创建滑块。
+func (s *OptionScale[T]) Position(value T) float64 {
+	for i, option := range s.options {
+		if equalOption(option, value) {
+			return float64(i)
+		}
+	}
+	return 0
+}
+
+// equalOption 比较两个选项是否相等，不可比较的类型视为不相等
+func equalOption[T any](a, b T) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return any(a) == any(b)
+}
+
+// Value 将索引转换为选项，没有选项时返回T的零值
+func (s *OptionScale[T]) Value(position float64) T {
+	var zero T
+	if len(s.options) == 0 {
+		return zero
+	}
+	min, max, step := s.Bounds()
+	return s.options[int(math.Round(snapPosition(position, min, max, step)))]
+}
+
+// Format 格式化选项
+func (s *OptionScale[T]) Format(value T) string {
+	return s.format(value)
+}
+
+// sliderBase 滑块组件的公共部分
+type sliderBase[T any] struct {
+	label  string
+	scale  IScale[T]
+	format func(value T) string
+}
+
+// formatValue 格式化值，优先使用自定义格式化函数
+func (s *sliderBase[T]) formatValue(value T) string {
+	if s.format != nil {
+		return s.format(value)
+	}
+	return s.scale.Format(value)
+}
+
+// SetFormatFunc 设置值的显示格式化函数
+func (s *sliderBase[T]) SetFormatFunc(format func(value T) string) {
+	s.format = format
+}
+
+// snap 将位置按刻度的步长对齐并限制在范围内
+func (s *sliderBase[T]) snap(position float64) float64 {
+	min, max, step := s.scale.Bounds()
+	return snapPosition(position, min, max, step)
+}
+
+// parsePosition 解析客户端发送的位置并按刻度对齐
+func (s *sliderBase[T]) parsePosition(value string) (float64, error) {
+	position, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(position) || math.IsInf(position, 0) {
+		return 0, fmt.Errorf("invalid slider position %q", value)
+	}
+	return s.snap(position), nil
+}
+
+// renderAttrs 渲染滑块的范围属性和供客户端即时显示的刻度文本
+func (s *sliderBase[T]) renderAttrs() (SafeHTML, SafeHTML) {
+	min, max, step := s.scale.Bounds()
+	rangeAttrs := HTMLf(" min=\"%s\" max=\"%s\" step=\"%s\"", formatPosition(min), formatPosition(max), formatPosition(step))
+
+	var labelsAttr SafeHTML
+	if step > 0 && (max-min)/step < maxSliderLabels {
+		count := int(math.Round((max-min)/step)) + 1
+		labels := make([]string, count)
+		for i := range labels {
+			labels[i] = s.formatValue(s.scale.Value(min + float64(i)*step))
+		}
+		data, _ := json.Marshal(labels)
+		labelsAttr = HTMLf(" data-min=\"%s\" data-step=\"%s\" data-labels=\"%s\"",
+			formatPosition(min), formatPosition(step), string(data))
+	}
+	return rangeAttrs, labelsAttr
+}
+
+// renderBounds 渲染最小值和最大值的显示文本
+func (s *sliderBase[T]) renderBounds() SafeHTML {
+	min, max, _ := s.scale.Bounds()
+	return HTMLf("<div class=\"st-slider-bounds\"><span>%s</span><span>%s</span></div>",
+		s.formatValue(s.scale.Value(min)), s.formatValue(s.scale.Value(max)))
+}
+
+// formatPosition 格式化滑块位置
+func formatPosition(position float64) string {
+	return strconv.FormatFloat(position, 'f', -1, 64)
+}
+
+// SliderWidget 滑块组件，拖动结束时发送change事件
+type SliderWidget[T any] struct {
+	*BaseWidget
+	sliderBase[T]
+	position float64 // 当前值对应的位置
+	initial  float64 // 创建时的位置，Reset时恢复
+}
+
+// NewSlider 创建数值滑块组件，整数步长为1，浮点数步长为0.01
+func NewSlider[T Number](label string, min, max, value T) *SliderWidget[T] {
+	return NewScaleSlider[T](label, NewNumberScale(min, max, 0), value)
+}
+
+// NewTimeSlider 创建时间滑块组件，step不大于0时使用一天
+func NewTimeSlider(label string, min, max, value time.Time, step time.Duration) *SliderWidget[time.Time] {
+	return NewScaleSlider[time.Time](label, NewTimeScale(min, max, step), value)
+}
+
+// NewOptionSlider 创建离散选项滑块组件，index为默认选项的索引
+func NewOptionSlider[T any](label string, options []T, index int) *SliderWidget[T] {
+	w := NewScaleSlider[T](label, NewOptionScale(options), *new(T))
+	w.position = w.snap(float64(index))
+	w.initial = w.position
+	return w
+}
+
+// NewScaleSlider 创建使用指定刻度的滑块组件
+func NewScaleSlider[T any](label string, scale IScale[T], value T) *SliderWidget[T] {
+	w := &SliderWidget[T]{
+		BaseWidget: NewBaseWidget("slider"),
+		sliderBase: sliderBase[T]{label: label, scale: scale},
+	}
+	w.position = w.snap(scale.Position(value))
+	w.initial = w.position
+	return w
+}
+
+// Render 渲染滑块组件为HTML
+func (w *SliderWidget[T]) Render() string {
+	rangeAttrs, labelsAttr := w.renderAttrs()
+	return HTMLf("<div class=\"st-slider-container\" data-widget-id=\"%s\"%s><label>%s</label><div class=\"st-slider-value\">%s</div><input type=\"range\" class=\"st-slider\" data-widget-id=\"%s\" data-event-type=\"change\"%s value=\"%s\">%s</div>",
+		w.GetID(), labelsAttr, w.label, w.formatValue(w.GetValue()),
+		w.GetID(), rangeAttrs, formatPosition(w.position), w.renderBounds()).String()
+}
+
+// Clone 复制滑块组件
+func (w *SliderWidget[T]) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetValue 设置滑块的值，值按刻度对齐并限制在范围内
+func (w *SliderWidget[T]) SetValue(session ISession, value T) {
+	w.position = w.snap(w.scale.Position(value))
+	w.TriggerCallbacks(session, "change", formatPosition(w.position))
+}
+
+// BindValue 将客户端发送的位置转换为值并保存
+func (w *SliderWidget[T]) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	position, err := w.parsePosition(value)
+	if err != nil {
+		return fmt.Errorf("slider %s: %w", w.GetID(), err)
+	}
+	w.position = position
+	return nil
+}
+
+// Reset 恢复创建时的值，限制在当前刻度的范围内
+func (w *SliderWidget[T]) Reset() {
+	w.position = w.snap(w.initial)
+}
+
+// GetValue 获取滑块的值
+func (w *SliderWidget[T]) GetValue() T {
+	return w.scale.Value(w.position)
+}
+
+// SetScale 更换滑块刻度，当前值按新刻度对齐
+func (w *SliderWidget[T]) SetScale(scale IScale[T]) {
+	value := w.GetValue()
+	w.scale = scale
+	w.position = w.snap(scale.Position(value))
+}
+
+// RangeSliderWidget 范围滑块组件，拖动任一端结束时发送change事件，值为逗号分隔的两个位置
+type RangeSliderWidget[T any] struct {
+	*BaseWidget
+	sliderBase[T]
+	low     float64    // 范围下端的位置
+	high    float64    // 范围上端的位置
+	initial [2]float64 // 创建时两端的位置，Reset时恢复
+}
+
+// NewRangeSlider 创建数值范围滑块组件
+func NewRangeSlider[T Number](label string, min, max, low, high T) *RangeSliderWidget[T] {
+	return NewScaleRangeSlider[T](label, NewNumberScale(min, max, 0), low, high)
+}
+
+// NewTimeRangeSlider 创建时间范围滑块组件，step不大于0时使用一天
+func NewTimeRangeSlider(label string, min, max, low, high time.Time, step time.Duration) *RangeSliderWidget[time.Time] {
+	return NewScaleRangeSlider[time.Time](label, NewTimeScale(min, max, step), low, high)
+}
+
+// NewOptionRangeSlider 创建离散选项范围滑块组件，low和high为默认选项的索引
+func NewOptionRangeSlider[T any](label string, options []T, low, high int) *RangeSliderWidget[T] {
+	var zero T
+	w := NewScaleRangeSlider[T](label, NewOptionScale(options), zero, zero)
+	w.setPositions(float64(low), float64(high))
+	w.initial = [2]float64{w.low, w.high}
+	return w
+}
+
+// NewScaleRangeSlider 创建使用指定刻度的范围滑块组件
+func NewScaleRangeSlider[T any](label string, scale IScale[T], low, high T) *RangeSliderWidget[T] {
+	w := &RangeSliderWidget[T]{
+		BaseWidget: NewBaseWidget("range_slider"),
+		sliderBase: sliderBase[T]{label: label, scale: scale},
+	}
+	w.setPositions(scale.Position(low), scale.Position(high))
+	w.initial = [2]float64{w.low, w.high}
+	return w
+}
+
+// setPositions 按刻度对齐并保存范围两端的位置，保证下端不大于上端
+func (w *RangeSliderWidget[T]) setPositions(low, high float64) {
+	low, high = w.snap(low), w.snap(high)
+	if low > high {
+		low, high = high, low
+	}
+	w.low, w.high = low, high
+}
+
+// Render 渲染范围滑块组件为HTML
+func (w *RangeSliderWidget[T]) Render() string {
+	rangeAttrs, labelsAttr := w.renderAttrs()
+	return HTMLf("<div class=\"st-slider-container\" data-widget-id=\"%s\"%s><label>%s</label><div class=\"st-slider-value\">%s – %s</div><div class=\"st-range-slider\" data-widget-id=\"%s\" data-event-type=\"change\"><input type=\"range\" class=\"st-slider\"%s value=\"%s\"><input type=\"range\" class=\"st-slider\"%s value=\"%s\"></div>%s</div>",
+		w.GetID(), labelsAttr, w.label,
+		w.formatValue(w.scale.Value(w.low)), w.formatValue(w.scale.Value(w.high)),
+		w.GetID(), rangeAttrs, formatPosition(w.low), rangeAttrs, formatPosition(w.high),
+		w.renderBounds()).String()
+}
+
+// Clone 复制范围滑块组件
+func (w *RangeSliderWidget[T]) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
+}
+
+// SetValue 设置范围，两端按刻度对齐并限制在范围内
+func (w *RangeSliderWidget[T]) SetValue(session ISession, low, high T) {
+	w.setPositions(w.scale.Position(low), w.scale.Position(high))
+	w.TriggerCallbacks(session, "change", formatPosition(w.low)+","+formatPosition(w.high))
+}
+
+// BindValue 将客户端发送的两个位置转换为范围并保存
+func (w *RangeSliderWidget[T]) BindValue(session ISession, event string, value string) error {
+	if event != "change" {
+		return nil
+	}
+
+	lowText, highText, ok := strings.Cut(value, ",")
+	if !ok {
+		return fmt.Errorf("range slider %s: invalid range %q", w.GetID(), value)
+	}
+	low, err := w.parsePosition(lowText)
+	if err != nil {
+		return fmt.Errorf("range slider %s: %w", w.GetID(), err)
+	}
+	high, err := w.parsePosition(highText)
+	if err != nil {
+		return fmt.Errorf("range slider %s: %w", w.GetID(), err)
+	}
+	w.setPositions(low, high)
+	return nil
+}
+
+// Reset 恢复创建时的范围，限制在当前刻度的范围内
+func (w *RangeSliderWidget[T]) Reset() {
+	w.setPositions(w.initial[0], w.initial[1])
+}
+
+// GetValue 获取范围的两端
+func (w *RangeSliderWidget[T]) GetValue() (T, T) {
+	return w.scale.Value(w.low), w.scale.Value(w.high)
+}
+
+// SetScale 更换滑块刻度，当前范围按新刻度对齐
+func (w *RangeSliderWidget[T]) SetScale(scale IScale[T]) {
+	low, high := w.GetValue()
+	w.scale = scale
+	w.setPositions(scale.Position(low), scale.Position(high))
+}
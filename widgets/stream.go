@@ -0,0 +1,64 @@
+package widgets
+
+import "sync"
+
+// IStreamer 在后台持续更新的组件接口
+//
+// 服务在渲染时记录每个会话显示的数据流和该会话中的组件实例。组件内容变化后调用Stream.Publish：
+// 带有增量更新时，服务把更新原样发送给最近一次渲染中包含该组件的会话，由页面脚本在组件的元素中执行；
+// 没有增量更新时，服务为这些会话重新渲染这一个组件，通过patch消息原地替换。两种方式都不重新运行应用。
+// Stream返回nil表示组件当前不推送更新。
+type IStreamer interface {
+	Stream() *Stream
+}
+
+// StreamUpdate 组件的增量更新
+//
+// 组件渲染的元素带有data-version属性。页面只在属性等于From时依次执行Ops并把属性设为To，
+// 否则说明错过了更新，页面发送resync视图事件，由服务重新渲染该组件。
+type StreamUpdate struct {
+	From uint64     `json:"from"`
+	To   uint64     `json:"to"`
+	Ops  []StreamOp `json:"ops"`
+}
+
+// StreamOp 增量更新中对组件元素内一个节点的操作，按HTML、Attrs、Drop、Append的顺序执行
+type StreamOp struct {
+	Target string            `json:"target,omitempty"` // 组件元素内的CSS选择器，为空表示组件元素本身
+	HTML   string            `json:"html,omitempty"`   // 替换节点的内容
+	Attrs  map[string]string `json:"attrs,omitempty"`  // 设置节点的属性
+	Drop   int               `json:"drop,omitempty"`   // 删除节点的前Drop个子元素
+	Append string            `json:"append,omitempty"` // 在节点末尾插入的内容
+}
+
+// Stream 组件的更新通知，由组件的所有会话副本共享
+type Stream struct {
+	mutex     sync.RWMutex
+	publisher func(stream *Stream, update *StreamUpdate)
+}
+
+// NewStream 创建数据流
+func NewStream() *Stream {
+	return &Stream{}
+}
+
+// Attach 设置发布函数，由服务在会话首次显示组件时调用
+func (s *Stream) Attach(publisher func(stream *Stream, update *StreamUpdate)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.publisher = publisher
+}
+
+// Publish 通知服务组件的内容已变化，组件还没有显示过时忽略
+//
+// update为nil时服务在Publish中调用组件的Render，调用时不能持有Render需要的锁。
+func (s *Stream) Publish(update *StreamUpdate) {
+	s.mutex.RLock()
+	publisher := s.publisher
+	s.mutex.RUnlock()
+
+	if publisher != nil {
+		publisher(s, update)
+	}
+}
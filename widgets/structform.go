@@ -0,0 +1,485 @@
+package widgets
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// timeType time.Time的反射类型
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField 结构体字段与表单输入组件的对应关系
+type structField struct {
+	index    int    // 字段在结构体中的索引
+	name     string // 字段名
+	label    string
+	id       string // 输入组件ID
+	required bool
+	min, max *float64  // 数值范围，字符串为长度范围
+	minDate  time.Time // 日期范围，零值表示不限制
+	maxDate  time.Time
+	options  []string // 可选值，非空时使用下拉选择
+}
+
+// structBinding 表单与结构体的绑定，由表单的所有会话副本共享
+type structBinding struct {
+	mutex  sync.Mutex
+	target reflect.Value // 结构体值（可寻址）
+	fields []*structField
+}
+
+// StructFormWidget 结构体表单组件
+//
+// 根据结构体字段和st标签生成表单：字符串对应文本输入框，数值对应数字输入框，
+// bool对应复选框，time.Time对应日期输入框，设置了options的字段对应下拉选择。
+// 提交时先校验所有提交的字段，全部通过后只把提交的字段写回结构体，再调用OnSubmit回调。
+// 复制、写回和渲染时在锁内从结构体重新读取字段值，显示其他会话或goroutine写入的最新值；
+// 最近一次提交未能写回时保留用户的输入，以便修改后重新提交。
+//
+// 标签格式为逗号分隔的选项，例如：
+//
+//	Name  string  `st:"label=姓名,required,max=20"`
+//	Age   int     `st:"label=年龄,min=0,max=150"`
+//	Level string  `st:"label=级别,options=低|中|高"`
+//	Skip  string  `st:"-"`
+//
+// 同一结构体被所有会话共享，写回时加锁；在其他goroutine中读取结构体时应使用Lock/Unlock与提交互斥。
+type StructFormWidget struct {
+	*FormWidget
+	binding *structBinding
+	pending bool // 最近一次提交未写回结构体，渲染时保留提交的输入
+}
+
+// NewStructForm 根据结构体指针创建表单组件
+//
+// target不是非nil的结构体指针、字段类型不受支持或标签无效时返回错误。
+func NewStructForm(target interface{}) (*StructFormWidget, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct form: target must be a non-nil pointer to struct, got %T", target)
+	}
+
+	w := &StructFormWidget{
+		FormWidget: NewForm(true),
+		binding:    &structBinding{target: value.Elem()},
+	}
+	w.widgetType = "struct_form"
+
+	t := value.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("st")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		field, err := parseStructTag(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		field.index = i
+
+		input, err := newStructFieldInput(field, sf.Type, value.Elem().Field(i))
+		if err != nil {
+			return nil, err
+		}
+		w.AddChild(input)
+		field.id = input.GetID()
+		w.binding.fields = append(w.binding.fields, field)
+	}
+
+	w.AddChild(NewFormSubmitButton("提交"))
+	w.beforeSubmit = w.binding.apply
+	w.binding.refresh(w.children)
+	return w, nil
+}
+
+// parseStructTag 解析字段的st标签
+func parseStructTag(sf reflect.StructField, tag string) (*structField, error) {
+	field := &structField{name: sf.Name, label: sf.Name}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "label":
+			field.label = value
+		case "required":
+			field.required = true
+		case "options":
+			field.options = strings.Split(value, "|")
+		case "format", "width", "hidden":
+			// 表格的列设置，表单忽略
+			continue
+		case "min", "max":
+			if sf.Type == timeType {
+				date, err := time.ParseInLocation(dateLayout, value, time.Local)
+				if err != nil {
+					return nil, fmt.Errorf("struct form: field %s: invalid %s date %q", sf.Name, key, value)
+				}
+				if key == "min" {
+					field.minDate = date
+				} else {
+					field.maxDate = date
+				}
+				continue
+			}
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("struct form: field %s: invalid %s %q", sf.Name, key, value)
+			}
+			if key == "min" {
+				field.min = &number
+			} else {
+				field.max = &number
+			}
+		default:
+			return nil, fmt.Errorf("struct form: field %s: unknown tag option %q", sf.Name, key)
+		}
+	}
+	return field, nil
+}
+
+// newStructFieldInput 根据字段类型创建输入组件
+func newStructFieldInput(field *structField, t reflect.Type, value reflect.Value) (Widget, error) {
+	if len(field.options) > 0 {
+		current := fmt.Sprint(value.Interface())
+		index := -1
+		for i, option := range field.options {
+			if option == current {
+				index = i
+			}
+			// 数值字段的可选值必须能转换为字段类型
+			if _, err := convertOption(option, t); err != nil {
+				return nil, fmt.Errorf("struct form: field %s: %w", field.name, err)
+			}
+		}
+		return NewSelectbox(field.label, field.options, index), nil
+	}
+
+	if t == timeType {
+		return NewDateInput(field.label, value.Interface().(time.Time)), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return NewTextInput(field.label, value.String()), nil
+	case reflect.Bool:
+		return NewCheckbox(field.label, value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewNumberInput(field.label, float64(value.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NewNumberInput(field.label, float64(value.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		input := NewNumberInput(field.label, value.Float())
+		input.SetStep(0.01)
+		return input, nil
+	}
+	return nil, fmt.Errorf("struct form: field %s: unsupported type %s", field.name, t)
+}
+
+// convertOption 将可选值转换为字段类型的值
+func convertOption(option string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(option)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(option, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("invalid option %q", option)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(option, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("invalid option %q", option)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(option, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("invalid option %q", option)
+		}
+		v.SetFloat(n)
+	default:
+		return v, fmt.Errorf("options are not supported for type %s", t)
+	}
+	return v, nil
+}
+
+// refresh 在锁内从结构体读取各字段的值，设置到表单实例的输入组件
+func (b *structBinding) refresh(children []Widget) {
+	index := NewIndex(children)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, field := range b.fields {
+		if input, exists := index.Lookup(field.id); exists {
+			field.load(input, b.target.Field(field.index))
+		}
+	}
+}
+
+// load 将字段的值设置到输入组件，不触发回调
+func (f *structField) load(input Widget, value reflect.Value) {
+	switch w := input.(type) {
+	case *SelectboxWidget[string]:
+		w.index = -1
+		current := fmt.Sprint(value.Interface())
+		for i, option := range f.options {
+			if option == current {
+				w.index = i
+			}
+		}
+	case *DateInputWidget:
+		w.value = value.Interface().(time.Time)
+		w.err = ""
+	case *TextInputWidget:
+		w.value = value.String()
+	case *CheckboxWidget:
+		w.value = value.Bool()
+	case *NumberInputWidget:
+		// 整数按精确的文本显示，超过2^53的值转换为float64会丢失精度
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			w.value = float64(value.Int())
+			w.text = strconv.FormatInt(value.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			w.value = float64(value.Uint())
+			w.text = strconv.FormatUint(value.Uint(), 10)
+		default:
+			w.value = value.Float()
+			w.text = ""
+		}
+		w.err = ""
+	}
+}
+
+// apply 校验表单实例中提交的字段，全部通过后只把提交的字段写回结构体
+func (b *structBinding) apply(form *FormWidget, session ISession, values FormValues) error {
+	index := NewIndex(form.children)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	converted := make([]reflect.Value, len(b.fields))
+	var errs []error
+	for i, field := range b.fields {
+		raw, submitted := values[field.id]
+		if !submitted {
+			// 客户端没有提交的字段保持结构体中的值
+			continue
+		}
+		input, _ := index.Lookup(field.id)
+		value, err := field.convert(input, raw, b.target.Field(field.index))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.label, err))
+			continue
+		}
+		converted[i] = value
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for i, field := range b.fields {
+		if converted[i].IsValid() {
+			b.target.Field(field.index).Set(converted[i])
+		}
+	}
+	return nil
+}
+
+// convert 校验输入组件的值并转换为字段类型，raw为客户端提交的原始值
+func (f *structField) convert(input Widget, raw string, current reflect.Value) (reflect.Value, error) {
+	t := current.Type()
+
+	switch w := input.(type) {
+	case *SelectboxWidget[string]:
+		option, ok := w.GetValue()
+		if !ok {
+			if f.required {
+				return reflect.Value{}, errors.New("请选择一项")
+			}
+			return current, nil
+		}
+		return convertOption(option, t)
+
+	case *DateInputWidget:
+		date := w.GetValue()
+		if date.IsZero() {
+			if f.required {
+				return reflect.Value{}, errors.New("不能为空")
+			}
+			return reflect.ValueOf(date), nil
+		}
+		if old := current.Interface().(time.Time); !old.IsZero() {
+			// 保持字段原有的时区
+			date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, old.Location())
+		}
+		if !f.minDate.IsZero() && date.Before(f.minDate) {
+			return reflect.Value{}, fmt.Errorf("不能早于 %s", f.minDate.Format(dateLayout))
+		}
+		if !f.maxDate.IsZero() && date.After(f.maxDate) {
+			return reflect.Value{}, fmt.Errorf("不能晚于 %s", f.maxDate.Format(dateLayout))
+		}
+		return reflect.ValueOf(date), nil
+
+	case *TextInputWidget:
+		text := w.GetValue()
+		if f.required && strings.TrimSpace(text) == "" {
+			return reflect.Value{}, errors.New("不能为空")
+		}
+		length := float64(utf8.RuneCountInString(text))
+		if f.min != nil && length < *f.min {
+			return reflect.Value{}, fmt.Errorf("长度不能少于 %g", *f.min)
+		}
+		if f.max != nil && length > *f.max {
+			return reflect.Value{}, fmt.Errorf("长度不能超过 %g", *f.max)
+		}
+		v := reflect.New(t).Elem()
+		v.SetString(text)
+		return v, nil
+
+	case *CheckboxWidget:
+		v := reflect.New(t).Elem()
+		v.SetBool(w.GetValue())
+		return v, nil
+
+	case *NumberInputWidget:
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			if f.required {
+				return reflect.Value{}, errors.New("不能为空")
+			}
+			// 与数字输入框一致，清空输入时保持原值
+			return current, nil
+		}
+		return f.convertNumber(raw, t)
+	}
+	return reflect.Value{}, errors.New("字段组件不存在")
+}
+
+// convertNumber 解析数值并校验范围，整数字段直接按字段类型解析，不经过float64
+func (f *structField) convertNumber(raw string, t reflect.Type) (reflect.Value, error) {
+	number, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return reflect.Value{}, fmt.Errorf("无效的数字: %s", raw)
+	}
+	if f.min != nil && number < *f.min {
+		return reflect.Value{}, fmt.Errorf("不能小于 %g", *f.min)
+	}
+	if f.max != nil && number > *f.max {
+		return reflect.Value{}, fmt.Errorf("不能大于 %g", *f.max)
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, integerError(err, number)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, integerError(err, number)
+		}
+		v.SetUint(n)
+	default:
+		if v.OverflowFloat(number) {
+			return reflect.Value{}, errors.New("超出取值范围")
+		}
+		v.SetFloat(number)
+	}
+	return v, nil
+}
+
+// integerError 将整数解析错误转换为提示信息
+func integerError(err error, number float64) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return errors.New("超出取值范围")
+	}
+	if number != math.Trunc(number) {
+		return errors.New("必须是整数")
+	}
+	if number < 0 {
+		// 无符号整数字段的负数
+		return errors.New("超出取值范围")
+	}
+	return errors.New("必须是整数")
+}
+
+// Clone 复制结构体表单组件，副本与原组件写回同一个结构体，字段值从结构体重新读取
+func (w *StructFormWidget) Clone() Widget {
+	c := &StructFormWidget{
+		FormWidget: w.FormWidget.Clone().(*FormWidget),
+		binding:    w.binding,
+	}
+	c.binding.refresh(c.children)
+	return c
+}
+
+// Render 渲染结构体表单组件为HTML，不修改组件
+//
+// 最近一次提交已写回时，把结构体的字段值读取到输入组件的副本中渲染，全局表单可以被多个会话同时渲染。
+func (w *StructFormWidget) Render() string {
+	if w.pending {
+		return w.FormWidget.Render()
+	}
+	snapshot := *w.FormWidget
+	snapshot.children = cloneChildren(w.children)
+	w.binding.refresh(snapshot.children)
+	return snapshot.Render()
+}
+
+// BindValue 绑定提交的字段值，写回结构体前渲染保留这些值
+func (w *StructFormWidget) BindValue(session ISession, event string, value string) error {
+	if event == "submit" {
+		w.pending = true
+	}
+	return w.FormWidget.BindValue(session, event, value)
+}
+
+// TriggerCallbacks 校验并写回提交的字段后调用回调，写回失败时保留提交的值
+func (w *StructFormWidget) TriggerCallbacks(session ISession, event string, value string) {
+	w.FormWidget.TriggerCallbacks(session, event, value)
+	if event == "submit" {
+		w.pending = w.err != ""
+		if !w.pending {
+			w.binding.refresh(w.children)
+		}
+	}
+}
+
+// SetSubmitLabel 设置提交按钮的文本
+func (w *StructFormWidget) SetSubmitLabel(label string) {
+	for _, child := range w.children {
+		if button, ok := child.(*FormSubmitButtonWidget); ok {
+			button.label = label
+		}
+	}
+}
+
+// Lock 锁定绑定的结构体，防止读取时其他会话的提交同时写回
+func (w *StructFormWidget) Lock() {
+	w.binding.mutex.Lock()
+}
+
+// Unlock 解除对结构体的锁定
+func (w *StructFormWidget) Unlock() {
+	w.binding.mutex.Unlock()
+}
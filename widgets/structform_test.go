@@ -0,0 +1,219 @@
+package widgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// submitStructForm 按客户端的格式提交表单字段，fields为组件ID -> 值
+func submitStructForm(t *testing.T, form *StructFormWidget, fields map[string]string) {
+	t.Helper()
+	submission := formSubmission{}
+	for id, value := range fields {
+		submission.Fields = append(submission.Fields, formField{ID: id, Event: "input", Value: value})
+	}
+	data, err := json.Marshal(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := form.BindValue(nil, "submit", string(data)); err != nil {
+		t.Fatalf("bind submission: %v", err)
+	}
+	form.TriggerCallbacks(nil, "submit", string(data))
+}
+
+// structFieldID 获取字段对应的输入组件ID
+func structFieldID(form *StructFormWidget, name string) string {
+	for _, field := range form.binding.fields {
+		if field.name == name {
+			return field.id
+		}
+	}
+	return ""
+}
+
+func TestStructFormLargeIntegers(t *testing.T) {
+	target := &struct {
+		Signed   int64  `st:"label=有符号"`
+		Unsigned uint64 `st:"label=无符号"`
+	}{}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submitStructForm(t, form, map[string]string{
+		structFieldID(form, "Signed"):   "9007199254740993",
+		structFieldID(form, "Unsigned"): "18446744073709551615",
+	})
+	if form.GetError() != "" {
+		t.Fatalf("unexpected error: %s", form.GetError())
+	}
+	if target.Signed != 9007199254740993 {
+		t.Errorf("Signed = %d, want 9007199254740993", target.Signed)
+	}
+	if target.Unsigned != 18446744073709551615 {
+		t.Errorf("Unsigned = %d, want 18446744073709551615", target.Unsigned)
+	}
+	// 渲染显示精确的整数，再次提交不丢失精度
+	if html := form.Render(); !strings.Contains(html, `value="9007199254740993"`) {
+		t.Errorf("render does not contain exact value: %s", html)
+	}
+}
+
+func TestStructFormIntegerErrors(t *testing.T) {
+	target := &struct {
+		Small    int8 `st:"label=小整数"`
+		Unsigned uint `st:"label=无符号"`
+	}{Small: 1, Unsigned: 1}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field, value, want string
+	}{
+		{"Small", "1.5", "必须是整数"},
+		{"Small", "200", "超出取值范围"},
+		{"Unsigned", "-1", "超出取值范围"},
+	}
+	for _, tt := range tests {
+		submitStructForm(t, form, map[string]string{structFieldID(form, tt.field): tt.value})
+		if !strings.Contains(form.GetError(), tt.want) {
+			t.Errorf("%s=%s: error %q, want %q", tt.field, tt.value, form.GetError(), tt.want)
+		}
+	}
+	if target.Small != 1 || target.Unsigned != 1 {
+		t.Errorf("invalid submissions were written back: %+v", *target)
+	}
+}
+
+func TestStructFormRequiredNumber(t *testing.T) {
+	target := &struct {
+		Age   int     `st:"label=年龄,required"`
+		Score float64 `st:"label=分数"`
+	}{Age: 18, Score: 1.5}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submitStructForm(t, form, map[string]string{
+		structFieldID(form, "Age"):   " ",
+		structFieldID(form, "Score"): "",
+	})
+	if !strings.Contains(form.GetError(), "年龄: 不能为空") {
+		t.Fatalf("expected required error, got %q", form.GetError())
+	}
+	if strings.Contains(form.GetError(), "分数") {
+		t.Fatalf("optional empty number rejected: %q", form.GetError())
+	}
+	if target.Age != 18 {
+		t.Errorf("Age = %d, want 18", target.Age)
+	}
+}
+
+func TestStructFormWritesOnlySubmittedFields(t *testing.T) {
+	target := &struct {
+		Name string `st:"label=姓名"`
+		Age  int    `st:"label=年龄"`
+	}{Name: "张三", Age: 18}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := form.Clone().(*StructFormWidget)
+
+	// 其他会话在本会话渲染表单之后修改了Name
+	form.Lock()
+	target.Name = "李四"
+	form.Unlock()
+
+	submitStructForm(t, session, map[string]string{structFieldID(form, "Age"): "20"})
+	if session.GetError() != "" {
+		t.Fatalf("unexpected error: %s", session.GetError())
+	}
+	if target.Name != "李四" || target.Age != 20 {
+		t.Errorf("got %+v, want Name=李四 Age=20", *target)
+	}
+}
+
+func TestStructFormRereadsStruct(t *testing.T) {
+	target := &struct {
+		Name string `st:"label=姓名"`
+	}{Name: "张三"}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form.Lock()
+	target.Name = "李四"
+	form.Unlock()
+
+	if html := form.Clone().Render(); !strings.Contains(html, `value="李四"`) {
+		t.Errorf("clone does not show the current value: %s", html)
+	}
+	if html := form.Render(); !strings.Contains(html, `value="李四"`) {
+		t.Errorf("render does not show the current value: %s", html)
+	}
+}
+
+func TestStructFormKeepsInputAfterFailedSubmit(t *testing.T) {
+	target := &struct {
+		Age int `st:"label=年龄,max=150"`
+	}{Age: 18}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submitStructForm(t, form, map[string]string{structFieldID(form, "Age"): "200"})
+	if form.GetError() == "" {
+		t.Fatal("expected range error")
+	}
+	if html := form.Render(); !strings.Contains(html, `value="200"`) {
+		t.Errorf("render discarded the rejected input: %s", html)
+	}
+}
+
+// TestStructFormRenderReadOnly 渲染不修改表单，全局表单可以被多个会话同时渲染
+func TestStructFormRenderReadOnly(t *testing.T) {
+	target := &struct {
+		Name string `st:"label=姓名"`
+	}{Name: "张三"}
+	form, err := NewStructForm(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := form.children[0].(*TextInputWidget)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i == 0 {
+					form.Lock()
+					target.Name = fmt.Sprintf("用户%d", j)
+					form.Unlock()
+				} else {
+					form.Render()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if input.GetValue() != "张三" {
+		t.Errorf("render modified the input: %q", input.GetValue())
+	}
+	if html := form.Render(); !strings.Contains(html, `value="用户49"`) {
+		t.Errorf("render does not show the current value: %s", html)
+	}
+}
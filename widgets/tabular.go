@@ -0,0 +1,463 @@
+package widgets
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableColumn 表格列的显示设置
+//
+// 结构体切片的列设置可以写在字段的st标签中，例如：
+//
+//	Price float64   `st:"label=价格,format=%.2f,width=100"`
+//	Date  time.Time `st:"label=日期,format=2006-01-02"`
+//	Note  string    `st:"hidden"`
+//	Skip  string    `st:"-"`
+//
+// 与StructForm共用st标签时，表格和表单各自忽略对方的选项。
+type TableColumn struct {
+	Name   string // 数据中的列名：结构体字段名、map的键或Rows的表头
+	Label  string // 表头显示的名称，为空时使用Name
+	Format string // fmt格式，如"%.2f"；time.Time使用时间布局，如"2006-01-02"
+	Width  int    // 列宽（像素），0表示自动
+	Hidden bool
+}
+
+// Rows 带表头的二维表格数据，Data是任意元素类型的二维切片，如[][]string或[][]interface{}
+//
+// 每行的值按位置与Headers对应，超出Headers的列以序号（从0开始）命名，较短的行缺少的值显示为空。
+type Rows struct {
+	Headers []string
+	Data    interface{}
+}
+
+// tableData 按列读取的表格数据
+type tableData struct {
+	columns []TableColumn   // 数据中的列，结构体的列带有st标签中的设置
+	rows    [][]interface{} // 每行的值按columns的顺序排列
+	records []interface{}   // 每行对应的原始元素，如结构体切片的元素；单个map或结构体为nil
+	header  bool            // 是否显示表头，map、单个结构体和没有表头的二维切片不显示
+}
+
+// record 获取第i行的原始元素，没有原始元素时返回该行的值
+func (t *tableData) record(i int) interface{} {
+	if t.records != nil {
+		return t.records[i]
+	}
+	return t.rows[i]
+}
+
+// readTableData 读取表格数据
+//
+// 支持结构体切片（元素可以是结构体指针）、键为字符串的map切片（列按名称排序）、Rows、
+// 二维切片（列以序号命名）和标量切片（单列）。单个map或结构体显示为键值两列。
+func readTableData(data interface{}) (*tableData, error) {
+	switch d := data.(type) {
+	case nil:
+		return &tableData{}, nil
+	case Rows:
+		return readGrid(d.Headers, d.Data)
+	case *Rows:
+		if d == nil {
+			return &tableData{}, nil
+		}
+		return readGrid(d.Headers, d.Data)
+	}
+
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return &tableData{}, nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		return readKeyValues(value)
+	case reflect.Struct:
+		if value.Type() != timeType {
+			return readFields(value)
+		}
+	case reflect.Slice, reflect.Array:
+		elem := value.Type().Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch {
+		case elem.Kind() == reflect.Struct && elem != timeType:
+			return readStructs(value, elem)
+		case elem.Kind() == reflect.Map && elem.Key().Kind() == reflect.String:
+			return readMaps(value)
+		case elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array:
+			return readGrid(nil, value.Interface())
+		case elem.Kind() != reflect.Map && elem.Kind() != reflect.Func && elem.Kind() != reflect.Chan:
+			t := &tableData{columns: []TableColumn{{Name: "value"}}}
+			for i := 0; i < value.Len(); i++ {
+				t.rows = append(t.rows, []interface{}{value.Index(i).Interface()})
+				t.records = append(t.records, value.Index(i).Interface())
+			}
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("table: unsupported data type %T", data)
+}
+
+// readStructs 读取结构体切片，每个导出字段为一列
+func readStructs(value reflect.Value, elem reflect.Type) (*tableData, error) {
+	t := &tableData{header: true}
+	var fields []int
+	for i := 0; i < elem.NumField(); i++ {
+		sf := elem.Field(i)
+		tag := sf.Tag.Get("st")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		column, err := parseColumnTag(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, i)
+		t.columns = append(t.columns, column)
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		row := value.Index(i)
+		for row.Kind() == reflect.Ptr && !row.IsNil() {
+			row = row.Elem()
+		}
+		if row.Kind() == reflect.Ptr {
+			continue
+		}
+		values := make([]interface{}, len(fields))
+		for j, field := range fields {
+			values[j] = row.Field(field).Interface()
+		}
+		t.rows = append(t.rows, values)
+		t.records = append(t.records, value.Index(i).Interface())
+	}
+	return t, nil
+}
+
+// parseColumnTag 解析字段st标签中的列设置，忽略表单的选项
+func parseColumnTag(sf reflect.StructField, tag string) (TableColumn, error) {
+	column := TableColumn{Name: sf.Name}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "label":
+			column.Label = value
+		case "format":
+			column.Format = value
+		case "width":
+			width, err := strconv.Atoi(value)
+			if err != nil || width < 0 {
+				return column, fmt.Errorf("table: field %s: invalid width %q", sf.Name, value)
+			}
+			column.Width = width
+		case "hidden":
+			column.Hidden = true
+		}
+	}
+	return column, nil
+}
+
+// readMaps 读取map切片，所有行中出现的键为列，按名称排序
+func readMaps(value reflect.Value) (*tableData, error) {
+	t := &tableData{header: true}
+	seen := make(map[string]bool)
+	var names []string
+	rows := make([]reflect.Value, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		row := value.Index(i)
+		for row.Kind() == reflect.Ptr && !row.IsNil() {
+			row = row.Elem()
+		}
+		if row.Kind() == reflect.Ptr || row.IsNil() {
+			continue
+		}
+		rows = append(rows, row)
+		t.records = append(t.records, value.Index(i).Interface())
+		for _, key := range row.MapKeys() {
+			if name := key.String(); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.columns = append(t.columns, TableColumn{Name: name})
+	}
+	for _, row := range rows {
+		values := make([]interface{}, len(names))
+		for j, name := range names {
+			if v := row.MapIndex(reflect.ValueOf(name).Convert(row.Type().Key())); v.IsValid() {
+				values[j] = v.Interface()
+			}
+		}
+		t.rows = append(t.rows, values)
+	}
+	return t, nil
+}
+
+// readGrid 读取二维切片，headers为nil时不显示表头
+func readGrid(headers []string, data interface{}) (*tableData, error) {
+	t := &tableData{header: headers != nil}
+	value := reflect.ValueOf(data)
+	if data != nil && value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("table: unsupported rows type %T", data)
+	}
+
+	width := len(headers)
+	if data != nil {
+		for i := 0; i < value.Len(); i++ {
+			row := value.Index(i)
+			for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+				if row.IsNil() {
+					break
+				}
+				row = row.Elem()
+			}
+			if row.Kind() != reflect.Slice && row.Kind() != reflect.Array {
+				return nil, fmt.Errorf("table: row %d: unsupported type %s", i, row.Type())
+			}
+			values := make([]interface{}, row.Len())
+			for j := range values {
+				values[j] = row.Index(j).Interface()
+			}
+			width = max(width, len(values))
+			t.rows = append(t.rows, values)
+			t.records = append(t.records, value.Index(i).Interface())
+		}
+	}
+
+	for i := 0; i < width; i++ {
+		name := strconv.Itoa(i)
+		if i < len(headers) {
+			name = headers[i]
+		}
+		t.columns = append(t.columns, TableColumn{Name: name})
+	}
+	for i, row := range t.rows {
+		if len(row) < width {
+			t.rows[i] = append(row, make([]interface{}, width-len(row))...)
+		}
+	}
+	return t, nil
+}
+
+// readKeyValues 读取单个map，每个键一行，按键排序
+func readKeyValues(value reflect.Value) (*tableData, error) {
+	t := &tableData{columns: []TableColumn{{Name: "key"}, {Name: "value"}}}
+	keys := value.MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = fmt.Sprint(key.Interface())
+	}
+	sort.Sort(keyOrder{keys, names})
+	for i, key := range keys {
+		t.rows = append(t.rows, []interface{}{names[i], value.MapIndex(key).Interface()})
+	}
+	return t, nil
+}
+
+// keyOrder 按显示文本排序map的键
+type keyOrder struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (o keyOrder) Len() int           { return len(o.keys) }
+func (o keyOrder) Less(i, j int) bool { return o.names[i] < o.names[j] }
+func (o keyOrder) Swap(i, j int) {
+	o.keys[i], o.keys[j] = o.keys[j], o.keys[i]
+	o.names[i], o.names[j] = o.names[j], o.names[i]
+}
+
+// readFields 读取单个结构体，每个导出字段一行，值按字段的format格式化
+func readFields(value reflect.Value) (*tableData, error) {
+	t := &tableData{columns: []TableColumn{{Name: "key"}, {Name: "value"}}}
+	for i := 0; i < value.NumField(); i++ {
+		sf := value.Type().Field(i)
+		tag := sf.Tag.Get("st")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		column, err := parseColumnTag(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		if column.Hidden {
+			continue
+		}
+		cell := value.Field(i).Interface()
+		if column.Format != "" {
+			cell = formatCell(cell, column.Format)
+		}
+		t.rows = append(t.rows, []interface{}{column.label(), cell})
+	}
+	return t, nil
+}
+
+// label 获取表头显示的名称
+func (c TableColumn) label() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.Name
+}
+
+// tabular 表格和数据框共用的数据和列设置
+type tabular struct {
+	data    *tableData
+	err     error         // 数据类型不受支持时的错误，渲染时显示
+	columns []TableColumn // SetColumns设置的列
+}
+
+// SetData 设置表格数据，替换已有数据，支持的类型见NewTable
+func (t *tabular) SetData(data interface{}) {
+	t.data, t.err = readTableData(data)
+}
+
+// SetColumns 设置列的显示方式，按Name匹配数据中的列，替换该列在st标签中的设置
+//
+// 设置的列按给出的顺序排在前面，其余列保持数据中的顺序；数据中不存在的列被忽略。
+func (t *tabular) SetColumns(columns ...TableColumn) {
+	t.columns = append([]TableColumn(nil), columns...)
+}
+
+// shownColumn 显示的列
+type shownColumn struct {
+	TableColumn
+	index int // 值在行中的位置
+}
+
+// visibleColumns 合并数据中的列和SetColumns的设置，返回要显示的列
+func (t *tabular) visibleColumns() []shownColumn {
+	if t.data == nil {
+		return nil
+	}
+	positions := make(map[string]int, len(t.data.columns))
+	for i, column := range t.data.columns {
+		if _, exists := positions[column.Name]; !exists {
+			positions[column.Name] = i
+		}
+	}
+
+	used := make([]bool, len(t.data.columns))
+	var result []shownColumn
+	for _, column := range t.columns {
+		index, exists := positions[column.Name]
+		if !exists || used[index] {
+			continue
+		}
+		used[index] = true
+		if !column.Hidden {
+			result = append(result, shownColumn{TableColumn: column, index: index})
+		}
+	}
+	for index, column := range t.data.columns {
+		if !used[index] && !column.Hidden {
+			result = append(result, shownColumn{TableColumn: column, index: index})
+		}
+	}
+	return result
+}
+
+// renderTable 渲染表格，attrs是table元素的属性
+func (t *tabular) renderTable(attrs SafeHTML, columns []shownColumn, rows [][]interface{}) SafeHTML {
+	var b strings.Builder
+	b.WriteString(HTMLf("<table%s>", attrs).String())
+	if t.data != nil && t.data.header {
+		b.WriteString("<thead><tr>")
+		for _, column := range columns {
+			b.WriteString(HTMLf("<th%s>%s</th>", widthStyle(column.Width), column.label()).String())
+		}
+		b.WriteString("</tr></thead>")
+	}
+
+	b.WriteString("<tbody>")
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		writeCells(&b, columns, row)
+		b.WriteString("</tr>")
+	}
+	if len(rows) == 0 {
+		b.WriteString(HTMLf("<tr><td class=\"st-table-empty\" colspan=\"%d\">无数据</td></tr>", max(len(columns), 1)).String())
+	}
+	b.WriteString("</tbody></table>")
+	return SafeHTML(b.String())
+}
+
+// writeCells 渲染一行中显示的单元格
+func writeCells(b *strings.Builder, columns []shownColumn, row []interface{}) {
+	for _, column := range columns {
+		value := row[column.index]
+		var class SafeHTML
+		if isNumber(value) {
+			class = " class=\"st-cell-number\""
+		}
+		b.WriteString(HTMLf("<td%s%s>%s</td>", class, widthStyle(column.Width), formatCell(value, column.Format)).String())
+	}
+}
+
+// widthStyle 渲染列宽，0表示自动
+func widthStyle(width int) SafeHTML {
+	if width <= 0 {
+		return ""
+	}
+	return HTMLf(" style=\"width: %dpx; min-width: %dpx\"", width, width)
+}
+
+// formatCell 格式化单元格的值，nil和nil指针显示为空
+func formatCell(value interface{}, format string) string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	value = v.Interface()
+
+	if t, ok := value.(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		if format == "" {
+			format = "2006-01-02 15:04:05"
+		}
+		return t.Format(format)
+	}
+	if format != "" {
+		return fmt.Sprintf(format, value)
+	}
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return fmt.Sprint(value)
+}
+
+// isNumber 判断值是否为数值，数值列右对齐
+func isNumber(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
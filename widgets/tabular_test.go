@@ -0,0 +1,206 @@
+package widgets
+
+import (
+	"errors"
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	rowPattern  = regexp.MustCompile(`<tr[^>]*>(.*?)</tr>`)
+	cellPattern = regexp.MustCompile(`<t[dh][^>]*>(.*?)</t[dh]>`)
+)
+
+// tableCells 把渲染的表格解析为每行的单元格文本，第一行为表头（如果有）
+func tableCells(output string) [][]string {
+	var rows [][]string
+	for _, row := range rowPattern.FindAllStringSubmatch(output, -1) {
+		cells := make([]string, 0)
+		for _, cell := range cellPattern.FindAllStringSubmatch(row[1], -1) {
+			cells = append(cells, html.UnescapeString(cell[1]))
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func TestTableStructSlices(t *testing.T) {
+	type product struct {
+		Name    string    `st:"label=名称,width=120"`
+		Price   float64   `st:"label=价格,format=%.2f"`
+		Added   time.Time `st:"label=日期,format=2006-01-02"`
+		Note    string    `st:"hidden"`
+		Skip    string    `st:"-"`
+		private string
+		Stock   *int
+	}
+	stock := 3
+	added := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	products := []product{
+		{Name: "苹果", Price: 1.5, Added: added, Note: "n", Skip: "s", private: "p", Stock: &stock},
+		{Name: "梨", Price: 2},
+	}
+	want := [][]string{
+		{"名称", "价格", "日期", "Stock"},
+		{"苹果", "1.50", "2024-03-01", "3"},
+		{"梨", "2.00", "", ""},
+	}
+
+	table := NewTable(products)
+	output := table.Render()
+	if got := tableCells(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("struct slice cells %v, want %v", got, want)
+	}
+	if !strings.Contains(output, `<th style="width: 120px; min-width: 120px">名称</th>`) {
+		t.Errorf("width from the tag not applied: %s", output)
+	}
+
+	// 指针元素与值元素显示相同，nil元素被跳过，记录保留原始指针
+	first, second := products[0], products[1]
+	pointers := []*product{&first, nil, &second}
+	if got := tableCells(NewTable(pointers).Render()); !reflect.DeepEqual(got, want) {
+		t.Errorf("pointer elements cells %v, want %v", got, want)
+	}
+	data, err := readTableData(pointers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.records) != 2 || data.record(0) != &first || data.record(1) != &second {
+		t.Errorf("records %v do not keep the original pointers", data.records)
+	}
+	if got := tableCells(NewTable(&products).Render()); !reflect.DeepEqual(got, want) {
+		t.Errorf("pointer to slice cells %v, want %v", got, want)
+	}
+
+	// SetColumns替换标签中的设置，指定的列排在前面，可以显示隐藏的列
+	table.SetColumns(
+		TableColumn{Name: "Note", Label: "备注"},
+		TableColumn{Name: "Price", Label: "单价", Format: "%.1f"},
+		TableColumn{Name: "Stock", Hidden: true},
+		TableColumn{Name: "Missing"},
+	)
+	if got := tableCells(table.Render()); !reflect.DeepEqual(got, [][]string{
+		{"备注", "单价", "名称", "日期"},
+		{"n", "1.5", "苹果", "2024-03-01"},
+		{"", "2.0", "梨", ""},
+	}) {
+		t.Errorf("configured columns %v", got)
+	}
+
+	// 无效的列宽显示错误
+	type invalid struct {
+		Name string `st:"width=wide"`
+	}
+	if output := NewTable([]invalid{{"a"}}).Render(); !strings.Contains(output, "st-table-error") || !strings.Contains(output, "invalid width") {
+		t.Errorf("invalid width tag: %s", output)
+	}
+}
+
+func TestTableMapSlices(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"name": "张三", "age": 30},
+		nil,
+		{"name": "李四", "city": "北京"},
+	}
+	want := [][]string{
+		{"age", "city", "name"},
+		{"30", "", "张三"},
+		{"", "北京", "李四"},
+	}
+	if got := tableCells(NewTable(rows).Render()); !reflect.DeepEqual(got, want) {
+		t.Errorf("map slice cells %v, want %v", got, want)
+	}
+
+	// map指针和命名的字符串键类型
+	type key string
+	first := map[key]int{"b": 2, "a": 1}
+	pointers := []*map[key]int{&first, nil}
+	if got := tableCells(NewTable(pointers).Render()); !reflect.DeepEqual(got, [][]string{{"a", "b"}, {"1", "2"}}) {
+		t.Errorf("map pointer cells %v", got)
+	}
+
+	// 单个map显示为按键排序的键值两列，没有表头
+	if got := tableCells(NewTable(map[int]string{2: "二", 1: "一"}).Render()); !reflect.DeepEqual(got, [][]string{{"1", "一"}, {"2", "二"}}) {
+		t.Errorf("single map cells %v", got)
+	}
+
+	// 键不是字符串的map切片不受支持
+	if output := NewTable([]map[int]string{{1: "a"}}).Render(); !strings.Contains(output, "st-table-error") {
+		t.Errorf("map slice with int keys: %s", output)
+	}
+}
+
+func TestTableNilValues(t *testing.T) {
+	empty := [][]string{{"无数据"}}
+	var nilRows *Rows
+	var nilSlice []struct{ Name string }
+	var nilPointer *[]string
+	for name, data := range map[string]interface{}{
+		"nil":          nil,
+		"nil rows":     nilRows,
+		"nil slice":    nilSlice,
+		"nil pointer":  nilPointer,
+		"empty grid":   [][]string{},
+		"nil map rows": []map[string]string{nil},
+	} {
+		output := NewTable(data).Render()
+		if strings.Contains(output, "st-table-error") {
+			t.Errorf("%s: error %s", name, output)
+			continue
+		}
+		got := tableCells(output)
+		if len(got) == 0 || !reflect.DeepEqual(got[len(got)-1], empty[0]) {
+			t.Errorf("%s: cells %v, want the empty hint", name, got)
+		}
+	}
+
+	// nil指针、nil接口和零时间显示为空，较短的行和nil行补齐
+	var missing *float64
+	var iface interface{}
+	rows := Rows{
+		Headers: []string{"a", "b"},
+		Data:    [][]interface{}{{missing, iface, time.Time{}}, {"x"}, nil},
+	}
+	if got := tableCells(NewTable(rows).Render()); !reflect.DeepEqual(got, [][]string{
+		{"a", "b", "2"},
+		{"", "", ""},
+		{"x", "", ""},
+		{"", "", ""},
+	}) {
+		t.Errorf("nil cells %v", got)
+	}
+
+	// 行类型不是切片时显示错误
+	if output := NewTable(Rows{Headers: []string{"a"}, Data: []interface{}{1}}).Render(); !strings.Contains(output, "st-table-error") {
+		t.Errorf("scalar row: %s", output)
+	}
+}
+
+func TestTableHostileCells(t *testing.T) {
+	type record struct {
+		Name  string
+		Value interface{}
+	}
+	for _, value := range hostileValues {
+		pointer := value
+		inputs := []interface{}{
+			[]record{{Name: value, Value: &pointer}, {Name: "b", Value: hostileStringer{}}},
+			[]*record{{Name: value, Value: errors.New(value)}},
+			[]map[string]interface{}{{"Name": value, value: &pointer}},
+			Rows{Headers: []string{"Name"}, Data: [][]interface{}{{value, hostileStringer{}}}},
+		}
+		for _, data := range inputs {
+			table := NewTable(data)
+			table.SetColumns(TableColumn{Name: "Name", Format: "%s!"})
+			output := table.Render()
+			assertEscaped(t, output, value)
+			if cells := tableCells(output); len(cells) < 2 || cells[1][0] != value+"!" {
+				t.Errorf("%T: cells %v, want the formatted raw value", data, cells)
+			}
+		}
+	}
+}
@@ -1,10 +1,5 @@
 package widgets
 
-import (
-	"fmt"
-	"html"
-)
-
 // TitleWidget 标题组件
 type TitleWidget struct {
 	*BaseWidget
@@ -31,11 +26,18 @@ func (w *TitleWidget) SetText(text string) {
 
 // Render 渲染标题组件为HTML
 func (w *TitleWidget) Render() string {
-	anchorAttr := ""
+	var anchorAttr SafeHTML
 	if w.anchor != "" {
-		anchorAttr = fmt.Sprintf(" id=\"%s\"", html.EscapeString(w.anchor))
+		anchorAttr = HTMLf(" id=\"%s\"", w.anchor)
 	}
-	return fmt.Sprintf("<h1 class=\"st-title\" data-widget-id=\"%s\"%s>%s</h1>", w.GetID(), anchorAttr, html.EscapeString(w.text))
+	return HTMLf("<h1 class=\"st-title\" data-widget-id=\"%s\"%s>%s</h1>", w.GetID(), anchorAttr, w.text).String()
+}
+
+// Clone 复制标题组件
+func (w *TitleWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // HeaderWidget 二级标题组件
@@ -63,7 +65,14 @@ func (w *HeaderWidget) Render() string {
 	if w.divider {
 		dividerClass = " st-header-with-divider"
 	}
-	return fmt.Sprintf("<h2 class=\"st-header%s\" data-widget-id=\"%s\">%s</h2>", dividerClass, w.GetID(), html.EscapeString(w.text))
+	return HTMLf("<h2 class=\"st-header%s\" data-widget-id=\"%s\">%s</h2>", dividerClass, w.GetID(), w.text).String()
+}
+
+// Clone 复制二级标题组件
+func (w *HeaderWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // SubheaderWidget 三级标题组件
@@ -82,7 +91,14 @@ func NewSubheader(text string) *SubheaderWidget {
 
 // Render 渲染三级标题组件为HTML
 func (w *SubheaderWidget) Render() string {
-	return fmt.Sprintf("<h3 class=\"st-subheader\" data-widget-id=\"%s\">%s</h3>", w.GetID(), html.EscapeString(w.text))
+	return HTMLf("<h3 class=\"st-subheader\" data-widget-id=\"%s\">%s</h3>", w.GetID(), w.text).String()
+}
+
+// Clone 复制三级标题组件
+func (w *SubheaderWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // TextWidget 文本组件
@@ -101,7 +117,14 @@ func NewText(text string) *TextWidget {
 
 // Render 渲染文本组件为HTML
 func (w *TextWidget) Render() string {
-	return fmt.Sprintf("<div class=\"st-text\" data-widget-id=\"%s\">%s</div>", w.GetID(), html.EscapeString(w.text))
+	return HTMLf("<div class=\"st-text\" data-widget-id=\"%s\">%s</div>", w.GetID(), w.text).String()
+}
+
+// Clone 复制文本组件
+func (w *TextWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
 
 // SetText 设置文本内容
@@ -115,7 +138,7 @@ type WriteWidget struct {
 	data interface{}
 }
 
-// NewWrite 创建新的通用数据展示组件
+// NewWrite 创建新的通用数据展示组件，数据按%v格式化后转义显示，SafeHTML原样显示
 func NewWrite(data interface{}) *WriteWidget {
 	return &WriteWidget{
 		BaseWidget: NewBaseWidget("write"),
@@ -130,5 +153,12 @@ func (w *WriteWidget) SetData(data interface{}) {
 
 // Render 渲染通用数据展示组件为HTML
 func (w *WriteWidget) Render() string {
-	return fmt.Sprintf("<div class=\"st-write\" data-widget-id=\"%s\">%v</div>", w.GetID(), w.data)
+	return HTMLf("<div class=\"st-write\" data-widget-id=\"%s\">%v</div>", w.GetID(), w.data).String()
+}
+
+// Clone 复制通用数据展示组件
+func (w *WriteWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	return &c
 }
@@ -0,0 +1,159 @@
+package widgets
+
+// IParent 容器组件接口，用于遍历组件树
+type IParent interface {
+	Children() []Widget
+}
+
+// IMutableParent 可修改子组件的容器接口
+type IMutableParent interface {
+	IParent
+
+	// RemoveChild 移除直接子组件，找到时返回true
+	RemoveChild(componentID string) bool
+
+	// ReplaceChild 替换直接子组件，找到时返回true
+	ReplaceChild(componentID string, widget Widget) bool
+}
+
+// Walk 深度优先遍历组件树，parent为nil表示顶层组件，fn返回false时停止遍历
+func Walk(roots []Widget, fn func(widget Widget, parent Widget) bool) {
+	walk(roots, nil, fn)
+}
+
+// walk 遍历一层组件，返回false表示遍历已停止
+func walk(widgets []Widget, parent Widget, fn func(widget Widget, parent Widget) bool) bool {
+	for _, widget := range widgets {
+		if !fn(widget, parent) {
+			return false
+		}
+		if p, ok := widget.(IParent); ok {
+			if !walk(p.Children(), widget, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FindWidget 在组件树中查找指定ID的组件，不存在时返回nil
+func FindWidget(roots []Widget, componentID string) Widget {
+	var found Widget
+	Walk(roots, func(widget Widget, parent Widget) bool {
+		if widget.GetID() == componentID {
+			found = widget
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// FindRoot 查找包含指定ID组件的顶层组件，不存在时返回nil
+func FindRoot(roots []Widget, componentID string) Widget {
+	for _, root := range roots {
+		if FindWidget([]Widget{root}, componentID) != nil {
+			return root
+		}
+	}
+	return nil
+}
+
+// Contains 检查组件树中是否包含指定的组件实例
+func Contains(roots []Widget, target Widget) bool {
+	found := false
+	Walk(roots, func(widget Widget, parent Widget) bool {
+		if widget == target {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// RemoveWidget 从组件树中移除指定ID的组件，返回新的顶层组件列表和是否找到
+func RemoveWidget(roots []Widget, componentID string) ([]Widget, bool) {
+	for i, widget := range roots {
+		if widget.GetID() == componentID {
+			return append(roots[:i], roots[i+1:]...), true
+		}
+	}
+
+	parent := NewIndex(roots).Parent(componentID)
+	if p, ok := parent.(IMutableParent); ok {
+		return roots, p.RemoveChild(componentID)
+	}
+	return roots, false
+}
+
+// ReplaceWidget 替换组件树中指定ID的组件，找到时返回true
+func ReplaceWidget(roots []Widget, componentID string, replacement Widget) bool {
+	for i, widget := range roots {
+		if widget.GetID() == componentID {
+			roots[i] = replacement
+			return true
+		}
+	}
+
+	parent := NewIndex(roots).Parent(componentID)
+	if p, ok := parent.(IMutableParent); ok {
+		return p.ReplaceChild(componentID, replacement)
+	}
+	return false
+}
+
+// indexEntry 索引项
+type indexEntry struct {
+	widget Widget
+	parent Widget
+}
+
+// Index 组件ID索引，用于在任意深度按ID查找组件及其父组件
+//
+// 索引是构建时组件树的快照，组件树变化后需要重新构建。
+type Index map[string]indexEntry
+
+// NewIndex 为组件树构建ID索引，ID重复时保留先遍历到的组件
+func NewIndex(roots []Widget) Index {
+	index := make(Index)
+	Walk(roots, func(widget Widget, parent Widget) bool {
+		if _, exists := index[widget.GetID()]; !exists {
+			index[widget.GetID()] = indexEntry{widget: widget, parent: parent}
+		}
+		return true
+	})
+	return index
+}
+
+// Lookup 按ID查找组件
+func (idx Index) Lookup(componentID string) (Widget, bool) {
+	entry, exists := idx[componentID]
+	return entry.widget, exists
+}
+
+// Parent 获取组件的父组件，顶层组件或不存在时返回nil
+func (idx Index) Parent(componentID string) Widget {
+	return idx[componentID].parent
+}
+
+// removeChild 从子组件列表中移除指定ID的组件
+func removeChild(children []Widget, componentID string) ([]Widget, bool) {
+	for i, child := range children {
+		if child.GetID() == componentID {
+			return append(children[:i], children[i+1:]...), true
+		}
+	}
+	return children, false
+}
+
+// replaceChild 替换子组件列表中指定ID的组件
+func replaceChild(children []Widget, componentID string, widget Widget) bool {
+	for i, child := range children {
+		if child.GetID() == componentID {
+			children[i] = widget
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package widgets
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxFileSize 文件上传组件默认的单个文件大小上限（字节）
+const DefaultMaxFileSize int64 = 32 << 20
+
+// defaultMaxFiles 允许多选时默认的一次上传的最大文件数
+const defaultMaxFiles = 10
+
+// UploadedFile 上传的文件
+//
+// 文件内容保存在会话的临时目录中，通过ReadAt随机读取或通过Reader顺序读取，可以并发读取。
+// 会话过期、被删除，或同一组件再次上传文件后，文件被删除，之后读取会返回错误。
+type UploadedFile struct {
+	Name        string // 客户端提供的文件名，不含路径
+	ContentType string // 客户端声明的MIME类型
+	Size        int64  // 文件大小（字节）
+	data        io.ReaderAt
+}
+
+// NewUploadedFile 创建上传文件，由服务的上传端点在保存文件后调用
+func NewUploadedFile(name, contentType string, size int64, data io.ReaderAt) *UploadedFile {
+	return &UploadedFile{
+		Name:        filepath.Base(strings.ReplaceAll(name, "\\", "/")),
+		ContentType: contentType,
+		Size:        size,
+		data:        data,
+	}
+}
+
+// ReadAt 从文件的指定偏移读取内容，实现io.ReaderAt
+func (f *UploadedFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.data.ReadAt(p, off)
+}
+
+// Reader 返回从文件开头读取的独立读取器
+func (f *UploadedFile) Reader() *io.SectionReader {
+	return io.NewSectionReader(f.data, 0, f.Size)
+}
+
+// UploadLimits 文件上传限制
+type UploadLimits struct {
+	MaxFileSize int64    // 单个文件的最大字节数
+	MaxFiles    int      // 一次上传的最大文件数
+	Extensions  []string // 允许的扩展名（小写，以"."开头），为空表示不限制
+}
+
+// CheckName 检查文件扩展名是否被允许
+func (l UploadLimits) CheckName(name string) error {
+	if len(l.Extensions) == 0 {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, allowed := range l.Extensions {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的文件类型: %s（允许 %s）", name, strings.Join(l.Extensions, ", "))
+}
+
+// CheckSize 检查文件大小是否超过限制
+func (l UploadLimits) CheckSize(name string, size int64) error {
+	if size > l.MaxFileSize {
+		return fmt.Errorf("文件过大: %s（不能超过 %s）", name, formatFileSize(l.MaxFileSize))
+	}
+	return nil
+}
+
+// CheckCount 检查文件数量是否超过限制
+func (l UploadLimits) CheckCount(count int) error {
+	if count > l.MaxFiles {
+		return fmt.Errorf("一次最多上传 %d 个文件", l.MaxFiles)
+	}
+	return nil
+}
+
+// CheckFiles 检查已接收的文件是否符合限制
+func (l UploadLimits) CheckFiles(files []*UploadedFile) error {
+	if err := l.CheckCount(len(files)); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := l.CheckName(file.Name); err != nil {
+			return err
+		}
+		if err := l.CheckSize(file.Name, file.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IFileUploader 文件上传组件接口
+//
+// 文件内容不经过事件协议，由服务的上传端点按UploadLimits接收并保存到会话的临时目录。
+// 接收成功时调用BindFiles后以upload事件触发组件回调；超出限制时调用RejectFiles，不触发回调。
+type IFileUploader interface {
+	UploadLimits() UploadLimits
+	BindFiles(files []*UploadedFile) error
+	RejectFiles(err error)
+}
+
+// FileUploaderWidget 文件上传组件
+type FileUploaderWidget struct {
+	*BaseWidget
+	label  string
+	limits UploadLimits
+	files  []*UploadedFile
+	err    string // 最近一次上传的错误
+}
+
+// NewFileUploader 创建新的文件上传组件，multiple为true时允许一次选择多个文件
+func NewFileUploader(label string, multiple bool) *FileUploaderWidget {
+	maxFiles := 1
+	if multiple {
+		maxFiles = defaultMaxFiles
+	}
+	return &FileUploaderWidget{
+		BaseWidget: NewBaseWidget("file_uploader"),
+		label:      label,
+		limits: UploadLimits{
+			MaxFileSize: DefaultMaxFileSize,
+			MaxFiles:    maxFiles,
+		},
+	}
+}
+
+// SetExtensions 设置允许的扩展名，例如 SetExtensions(".csv", "tsv")，不设置时不限制类型
+//
+// 之前上传的文件中扩展名不再被允许的不再返回，下同。
+func (w *FileUploaderWidget) SetExtensions(extensions ...string) {
+	w.limits.Extensions = make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		w.limits.Extensions = append(w.limits.Extensions, ext)
+	}
+	w.dropInvalidFiles()
+}
+
+// SetMaxFileSize 设置单个文件的最大字节数
+func (w *FileUploaderWidget) SetMaxFileSize(size int64) {
+	if size > 0 {
+		w.limits.MaxFileSize = size
+	}
+	w.dropInvalidFiles()
+}
+
+// SetMaxFiles 设置一次上传的最大文件数，大于1时允许多选
+func (w *FileUploaderWidget) SetMaxFiles(count int) {
+	if count > 0 {
+		w.limits.MaxFiles = count
+	}
+	w.dropInvalidFiles()
+}
+
+// dropInvalidFiles 去掉已上传文件中不符合当前限制的文件，数量超过上限时只保留前面的文件
+func (w *FileUploaderWidget) dropInvalidFiles() {
+	var files []*UploadedFile
+	for _, file := range w.files {
+		if len(files) < w.limits.MaxFiles && w.limits.CheckName(file.Name) == nil && w.limits.CheckSize(file.Name, file.Size) == nil {
+			files = append(files, file)
+		}
+	}
+	w.files = files
+}
+
+// SetMultiple 设置是否允许一次选择多个文件，已经允许多选时保持当前的文件数上限
+func (w *FileUploaderWidget) SetMultiple(multiple bool) {
+	switch {
+	case !multiple:
+		w.SetMaxFiles(1)
+	case w.limits.MaxFiles == 1:
+		w.SetMaxFiles(defaultMaxFiles)
+	}
+}
+
+// UploadLimits 获取上传限制
+func (w *FileUploaderWidget) UploadLimits() UploadLimits {
+	return w.limits
+}
+
+// BindFiles 保存上传的文件，替换之前上传的文件
+func (w *FileUploaderWidget) BindFiles(files []*UploadedFile) error {
+	w.files = files
+	w.err = ""
+	return nil
+}
+
+// RejectFiles 记录上传失败的原因，之前上传的文件保持不变
+func (w *FileUploaderWidget) RejectFiles(err error) {
+	w.err = err.Error()
+}
+
+// Render 渲染文件上传组件为HTML
+func (w *FileUploaderWidget) Render() string {
+	id := w.GetID()
+
+	var attrs SafeHTML
+	if w.limits.MaxFiles > 1 {
+		attrs += " multiple"
+	}
+	if len(w.limits.Extensions) > 0 {
+		attrs += HTMLf(" accept=\"%s\"", strings.Join(w.limits.Extensions, ","))
+	}
+
+	var filesHTML SafeHTML
+	for _, file := range w.files {
+		filesHTML += HTMLf("<li><span class=\"st-file-name\">%s</span><span class=\"st-file-size\">%s</span></li>",
+			file.Name, formatFileSize(file.Size))
+	}
+	if filesHTML != "" {
+		filesHTML = "<ul class=\"st-file-list\">" + filesHTML + "</ul>"
+	}
+
+	var errorHTML SafeHTML
+	if w.err != "" {
+		errorHTML = HTMLf("<div class=\"st-input-error\">%s</div>", w.err)
+	}
+
+	return HTMLf("<div class=\"st-file-uploader-container\" data-widget-id=\"%s\"><label>%s</label><input type=\"file\" class=\"st-file-uploader\" data-widget-id=\"%s\" data-event-type=\"upload\" data-max-files=\"%d\" data-max-size=\"%d\"%s><div class=\"st-file-hint\">单个文件不超过 %s</div>%s%s</div>",
+		id, w.label, id, w.limits.MaxFiles, w.limits.MaxFileSize, attrs, formatFileSize(w.limits.MaxFileSize), filesHTML, errorHTML).String()
+}
+
+// Clone 复制文件上传组件
+func (w *FileUploaderWidget) Clone() Widget {
+	c := *w
+	c.BaseWidget = w.BaseWidget.CloneBase()
+	c.limits.Extensions = append([]string(nil), w.limits.Extensions...)
+	return &c
+}
+
+// GetFiles 获取最近一次上传的文件，没有上传时返回nil
+func (w *FileUploaderWidget) GetFiles() []*UploadedFile {
+	return w.files
+}
+
+// GetFile 获取最近一次上传的第一个文件
+func (w *FileUploaderWidget) GetFile() (*UploadedFile, bool) {
+	if len(w.files) == 0 {
+		return nil, false
+	}
+	return w.files[0], true
+}
+
+// GetError 获取最近一次上传的错误，没有错误时返回空字符串
+func (w *FileUploaderWidget) GetError() string {
+	return w.err
+}
+
+// formatFileSize 将字节数格式化为易读的大小
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}